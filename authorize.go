@@ -0,0 +1,137 @@
+package tokay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type (
+	// PolicyEnforcer decides whether the principal (the authenticated user name) is allowed
+	// to access the resource protected by the policy (see Authorize).
+	PolicyEnforcer interface {
+		Enforce(c *Context, principal, policy string) (bool, error)
+	}
+
+	// RBAC is a simple role-based PolicyEnforcer: policies are permission names granted to roles,
+	// roles are assigned to principals. Permission "*" grants all policies.
+	RBAC struct {
+		sync.RWMutex
+		permissions map[string]map[string]bool // permissions by roles
+		roles       map[string][]string        // roles by principals
+	}
+
+	// CasbinEnforcer is the interface of the casbin.Enforcer used by the casbin adapter.
+	CasbinEnforcer interface {
+		Enforce(rvals ...interface{}) (bool, error)
+	}
+
+	casbinAdapter struct {
+		enforcer CasbinEnforcer
+	}
+)
+
+// SetPolicyEnforcer registers the policy enforcer used by the Authorize middlewares.
+func (engine *Engine) SetPolicyEnforcer(enforcer PolicyEnforcer) {
+	engine.enforcer = enforcer
+}
+
+// Authorize returns a route middleware which allows the request only if the engine policy
+// enforcer (see SetPolicyEnforcer) allows the policy for the principal. The principal is
+// the user name stored with AuthUserKey by the authentication middlewares (BasicAuth,
+// RequireClientCert etc). Requests without the principal are rejected with 401 Unauthorized,
+// denied requests are rejected with 403 Forbidden.
+//
+//	rbac := tokay.NewRBAC()
+//	rbac.Grant("admin", "*")
+//	rbac.Grant("editor", "posts:write")
+//	rbac.Assign("john", "editor")
+//	engine.SetPolicyEnforcer(rbac)
+//	engine.POST("/posts", tokay.BasicAuth("john", "secret"), tokay.Authorize("posts:write"), createPost)
+func Authorize(policy string) Handler {
+	return func(c *Context) {
+		principal, _ := c.Get(AuthUserKey).(string)
+		if principal == "" {
+			c.AbortWithError(http.StatusUnauthorized, nil)
+			return
+		}
+		if c.engine.enforcer == nil {
+			c.AbortWithError(http.StatusInternalServerError, errors.New("policy enforcer is not registered"))
+			return
+		}
+		allowed, err := c.engine.enforcer.Enforce(c, principal, policy)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if !allowed {
+			c.AbortWithError(http.StatusForbidden, nil)
+		}
+	}
+}
+
+// NewRBAC creates an empty RBAC policy enforcer.
+func NewRBAC() *RBAC {
+	return &RBAC{
+		permissions: make(map[string]map[string]bool),
+		roles:       make(map[string][]string),
+	}
+}
+
+// Grant grants the permissions (policy names) to the role.
+func (r *RBAC) Grant(role string, permissions ...string) *RBAC {
+	r.Lock()
+	if r.permissions[role] == nil {
+		r.permissions[role] = make(map[string]bool)
+	}
+	for _, permission := range permissions {
+		r.permissions[role][permission] = true
+	}
+	r.Unlock()
+	return r
+}
+
+// Assign assigns the roles to the principal.
+func (r *RBAC) Assign(principal string, roles ...string) *RBAC {
+	r.Lock()
+	r.roles[principal] = append(r.roles[principal], roles...)
+	r.Unlock()
+	return r
+}
+
+// Enforce implements PolicyEnforcer.
+func (r *RBAC) Enforce(c *Context, principal, policy string) (bool, error) {
+	r.RLock()
+	defer r.RUnlock()
+	for _, role := range r.roles[principal] {
+		if permissions := r.permissions[role]; permissions[policy] || permissions["*"] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NewCasbinEnforcer adapts the casbin enforcer (github.com/casbin/casbin) to PolicyEnforcer.
+// The policy "object:action" is enforced as (principal, object, action); the policy without
+// the colon is enforced as (principal, policy, request method).
+//
+//	e, _ := casbin.NewEnforcer("model.conf", "policy.csv")
+//	engine.SetPolicyEnforcer(tokay.NewCasbinEnforcer(e))
+func NewCasbinEnforcer(enforcer CasbinEnforcer) PolicyEnforcer {
+	return &casbinAdapter{enforcer: enforcer}
+}
+
+// Enforce implements PolicyEnforcer.
+func (a *casbinAdapter) Enforce(c *Context, principal, policy string) (bool, error) {
+	object, action := policy, c.Method()
+	if i := strings.LastIndexByte(policy, ':'); i >= 0 {
+		object, action = policy[:i], policy[i+1:]
+	}
+	allowed, err := a.enforcer.Enforce(principal, object, action)
+	if err != nil {
+		return false, fmt.Errorf("casbin: %v", err)
+	}
+	return allowed, nil
+}
@@ -0,0 +1,54 @@
+package tokay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testCasbin struct {
+	args []interface{}
+}
+
+func (e *testCasbin) Enforce(rvals ...interface{}) (bool, error) {
+	e.args = rvals
+	if rvals[0] == "error" {
+		return false, errors.New("failed")
+	}
+	return rvals[0] == "admin", nil
+}
+
+func TestAuthorize(t *testing.T) {
+	engine := New()
+	rbac := NewRBAC().Grant("admin", "*").Grant("editor", "posts:write", "posts:read").Assign("john", "editor").Assign("root", "admin")
+	engine.SetPolicyEnforcer(rbac)
+	login := func(c *Context) {
+		if user := c.Query("user"); user != "" {
+			c.Set(AuthUserKey, user)
+		}
+	}
+	engine.GET("/posts", login, Authorize("posts:write"), func(c *Context) { c.String(200, "OK") })
+	engine.GET("/users", login, Authorize("users:write"), func(c *Context) { c.String(200, "OK") })
+
+	tests := map[string]int{
+		"/posts":           401,
+		"/posts?user=john": 200,
+		"/posts?user=bob":  403,
+		"/users?user=john": 403,
+		"/users?user=root": 200,
+	}
+	for uri, status := range tests {
+		assert.Equal(t, status, serveTestRequest(engine, uri).Response.StatusCode(), uri)
+	}
+
+	casbin := &testCasbin{}
+	engine.SetPolicyEnforcer(NewCasbinEnforcer(casbin))
+	assert.Equal(t, 403, serveTestRequest(engine, "/posts?user=john").Response.StatusCode())
+	assert.Equal(t, []interface{}{"john", "posts", "write"}, casbin.args)
+	assert.Equal(t, 200, serveTestRequest(engine, "/posts?user=admin").Response.StatusCode())
+	assert.Equal(t, 500, serveTestRequest(engine, "/posts?user=error").Response.StatusCode())
+
+	engine.SetPolicyEnforcer(nil)
+	assert.Equal(t, 500, serveTestRequest(engine, "/posts?user=john").Response.StatusCode())
+}
@@ -0,0 +1,55 @@
+package tokay
+
+import "strings"
+
+// BasePath mounts the engine at the path prefix, like "/app" when a reverse proxy forwards the
+// requests of https://example.com/app/... without stripping the prefix. The routes are declared
+// without the prefix, the handlers see the paths without it (see Context.OriginalPath) and the URLs
+// generated by Route.URL and Context.URL include it. The requests outside the prefix get 404.
+// The empty prefix unmounts the engine.
+//
+//	engine.BasePath("/app")
+//	engine.GET("/users", listUsers) // serves GET /app/users
+func (engine *Engine) BasePath(prefix string) {
+	prefix = strings.TrimRight(prefix, "/")
+	if prefix != "" && prefix[0] != '/' {
+		prefix = "/" + prefix
+	}
+	engine.basePath = prefix
+}
+
+// StripPrefix sets whether the handlers of the group routes see the request paths without the group
+// path (for example to mount a file server or a sub-application), the routes still match the full paths.
+// The full path is available with Context.OriginalPath. Routes of the subgroups are not affected.
+//
+//	docs := engine.Group("/docs").StripPrefix(true)
+//	docs.GET("/*", serveDocs) // c.Path() is "/intro" for GET /docs/intro
+func (r *RouterGroup) StripPrefix(strip bool) *RouterGroup {
+	r.stripPrefix = strip
+	return r
+}
+
+// OriginalPath returns the request path before the prefixes were stripped (see Engine.BasePath
+// and RouterGroup.StripPrefix).
+func (c *Context) OriginalPath() string {
+	if c.originalPath != "" {
+		return c.originalPath
+	}
+	return c.Path()
+}
+
+// stripPath removes the prefix from the request path, false is returned if the path is outside the prefix.
+func (c *Context) stripPath(prefix string) bool {
+	path := c.Path()
+	if !strings.HasPrefix(path, prefix) || len(path) > len(prefix) && path[len(prefix)] != '/' {
+		return false
+	}
+	if c.originalPath == "" {
+		c.originalPath = path
+	}
+	if path = path[len(prefix):]; path == "" {
+		path = "/"
+	}
+	c.Request.URI().SetPath(path)
+	return true
+}
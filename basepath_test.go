@@ -0,0 +1,56 @@
+package tokay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineBasePath(t *testing.T) {
+	engine := New()
+	engine.BasePath("app/")
+	engine.GET("/users/<id>", func(c *Context) {
+		c.String(200, c.Path()+" "+c.OriginalPath()+" "+c.URL("user", "id", 2))
+	}).Name("user")
+	engine.GET("/list/", func(c *Context) {})
+
+	ctx := serveTestRequest(engine, "/app/users/1")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "/users/1 /app/users/1 /app/users/2", string(ctx.Response.Body()))
+
+	ctx = serveTestRequest(engine, "/users/1")
+	assert.Equal(t, 404, ctx.Response.StatusCode())
+	ctx = serveTestRequest(engine, "/application/users/1")
+	assert.Equal(t, 404, ctx.Response.StatusCode())
+
+	ctx = serveTestRequest(engine, "/app/list")
+	assert.Equal(t, 301, ctx.Response.StatusCode())
+	assert.True(t, strings.HasSuffix(string(ctx.Response.Header.Peek("Location")), "/app/list/"))
+
+	engine.BasePath("")
+	ctx = serveTestRequest(engine, "/users/1")
+	assert.Equal(t, "/users/1 /users/1 /users/2", string(ctx.Response.Body()))
+}
+
+func TestRouterGroupStripPrefix(t *testing.T) {
+	engine := New()
+	handler := func(c *Context) {
+		c.String(200, c.Path()+" "+c.OriginalPath())
+	}
+	docs := engine.Group("/docs").StripPrefix(true)
+	docs.Group("/v2").GET("/intro", handler)
+	docs.GET("/*", handler)
+	engine.Group("/files").GET("/*", handler)
+
+	ctx := serveTestRequest(engine, "/docs/intro")
+	assert.Equal(t, "/intro /docs/intro", string(ctx.Response.Body()))
+	ctx = serveTestRequest(engine, "/docs/v2/intro")
+	assert.Equal(t, "/docs/v2/intro /docs/v2/intro", string(ctx.Response.Body()))
+	ctx = serveTestRequest(engine, "/files/a.txt")
+	assert.Equal(t, "/files/a.txt /files/a.txt", string(ctx.Response.Body()))
+
+	engine.BasePath("/app")
+	ctx = serveTestRequest(engine, "/app/docs/intro")
+	assert.Equal(t, "/intro /app/docs/intro", string(ctx.Response.Body()))
+}
@@ -0,0 +1,144 @@
+package tokay
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/night-codes/go-json"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// BatchConfig is a struct for specifying Batch handler options.
+	BatchConfig struct {
+		// MaxRequests is the maximum number of sub-requests in a batch. Default is 20.
+		MaxRequests int
+	}
+
+	// BatchRequest is a sub-request of the batch. JSON string body is sent as is, other JSON
+	// values are sent as the JSON body with the "application/json" Content-Type unless
+	// the Headers set another one.
+	BatchRequest struct {
+		Method  string            `json:"method"`
+		Path    string            `json:"path"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    json.RawMessage   `json:"body,omitempty"`
+	}
+
+	// BatchResponse is a response to the sub-request. JSON response body is embedded as is,
+	// other bodies are embedded as JSON strings.
+	BatchResponse struct {
+		Status  int               `json:"status"`
+		Headers map[string]string `json:"headers,omitempty"`
+		Body    json.RawMessage   `json:"body,omitempty"`
+	}
+)
+
+// Batch returns a handler which accepts a JSON array of sub-requests (see BatchRequest),
+// dispatches them one by one through the engine routing in-process and responds with
+// the JSON array of responses (see BatchResponse). Sub-requests inherit the batch request
+// headers (authorization, cookies etc) unless they are overridden, except the body headers and
+// Accept-Encoding. The batch requests inside a batch get 400 Bad Request.
+//
+//	engine.POST("/batch", tokay.Batch(engine))
+//
+//	POST /batch
+//	[{"method": "GET", "path": "/users/1"}, {"method": "POST", "path": "/posts", "body": {"title": "Hi"}}]
+func Batch(engine *Engine, config ...BatchConfig) Handler {
+	var cfg BatchConfig
+	if len(config) != 0 {
+		cfg = config[0]
+	}
+	if cfg.MaxRequests <= 0 {
+		cfg.MaxRequests = 20
+	}
+
+	return func(c *Context) {
+		if c.RequestCtx.UserValue(batchSubRequestKey) != nil {
+			// the nested batches multiply the sub-requests
+			c.AbortWithError(http.StatusBadRequest, errors.New("batch request inside batch request"))
+			return
+		}
+		var requests []BatchRequest
+		if err := json.Unmarshal(c.Body(), &requests); err != nil {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		if len(requests) > cfg.MaxRequests {
+			c.AbortWithError(http.StatusRequestEntityTooLarge, fmt.Errorf("too many requests in batch, maximum is %d", cfg.MaxRequests))
+			return
+		}
+
+		responses := make([]BatchResponse, len(requests))
+		for i, r := range requests {
+			if r.Method == "" {
+				r.Method = "GET"
+			}
+			if r.Path == "" || r.Path[0] != '/' {
+				responses[i] = BatchResponse{Status: http.StatusBadRequest, Body: batchBody("", []byte("invalid batch request path"))}
+				continue
+			}
+			responses[i] = engine.dispatchBatchRequest(c, r)
+		}
+		c.JSON(http.StatusOK, responses)
+	}
+}
+
+// batchSubRequestKey is the fasthttp user value marking the batch sub-requests.
+const batchSubRequestKey = "tokay.batchSubRequest"
+
+// dispatchBatchRequest handles the sub-request of the batch request c.
+func (engine *Engine) dispatchBatchRequest(c *Context, r BatchRequest) BatchResponse {
+	var req fasthttp.Request
+	c.Request.Header.CopyTo(&req.Header)
+	// the body headers of the batch request don't describe the sub-request body
+	req.Header.Del("Content-Length")
+	req.Header.Del("Content-Type")
+	// the sub-response bodies are embedded into the batch response, they must not be encoded
+	req.Header.Del("Accept-Encoding")
+	req.Header.SetMethod(strings.ToUpper(r.Method))
+	req.SetRequestURI(r.Path)
+	if len(r.Body) != 0 && string(r.Body) != "null" {
+		var s string
+		if json.Unmarshal(r.Body, &s) == nil {
+			req.SetBodyString(s)
+		} else {
+			req.Header.SetContentType("application/json")
+			req.SetBody(r.Body)
+		}
+	} else {
+		req.ResetBody()
+	}
+	req.Header.SetContentLength(len(req.Body()))
+	for key, value := range r.Headers {
+		if !strings.EqualFold(key, "Accept-Encoding") {
+			req.Header.Set(key, value)
+		}
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(&req, c.RemoteAddr(), nil)
+	ctx.SetUserValue(batchSubRequestKey, true)
+	engine.HandleRequest(ctx)
+
+	resp := BatchResponse{Status: ctx.Response.StatusCode(), Headers: make(map[string]string)}
+	ctx.Response.Header.VisitAll(func(key, value []byte) {
+		resp.Headers[string(key)] = string(value)
+	})
+	resp.Body = batchBody(string(ctx.Response.Header.ContentType()), ctx.Response.Body())
+	return resp
+}
+
+// batchBody embeds the response body into the batch response.
+func batchBody(contentType string, body []byte) json.RawMessage {
+	if len(body) == 0 {
+		return nil
+	}
+	if strings.HasPrefix(contentType, "application/json") && json.Valid(body) {
+		return append(json.RawMessage(nil), body...)
+	}
+	data, _ := json.Marshal(string(body))
+	return data
+}
@@ -0,0 +1,66 @@
+package tokay
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/night-codes/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestBatch(t *testing.T) {
+	engine := New()
+	engine.Use(Compress())
+	batches := 0
+	engine.POST("/batch", func(c *Context) {
+		batches++
+	}, Batch(engine, BatchConfig{MaxRequests: 6}))
+	engine.GET("/users/<id>", func(c *Context) {
+		c.JSON(200, map[string]string{"id": c.Param("id"), "auth": c.GetHeader("Authorization")})
+	})
+	large := strings.Repeat("large ", 100)
+	engine.GET("/large", func(c *Context) {
+		c.String(200, large)
+	})
+	engine.POST("/echo", func(c *Context) {
+		c.String(201, fmt.Sprintf("%s|%d|%s", c.ContentType(), c.Request.Header.ContentLength(), c.Body()))
+	})
+
+	batch := func(body string) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, "/batch", withMethod("POST"), withHeader("Authorization", "Bearer abc"),
+			withHeader("Accept-Encoding", "gzip"), withBody("application/json", body))
+	}
+
+	ctx := batch(`[
+		{"method": "GET", "path": "/users/1"},
+		{"method": "post", "path": "/echo", "headers": {"Content-Type": "text/plain", "Accept-Encoding": "br"}, "body": "hello"},
+		{"method": "POST", "path": "/x/../batch", "body": [{"method": "POST", "path": "/./batch", "body": []}]},
+		{"method": "POST", "path": "/echo", "body": {"a": 1}},
+		{"method": "POST", "path": "/echo", "body": "hi"},
+		{"path": "/large", "headers": {"Accept-Encoding": "gzip"}}
+	]`)
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, 2, batches)
+	body, err := ctx.Response.BodyGunzip()
+	assert.Nil(t, err)
+	var responses []BatchResponse
+	assert.Nil(t, json.Unmarshal(body, &responses))
+	if assert.Len(t, responses, 6) {
+		assert.Equal(t, 200, responses[0].Status)
+		assert.JSONEq(t, `{"id":"1","auth":"Bearer abc"}`, string(responses[0].Body))
+		assert.Equal(t, 201, responses[1].Status)
+		assert.Equal(t, `"text/plain|5|hello"`, string(responses[1].Body))
+		assert.Equal(t, 400, responses[2].Status)
+		// the body headers of the batch request are not inherited
+		assert.Equal(t, `"application/json|8|{\"a\": 1}"`, string(responses[3].Body))
+		assert.Equal(t, `"|2|hi"`, string(responses[4].Body))
+		// the sub-responses are not encoded
+		assert.Equal(t, `"`+large+`"`, string(responses[5].Body))
+		assert.Empty(t, responses[5].Headers["Content-Encoding"])
+	}
+
+	assert.Equal(t, 413, batch(`[{},{},{},{},{},{},{}]`).Response.StatusCode())
+	assert.Equal(t, 400, batch(`{}`).Response.StatusCode())
+}
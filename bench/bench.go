@@ -0,0 +1,73 @@
+// Package bench contains the benchmark scenarios of the tokay package.
+// The same routes are served by bench/server for the wrk and bombardier load tests
+// (see bench/run.sh) and used by the Go benchmarks of this package.
+package bench
+
+import (
+	"github.com/night-codes/tokay"
+)
+
+// User is the JSON payload of the binding and rendering scenarios.
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name" valid:"required"`
+	Email string `json:"email" valid:"email"`
+}
+
+// MiddlewareDepth is the number of no-op middlewares of the "/middleware" scenario.
+const MiddlewareDepth = 10
+
+// NewEngine returns the engine with the benchmark scenario routes:
+//
+//	GET  /plaintext             - string response
+//	GET  /json                  - JSON rendering
+//	GET  /users/<id:\d+>/posts/<post> - routing with parameters
+//	GET  /typed/<id:int>/posts/<post> - routing with typed parameters
+//	GET  /params/<a>/<b>/<c>/<d>/<e>/<f> - routing with many parameters
+//	POST /bind                  - JSON body binding with validation
+//	GET  /middleware            - MiddlewareDepth no-op middlewares
+func NewEngine() *tokay.Engine {
+	engine := tokay.New()
+
+	engine.GET("/plaintext", func(c *tokay.Context) {
+		c.String(200, "Hello, World!")
+	})
+	engine.GET("/json", func(c *tokay.Context) {
+		c.JSON(200, User{ID: 1, Name: "John", Email: "john@example.com"})
+	})
+	engine.GET("/users/<id:\\d+>/posts/<post>", func(c *tokay.Context) {
+		c.String(200, c.Param("id")+":"+c.Param("post"))
+	})
+	engine.GET("/typed/<id:int>/posts/<post>", func(c *tokay.Context) {
+		c.String(200, c.Param("id")+":"+c.Param("post"))
+	})
+	engine.GET("/params/<a>/<b>/<c>/<d>/<e>/<f>", func(c *tokay.Context) {
+		c.String(200, c.Param("f"))
+	})
+	engine.POST("/bind", func(c *tokay.Context) {
+		var user User
+		if err := c.BindJSON(&user); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.JSON(200, user)
+	})
+
+	handlers := make([]tokay.Handler, MiddlewareDepth+1)
+	for i := 0; i < MiddlewareDepth; i++ {
+		handlers[i] = func(c *tokay.Context) {}
+	}
+	handlers[MiddlewareDepth] = func(c *tokay.Context) {
+		c.String(200, "OK")
+	}
+	engine.GET("/middleware", handlers...)
+
+	// GitHub-like API routes to make the routing tree realistic
+	api := engine.Group("/repos/<owner>/<repo>")
+	for _, path := range []string{"", "/issues", "/issues/<number>", "/pulls", "/pulls/<number>", "/commits", "/commits/<sha>", "/branches", "/branches/<branch>"} {
+		api.GET(path, func(c *tokay.Context) {
+			c.String(200, "OK")
+		})
+	}
+	return engine
+}
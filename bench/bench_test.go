@@ -0,0 +1,116 @@
+package bench
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/night-codes/tokay"
+
+	"github.com/valyala/fasthttp"
+)
+
+// registrationRoutes is the number of routes of the registration benchmarks.
+const registrationRoutes = 10000
+
+func benchmarkRequest(b *testing.B, method, uri, contentType, body string) {
+	engine := NewEngine()
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	if contentType != "" {
+		ctx.Request.Header.SetContentType(contentType)
+		ctx.Request.SetBodyString(body)
+	}
+	engine.HandleRequest(ctx)
+	if status := ctx.Response.StatusCode(); status != 200 {
+		b.Fatalf("%s %s: status %d: %s", method, uri, status, ctx.Response.Body())
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctx.Response.Reset()
+		engine.HandleRequest(ctx)
+	}
+}
+
+func BenchmarkStatic(b *testing.B) {
+	benchmarkRequest(b, "GET", "/plaintext", "", "")
+}
+
+func BenchmarkParams(b *testing.B) {
+	benchmarkRequest(b, "GET", "/users/12/posts/hello", "", "")
+}
+
+func BenchmarkGroupParams(b *testing.B) {
+	benchmarkRequest(b, "GET", "/repos/night-codes/tokay/pulls/42", "", "")
+}
+
+func BenchmarkTypedParams(b *testing.B) {
+	benchmarkRequest(b, "GET", "/typed/42/posts/hello", "", "")
+}
+
+func BenchmarkManyParams(b *testing.B) {
+	benchmarkRequest(b, "GET", "/params/1/2/3/4/5/6", "", "")
+}
+
+func BenchmarkJSON(b *testing.B) {
+	benchmarkRequest(b, "GET", "/json", "", "")
+}
+
+func BenchmarkBindJSON(b *testing.B) {
+	benchmarkRequest(b, "POST", "/bind", "application/json", `{"id":1,"name":"John","email":"john@example.com"}`)
+}
+
+func BenchmarkMiddleware(b *testing.B) {
+	benchmarkRequest(b, "GET", "/middleware", "", "")
+}
+
+func routeDefs() []tokay.RouteDef {
+	handler := func(c *tokay.Context) {}
+	defs := make([]tokay.RouteDef, registrationRoutes)
+	for i := range defs {
+		defs[i] = tokay.RouteDef{
+			Path:     fmt.Sprintf("/api/v1/resource%d/<id>/items/<item>", i),
+			Name:     fmt.Sprintf("resource%d", i),
+			Handlers: []tokay.Handler{handler},
+		}
+	}
+	return defs
+}
+
+func BenchmarkRegisterRoutes(b *testing.B) {
+	defs := routeDefs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		engine := tokay.New()
+		for _, def := range defs {
+			engine.GET(def.Path, def.Handlers...).Name(def.Name)
+		}
+	}
+}
+
+func BenchmarkAddRoutes(b *testing.B) {
+	defs := routeDefs()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tokay.New().AddRoutes(defs)
+	}
+}
+
+func TestScenarios(t *testing.T) {
+	engine := NewEngine()
+	for _, uri := range []string{"/plaintext", "/json", "/users/1/posts/a", "/middleware", "/repos/a/b/commits/c"} {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(uri)
+		engine.HandleRequest(ctx)
+		if status := ctx.Response.StatusCode(); status != 200 {
+			t.Errorf("GET %s: status %d", uri, status)
+		}
+	}
+	if stats := engine.Stats(); stats.Requests != 5 || stats.ActiveRequests != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
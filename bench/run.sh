@@ -0,0 +1,37 @@
+#!/bin/sh
+# Runs the load test scenarios against bench/server with wrk (default) or bombardier.
+#
+#   ./bench/run.sh [wrk|bombardier]
+#
+# Environment: DURATION (default 10s), CONNECTIONS (default 100), THREADS (wrk only, default 4),
+# ADDR (default 127.0.0.1:8080).
+set -e
+
+TOOL=${1:-wrk}
+DURATION=${DURATION:-10s}
+CONNECTIONS=${CONNECTIONS:-100}
+THREADS=${THREADS:-4}
+ADDR=${ADDR:-127.0.0.1:8080}
+DIR=$(dirname "$0")
+
+go build -o "$DIR/server.bin" "$DIR/server"
+"$DIR/server.bin" -addr "$ADDR" >/dev/null &
+PID=$!
+trap 'kill $PID; rm -f "$DIR/server.bin"' EXIT
+sleep 1
+
+for path in /plaintext /json /users/12/posts/hello /repos/night-codes/tokay/pulls/42 /middleware; do
+	echo "== GET $path"
+	case $TOOL in
+	wrk) wrk -t"$THREADS" -c"$CONNECTIONS" -d"$DURATION" "http://$ADDR$path" ;;
+	bombardier) bombardier -c "$CONNECTIONS" -d "$DURATION" -p r "http://$ADDR$path" ;;
+	*) echo "unknown tool $TOOL" >&2; exit 1 ;;
+	esac
+done
+
+BODY='{"id":1,"name":"John","email":"john@example.com"}'
+echo "== POST /bind"
+case $TOOL in
+wrk) wrk -t"$THREADS" -c"$CONNECTIONS" -d"$DURATION" -s "$DIR/bind.lua" "http://$ADDR/bind" ;;
+bombardier) bombardier -c "$CONNECTIONS" -d "$DURATION" -p r -m POST -H "Content-Type: application/json" -b "$BODY" "http://$ADDR/bind" ;;
+esac
@@ -0,0 +1,39 @@
+// Command server serves the benchmark scenarios of the bench package for the load tests.
+//
+//	go run ./bench/server -addr :8080 -pprof :6060
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	_ "net/http/pprof"
+	"time"
+
+	"github.com/night-codes/tokay/bench"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen")
+	pprofAddr := flag.String("pprof", "", "address of the net/http/pprof server, disabled if empty")
+	statsInterval := flag.Duration("stats", 0, "interval of printing engine.Stats(), disabled if zero")
+	flag.Parse()
+
+	if *pprofAddr != "" {
+		go func() {
+			log.Println(http.ListenAndServe(*pprofAddr, nil))
+		}()
+	}
+
+	engine := bench.NewEngine()
+	if *statsInterval > 0 {
+		go func() {
+			for range time.Tick(*statsInterval) {
+				log.Printf("%+v", engine.Stats())
+			}
+		}()
+	}
+	if err := engine.Run(*addr, "Benchmark server started at %s"); err != nil {
+		log.Fatal(err)
+	}
+}
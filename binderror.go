@@ -0,0 +1,91 @@
+package tokay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/night-codes/go-json"
+)
+
+// bindErrorContext is the number of the request body bytes around the error position in BindError.Fragment.
+const bindErrorContext = 16
+
+// BindError is the JSON request body decoding error with its position, returned by BindJSON.
+// It is marshaled to JSON, so it can be sent to the API clients as is:
+//
+//	if err := c.BindJSON(&order); err != nil {
+//		var bindErr *tokay.BindError
+//		if errors.As(err, &bindErr) {
+//			c.JSON(400, bindErr)
+//			return
+//		}
+//		...
+//	}
+type BindError struct {
+	// Message describes the error.
+	Message string `json:"message"`
+	// Field is the struct field of the value with the wrong type, if any.
+	Field string `json:"field,omitempty"`
+	// Expected is the Go type of the field, if any.
+	Expected string `json:"expected,omitempty"`
+	// Offset is the number of the body bytes read before the error.
+	Offset int64 `json:"offset"`
+	// Line and Column are the 1-based error position, the column counts the characters.
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	// Fragment is the body text around the error position.
+	Fragment string `json:"fragment"`
+	// Err is the decoder error.
+	Err error `json:"-"`
+}
+
+// Error returns the message with the error position.
+func (e *BindError) Error() string {
+	return fmt.Sprintf("json: %s at line %d, column %d (offset %d) near %q", e.Message, e.Line, e.Column, e.Offset, e.Fragment)
+}
+
+// Unwrap returns the decoder error.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// newBindError wraps the JSON decoding error of the body with its position,
+// other errors are returned as is.
+func newBindError(body []byte, err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		bindErr   *BindError
+	)
+	switch {
+	case errors.As(err, &syntaxErr):
+		bindErr = &BindError{Message: strings.TrimPrefix(syntaxErr.Error(), "json: "), Offset: syntaxErr.Offset}
+	case errors.As(err, &typeErr):
+		bindErr = &BindError{Field: typeErr.Field, Offset: typeErr.Offset}
+		if typeErr.Type != nil {
+			bindErr.Expected = typeErr.Type.String()
+		}
+		bindErr.Message = fmt.Sprintf("cannot unmarshal %s into field %q of type %s", typeErr.Value, bindErr.Field, bindErr.Expected)
+	default:
+		return err
+	}
+	bindErr.Err = err
+	if bindErr.Offset > int64(len(body)) {
+		bindErr.Offset = int64(len(body))
+	}
+	before := body[:bindErr.Offset]
+	bindErr.Line = bytes.Count(before, []byte{'\n'}) + 1
+	bindErr.Column = utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	start, end := int(bindErr.Offset)-bindErrorContext, int(bindErr.Offset)+bindErrorContext
+	if start < 0 {
+		start = 0
+	}
+	if end > len(body) {
+		end = len(body)
+	}
+	bindErr.Fragment = strings.ToValidUTF8(string(body[start:end]), "")
+	return bindErr
+}
@@ -0,0 +1,46 @@
+package tokay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/night-codes/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextBindJSONError(t *testing.T) {
+	bind := func(body string) error {
+		c := newTestContext("/", withBody("", body))
+		var obj struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+		}
+		return c.BindJSON(&obj)
+	}
+
+	assert.Nil(t, bind(`{"name": "ok", "count": 1}`))
+
+	err := bind("{\n  \"name\": \"ёжик\",\n  \"count\": 1,\n}")
+	var bindErr *BindError
+	if assert.True(t, errors.As(err, &bindErr)) {
+		assert.Equal(t, 4, bindErr.Line)
+		assert.Equal(t, 1, bindErr.Column)
+		assert.Equal(t, int64(38), bindErr.Offset)
+		assert.Equal(t, "", bindErr.Field)
+		assert.Equal(t, ",\n  \"count\": 1,\n}", bindErr.Fragment)
+		assert.Contains(t, err.Error(), "at line 4, column 1 (offset 38)")
+		var syntaxErr *json.SyntaxError
+		assert.True(t, errors.As(err, &syntaxErr))
+	}
+
+	err = bind(`{"name": "ё", "count": true}`)
+	if assert.True(t, errors.As(err, &bindErr)) {
+		assert.Equal(t, 1, bindErr.Line)
+		assert.Equal(t, int(bindErr.Offset), bindErr.Column)
+		assert.Equal(t, "int", bindErr.Expected)
+		assert.NotEqual(t, "", bindErr.Field)
+		data, _ := json.Marshal(bindErr)
+		assert.Contains(t, string(data), `"expected":"int"`)
+		assert.NotContains(t, string(data), "Err")
+	}
+}
@@ -0,0 +1,395 @@
+package tokay
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Broker delivers the messages published by any server instance to the subscribers of all
+// instances, so Hub broadcasts reach the clients connected to the other instances.
+type Broker interface {
+	// Publish sends the message to the topic subscribers.
+	Publish(topic string, message []byte) error
+	// Subscribe registers the handler of the topic messages. Handlers are called sequentially,
+	// so they should not block for long.
+	Subscribe(topic string, handler func(message []byte)) (unsubscribe func(), err error)
+	// Close releases the broker resources.
+	Close() error
+}
+
+type (
+	// subscriptions are the topic handlers of a broker.
+	subscriptions struct {
+		sync.RWMutex
+		topics map[string]map[*subscription]struct{}
+	}
+
+	subscription struct {
+		handler func(message []byte)
+	}
+
+	// localBroker delivers messages to the subscribers of the current process.
+	localBroker struct {
+		subs subscriptions
+	}
+)
+
+// add registers the topic handler. It returns true for the first handler of the topic.
+func (s *subscriptions) add(topic string, handler func(message []byte)) (*subscription, bool) {
+	sub := &subscription{handler: handler}
+	s.Lock()
+	defer s.Unlock()
+	if s.topics == nil {
+		s.topics = make(map[string]map[*subscription]struct{})
+	}
+	handlers, ok := s.topics[topic]
+	if !ok {
+		handlers = make(map[*subscription]struct{})
+		s.topics[topic] = handlers
+	}
+	handlers[sub] = struct{}{}
+	return sub, !ok
+}
+
+// remove unregisters the topic handler. It returns true when the last handler of the topic is removed.
+func (s *subscriptions) remove(topic string, sub *subscription) bool {
+	s.Lock()
+	defer s.Unlock()
+	handlers, ok := s.topics[topic]
+	if !ok {
+		return false
+	}
+	delete(handlers, sub)
+	if len(handlers) == 0 {
+		delete(s.topics, topic)
+		return true
+	}
+	return false
+}
+
+// dispatch calls the topic handlers.
+func (s *subscriptions) dispatch(topic string, message []byte) {
+	s.RLock()
+	handlers := make([]*subscription, 0, len(s.topics[topic]))
+	for sub := range s.topics[topic] {
+		handlers = append(handlers, sub)
+	}
+	s.RUnlock()
+	for _, sub := range handlers {
+		sub.handler(message)
+	}
+}
+
+// names returns the subscribed topics.
+func (s *subscriptions) names() []string {
+	s.RLock()
+	defer s.RUnlock()
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	return topics
+}
+
+// NewLocalBroker creates the Broker delivering messages within the current process only.
+// It is used by NewHub when no broker is given.
+func NewLocalBroker() Broker {
+	return &localBroker{}
+}
+
+// Publish implements Broker.
+func (b *localBroker) Publish(topic string, message []byte) error {
+	b.subs.dispatch(topic, message)
+	return nil
+}
+
+// Subscribe implements Broker.
+func (b *localBroker) Subscribe(topic string, handler func(message []byte)) (func(), error) {
+	sub, _ := b.subs.add(topic, handler)
+	return func() { b.subs.remove(topic, sub) }, nil
+}
+
+// Close implements Broker.
+func (b *localBroker) Close() error {
+	return nil
+}
+
+// RedisConfig is a struct for specifying Redis broker options.
+type RedisConfig struct {
+	// Addr is the Redis server address. Default is "127.0.0.1:6379".
+	Addr string
+	// Password is the AUTH password.
+	Password string
+	// Prefix is prepended to the topics to get the Redis channel names. Default is "tokay:".
+	Prefix string
+	// DialTimeout is the connection timeout. Default is 5 seconds.
+	DialTimeout time.Duration
+	// ReconnectDelay is the delay between reconnection attempts. Default is 1 second.
+	ReconnectDelay time.Duration
+	// Logger receives the reconnection errors, usually engine.Logger(). Default is the
+	// standard logger.
+	Logger FieldLogger
+}
+
+type (
+	// redisBroker delivers messages through the Redis pub/sub.
+	redisBroker struct {
+		config    RedisConfig
+		subs      subscriptions
+		pubMu     sync.Mutex
+		pub       *redisConn // connection for PUBLISH commands, nil when broken
+		subMu     sync.Mutex
+		sub       *redisConn // connection in the subscribed state
+		closed    chan struct{}
+		closeOnce sync.Once
+	}
+
+	// redisConn is a connection speaking the Redis protocol (RESP).
+	redisConn struct {
+		net.Conn
+		r *bufio.Reader
+	}
+
+	// redisError is the error reply of the Redis server.
+	redisError string
+)
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// NewRedisBroker connects to the Redis server and creates the Broker delivering messages through
+// the Redis pub/sub. Broken connections are restored and the topics are subscribed again.
+//
+//	broker, err := tokay.NewRedisBroker(tokay.RedisConfig{Addr: "redis:6379", Logger: engine.Logger()})
+//	hub := tokay.NewHub(broker)
+func NewRedisBroker(config RedisConfig) (Broker, error) {
+	if config.Addr == "" {
+		config.Addr = "127.0.0.1:6379"
+	}
+	if config.Prefix == "" {
+		config.Prefix = "tokay:"
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = time.Second
+	}
+	if config.Logger == nil {
+		config.Logger = &stdLogger{}
+	}
+	b := &redisBroker{config: config, closed: make(chan struct{})}
+	var err error
+	if b.pub, err = b.dial(); err != nil {
+		return nil, err
+	}
+	if b.sub, err = b.dial(); err != nil {
+		b.pub.Close()
+		return nil, err
+	}
+	go b.listen()
+	return b, nil
+}
+
+// Publish implements Broker.
+func (b *redisBroker) Publish(topic string, message []byte) (err error) {
+	b.pubMu.Lock()
+	defer b.pubMu.Unlock()
+	// a broken connection is restored once
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.pub == nil {
+			if b.pub, err = b.dial(); err != nil {
+				return err
+			}
+		}
+		if _, err = b.pub.do("PUBLISH", b.config.Prefix+topic, string(message)); err == nil {
+			return nil
+		}
+		if _, ok := err.(redisError); ok {
+			return err
+		}
+		b.pub.Close()
+		b.pub = nil
+	}
+	return err
+}
+
+// Subscribe implements Broker.
+func (b *redisBroker) Subscribe(topic string, handler func(message []byte)) (func(), error) {
+	sub, first := b.subs.add(topic, handler)
+	if first {
+		b.subMu.Lock()
+		// on failure the topic is subscribed when the connection is restored
+		b.sub.send("SUBSCRIBE", b.config.Prefix+topic)
+		b.subMu.Unlock()
+	}
+	return func() {
+		if b.subs.remove(topic, sub) {
+			b.subMu.Lock()
+			b.sub.send("UNSUBSCRIBE", b.config.Prefix+topic)
+			b.subMu.Unlock()
+		}
+	}, nil
+}
+
+// Close implements Broker.
+func (b *redisBroker) Close() error {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+		b.pubMu.Lock()
+		if b.pub != nil {
+			b.pub.Close()
+		}
+		b.pubMu.Unlock()
+		b.subMu.Lock()
+		b.sub.Close()
+		b.subMu.Unlock()
+	})
+	return nil
+}
+
+// listen reads the messages of the subscribed channels and restores the broken connection.
+func (b *redisBroker) listen() {
+	for {
+		b.subMu.Lock()
+		conn := b.sub
+		b.subMu.Unlock()
+		for {
+			reply, err := conn.read()
+			if err != nil {
+				break
+			}
+			if msg, ok := reply.([]interface{}); ok && len(msg) == 3 {
+				kind, _ := msg[0].([]byte)
+				channel, _ := msg[1].([]byte)
+				payload, _ := msg[2].([]byte)
+				if string(kind) == "message" {
+					b.subs.dispatch(strings.TrimPrefix(string(channel), b.config.Prefix), payload)
+				}
+			}
+		}
+		conn.Close()
+
+		for {
+			select {
+			case <-b.closed:
+				return
+			case <-time.After(b.config.ReconnectDelay):
+			}
+			conn, err := b.dial()
+			if err != nil {
+				b.config.Logger.Warn("cannot reconnect to redis", "addr", b.config.Addr, "error", err)
+				continue
+			}
+			b.subMu.Lock()
+			b.sub = conn
+			if topics := b.subs.names(); len(topics) != 0 {
+				args := []string{"SUBSCRIBE"}
+				for _, topic := range topics {
+					args = append(args, b.config.Prefix+topic)
+				}
+				conn.send(args...)
+			}
+			b.subMu.Unlock()
+			break
+		}
+	}
+}
+
+// dial connects and authenticates to the Redis server.
+func (b *redisBroker) dial() (*redisConn, error) {
+	conn, err := net.DialTimeout("tcp", b.config.Addr, b.config.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{Conn: conn, r: bufio.NewReader(conn)}
+	if b.config.Password != "" {
+		if _, err = c.do("AUTH", b.config.Password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// send writes the command.
+func (c *redisConn) send(args ...string) error {
+	var buf []byte
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, "\r\n"...)
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, "\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	_, err := c.Write(buf)
+	return err
+}
+
+// do writes the command and reads the reply.
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+	reply, err := c.read()
+	if err == nil {
+		if e, ok := reply.(redisError); ok {
+			return nil, e
+		}
+	}
+	return reply, err
+}
+
+// read reads the reply: string, redisError, int64, []byte, []interface{} or nil.
+func (c *redisConn) read() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.New("redis: malformed reply")
+	}
+	kind, line := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return line, nil
+	case '-':
+		return redisError(line), nil
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = c.read(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+}
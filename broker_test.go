@@ -0,0 +1,199 @@
+package tokay
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis is a minimal Redis pub/sub server.
+type fakeRedis struct {
+	sync.Mutex
+	ln          net.Listener
+	subscribers map[string]map[*fakeRedisConn]struct{}
+	conns       []*fakeRedisConn
+}
+
+type fakeRedisConn struct {
+	sync.Mutex
+	*redisConn
+}
+
+func (c *fakeRedisConn) reply(s string) {
+	c.Lock()
+	c.Write([]byte(s))
+	c.Unlock()
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(t, err)
+	r := &fakeRedis{ln: ln, subscribers: make(map[string]map[*fakeRedisConn]struct{})}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			c := &fakeRedisConn{redisConn: &redisConn{Conn: conn, r: bufio.NewReader(conn)}}
+			r.Lock()
+			r.conns = append(r.conns, c)
+			r.Unlock()
+			go r.serve(c)
+		}
+	}()
+	return r
+}
+
+func (r *fakeRedis) serve(c *fakeRedisConn) {
+	defer c.Close()
+	for {
+		reply, err := c.read()
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range reply.([]interface{}) {
+			args = append(args, string(arg.([]byte)))
+		}
+		r.Lock()
+		switch args[0] {
+		case "PUBLISH":
+			for sub := range r.subscribers[args[1]] {
+				sub.reply("*3\r\n" + bulk("message") + bulk(args[1]) + bulk(args[2]))
+			}
+			c.reply(":" + strconv.Itoa(len(r.subscribers[args[1]])) + "\r\n")
+		case "SUBSCRIBE":
+			for _, channel := range args[1:] {
+				if r.subscribers[channel] == nil {
+					r.subscribers[channel] = make(map[*fakeRedisConn]struct{})
+				}
+				r.subscribers[channel][c] = struct{}{}
+				c.reply("*3\r\n" + bulk("subscribe") + bulk(channel) + ":1\r\n")
+			}
+		case "UNSUBSCRIBE":
+			for _, channel := range args[1:] {
+				delete(r.subscribers[channel], c)
+				c.reply("*3\r\n" + bulk("unsubscribe") + bulk(channel) + ":0\r\n")
+			}
+		default:
+			c.reply("-ERR unknown command\r\n")
+		}
+		r.Unlock()
+	}
+}
+
+// dropConnections closes all client connections.
+func (r *fakeRedis) dropConnections() {
+	r.Lock()
+	defer r.Unlock()
+	for _, c := range r.conns {
+		c.Close()
+	}
+	r.conns = nil
+	r.subscribers = make(map[string]map[*fakeRedisConn]struct{})
+}
+
+// receive waits for the message from the channel.
+func receive(t *testing.T, messages chan string) string {
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(time.Second):
+		t.Error("message is not received")
+		return ""
+	}
+}
+
+func TestLocalBroker(t *testing.T) {
+	broker := NewLocalBroker()
+	messages := make(chan string, 10)
+	unsubscribe, err := broker.Subscribe("chat", func(message []byte) {
+		messages <- string(message)
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, broker.Publish("chat", []byte("hello")))
+	assert.Nil(t, broker.Publish("news", []byte("skipped")))
+	assert.Equal(t, "hello", receive(t, messages))
+	unsubscribe()
+	assert.Nil(t, broker.Publish("chat", []byte("unsubscribed")))
+	assert.Empty(t, messages)
+	assert.Nil(t, broker.Close())
+}
+
+func TestRedisBroker(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.ln.Close()
+	config := RedisConfig{Addr: server.ln.Addr().String(), ReconnectDelay: 10 * time.Millisecond}
+
+	// two server instances share the messages
+	broker1, err := NewRedisBroker(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer broker1.Close()
+	broker2, err := NewRedisBroker(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer broker2.Close()
+
+	messages := make(chan string, 10)
+	_, err = broker2.Subscribe("chat", func(message []byte) {
+		messages <- "2:" + string(message)
+	})
+	assert.Nil(t, err)
+	unsubscribe, _ := broker1.Subscribe("chat", func(message []byte) {
+		messages <- "1:" + string(message)
+	})
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Nil(t, broker1.Publish("chat", []byte("hello\r\nworld")))
+	received := []string{receive(t, messages), receive(t, messages)}
+	assert.ElementsMatch(t, []string{"1:hello\r\nworld", "2:hello\r\nworld"}, received)
+
+	unsubscribe()
+	time.Sleep(50 * time.Millisecond)
+	assert.Nil(t, broker1.Publish("chat", []byte("again")))
+	assert.Equal(t, "2:again", receive(t, messages))
+
+	// the connections are restored and the topics are subscribed again
+	server.dropConnections()
+	time.Sleep(100 * time.Millisecond)
+	assert.Nil(t, broker1.Publish("chat", []byte("restored")))
+	assert.Equal(t, "2:restored", receive(t, messages))
+
+	_, err = NewRedisBroker(RedisConfig{Addr: "127.0.0.1:1"})
+	assert.NotNil(t, err)
+}
+
+func TestRedisBrokerReconnectError(t *testing.T) {
+	server := newFakeRedis(t)
+	logger := &eventLogger{}
+	addr := server.ln.Addr().String()
+	broker, err := NewRedisBroker(RedisConfig{Addr: addr, ReconnectDelay: 10 * time.Millisecond, Logger: logger})
+	if !assert.Nil(t, err) {
+		server.ln.Close()
+		return
+	}
+	defer broker.Close()
+
+	server.ln.Close()
+	server.dropConnections()
+	time.Sleep(100 * time.Millisecond)
+	logger.Lock()
+	defer logger.Unlock()
+	if assert.NotEmpty(t, logger.events) {
+		assert.True(t, strings.HasPrefix(logger.events[0], "cannot reconnect to redis addr "+addr+" error "), logger.events[0])
+	}
+}
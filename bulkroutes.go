@@ -0,0 +1,63 @@
+package tokay
+
+import "strings"
+
+// RouteDef is a route registered by AddRoutes.
+type RouteDef struct {
+	Method   string // HTTP methods separated by commas, default is GET
+	Path     string
+	Name     string
+	Handlers []Handler
+}
+
+// AddRoutes registers the routes in one pass, for the applications registering tens of thousands
+// of routes (like the generated APIs): the engine route tables are sized once and the URL templates
+// are built on the first URL call. The routes are returned in the order of defs.
+//
+//	engine.AddRoutes([]tokay.RouteDef{
+//		{Path: "/users", Handlers: []tokay.Handler{listUsers}},
+//		{Method: "POST,PUT", Path: "/users/<id>", Name: "user", Handlers: []tokay.Handler{auth, saveUser}},
+//	})
+func (r *RouterGroup) AddRoutes(defs []RouteDef) []*Route {
+	r.engine.growRoutes(len(defs))
+	routes := make([]*Route, len(defs))
+	for i, def := range defs {
+		route := registerRoute(def.Path, r)
+		method := def.Method
+		if method == "" {
+			method = "GET"
+		}
+		for _, m := range strings.Split(method, ",") {
+			route.add(m, def.Handlers)
+		}
+		if def.Name != "" {
+			route.Name(def.Name)
+		}
+		routes[i] = route
+	}
+	return routes
+}
+
+// growRoutes resizes the route tables of the engine for n more routes.
+func (engine *Engine) growRoutes(n int) {
+	if cap(engine.routeList)-len(engine.routeList) < n {
+		list := make([]*Route, len(engine.routeList), len(engine.routeList)+n)
+		copy(list, engine.routeList)
+		engine.routeList = list
+	}
+	routes := make(map[string]*Route, len(engine.routes)+n)
+	for name, route := range engine.routes {
+		routes[name] = route
+	}
+	engine.routes = routes
+	candidates := make(map[string]*routeHandlers, len(engine.candidates)+n)
+	for key, rh := range engine.candidates {
+		candidates[key] = rh
+	}
+	engine.candidates = candidates
+	allowed := make(map[string]*allowedMethods, len(engine.allowed)+n)
+	for path, methods := range engine.allowed {
+		allowed[path] = methods
+	}
+	engine.allowed = allowed
+}
@@ -0,0 +1,34 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterGroupAddRoutes(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) {})
+	api := engine.Group("/api")
+	handler := func(c *Context) {
+		c.String(200, c.Method()+" "+c.URL("user", "id", c.Param("id")))
+	}
+	routes := api.AddRoutes([]RouteDef{
+		{Path: "/users", Handlers: []Handler{handler}},
+		{Method: "POST,PUT", Path: "/users/<id:\\d+>", Name: "user", Handlers: []Handler{handler}},
+	})
+	if assert.Len(t, routes, 2) {
+		assert.Equal(t, "/api/users", routes[0].Path())
+		assert.Equal(t, []string{"POST", "PUT"}, routes[1].Info().Methods)
+		assert.Equal(t, "", routes[1].template, "the template is deferred")
+	}
+	assert.Len(t, engine.Routes(), 3)
+	assert.Equal(t, routes[1], engine.Route("user"))
+
+	ctx := serveTestRequest(engine, "/api/users")
+	assert.Equal(t, "GET /api/users/", string(ctx.Response.Body()))
+
+	ctx = serveTestRequest(engine, "/api/users/7", withMethod("PUT"))
+	assert.Equal(t, "PUT /api/users/7", string(ctx.Response.Body()))
+	assert.Equal(t, "/api/users/<id>", routes[1].template)
+}
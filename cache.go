@@ -0,0 +1,163 @@
+package tokay
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries is the maximum number of cached responses.
+const maxCacheEntries = 10000
+
+type (
+	// cacheRule is the response caching rule of the route (see Route.CacheFor).
+	cacheRule struct {
+		ttl  time.Duration
+		vary []string
+	}
+
+	// responseCache keeps the cached responses of the engine.
+	responseCache struct {
+		sync.RWMutex
+		entries map[string]*cacheEntry
+	}
+
+	// cacheEntry is a cached response.
+	cacheEntry struct {
+		path    string // the request path matched by PurgeCache
+		status  int
+		headers [][2][]byte // the headers set by the route handler
+		body    []byte
+		created time.Time
+		expires time.Time
+	}
+)
+
+// CacheFor enables caching of the GET and HEAD responses of the route for the ttl. The cached responses
+// are kept per host, request URI and the values of the varyHeaders. Only the last route handler
+// is skipped when the response is cached, so the middlewares (authorization etc.) are called
+// for every request. Successful responses without cookies are cached (see also Engine.PurgeCache).
+//
+//	engine.GET("/products/<id>", showProduct).CacheFor(time.Minute, "Accept-Language")
+func (r *Route) CacheFor(ttl time.Duration, varyHeaders ...string) *Route {
+	rule := &cacheRule{ttl: ttl, vary: varyHeaders}
+	r.insert(rule.handle, "GET", "HEAD")
+	return r
+}
+
+// handle serves the cached response or caches the response of the next handler.
+func (rule *cacheRule) handle(c *Context) {
+	cache := c.engine.cache
+	key := rule.key(c)
+	if entry := cache.get(key); entry != nil {
+		c.SetStatusCode(entry.status)
+		for _, h := range entry.headers {
+			c.Response.Header.SetBytesKV(h[0], h[1])
+		}
+		c.Response.Header.Set("Age", strconv.Itoa(int(time.Since(entry.created)/time.Second)))
+		c.Response.Header.Set("X-Cache", "HIT")
+		c.Response.SetBody(entry.body)
+		c.skipHandlers()
+		return
+	}
+
+	before := make(map[string]string)
+	c.Response.Header.VisitAll(func(key, value []byte) {
+		before[string(key)] = string(value)
+	})
+	c.Next()
+	if c.Response.StatusCode() != http.StatusOK || c.Response.IsBodyStream() || len(c.Response.Header.Peek("Set-Cookie")) != 0 {
+		return
+	}
+	now := time.Now()
+	entry := &cacheEntry{
+		path:    c.Path(),
+		status:  c.Response.StatusCode(),
+		body:    append([]byte(nil), c.Response.Body()...),
+		created: now,
+		expires: now.Add(rule.ttl),
+	}
+	c.Response.Header.VisitAll(func(key, value []byte) {
+		if v, ok := before[string(key)]; !ok || v != string(value) {
+			entry.headers = append(entry.headers, [2][]byte{append([]byte(nil), key...), append([]byte(nil), value...)})
+		}
+	})
+	cache.set(key, entry)
+	c.Response.Header.Set("X-Cache", "MISS")
+}
+
+// key returns the cache key of the request.
+func (rule *cacheRule) key(c *Context) string {
+	var b strings.Builder
+	b.WriteString(c.Host())
+	b.WriteString(c.RequestURI())
+	for _, header := range rule.vary {
+		b.WriteByte('\n')
+		b.WriteString(c.GetHeader(header))
+	}
+	return b.String()
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: make(map[string]*cacheEntry)}
+}
+
+// get returns the fresh cached response or nil.
+func (cache *responseCache) get(key string) *cacheEntry {
+	cache.RLock()
+	entry := cache.entries[key]
+	cache.RUnlock()
+	if entry == nil || time.Now().After(entry.expires) {
+		return nil
+	}
+	return entry
+}
+
+// set caches the response. Expired responses are removed when the cache is full.
+func (cache *responseCache) set(key string, entry *cacheEntry) {
+	cache.Lock()
+	defer cache.Unlock()
+	if len(cache.entries) >= maxCacheEntries {
+		now := time.Now()
+		for k, e := range cache.entries {
+			if now.After(e.expires) {
+				delete(cache.entries, k)
+			}
+		}
+		if len(cache.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	cache.entries[key] = entry
+}
+
+// PurgeCache removes the cached responses of the request paths matching the pattern
+// (see path.Match, "*" matches a single path segment; "" purges all responses).
+// It returns the number of removed responses.
+//
+//	engine.PurgeCache("/products/*")
+func (engine *Engine) PurgeCache(pattern string) int {
+	cache := engine.cache
+	cache.Lock()
+	defer cache.Unlock()
+	purged := 0
+	for key, entry := range cache.entries {
+		if matched, _ := path.Match(pattern, entry.path); matched || pattern == "" {
+			delete(cache.entries, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// CachePurgeHandler purges the cached responses of the paths matching the "pattern" query parameter
+// (see Engine.PurgeCache) and responds with the number of purged responses as JSON. Protect its route
+// with an authorization middleware.
+//
+//	admin.POST("/cache/purge", tokay.CachePurgeHandler)
+func CachePurgeHandler(c *Context) {
+	c.JSON(http.StatusOK, map[string]int{"purged": c.engine.PurgeCache(c.Query("pattern"))})
+}
@@ -0,0 +1,84 @@
+package tokay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouteCacheFor(t *testing.T) {
+	engine := New()
+	calls, authorized, aborted := 0, 0, 0
+	engine.OnAbort(func(c *Context) {
+		aborted++
+	})
+	engine.Use(func(c *Context) {
+		authorized++
+		c.Header("X-Request", "1")
+		c.Next()
+		if c.IsAborted() {
+			aborted++
+		}
+	})
+	engine.GET("/products/<id>", func(c *Context) {
+		calls++
+		c.Header("X-Handler", c.Param("id"))
+		c.JSON(200, map[string]int{"calls": calls})
+	}).CacheFor(time.Minute, "Accept-Language")
+	engine.GET("/cookie", func(c *Context) {
+		calls++
+		c.SetCookie("session", "1", "/", "", false, false)
+		c.String(200, "cookie")
+	}).CacheFor(time.Minute)
+	engine.POST("/purge", CachePurgeHandler)
+
+	request := func(uri, lang string) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, uri, withHeader("Accept-Language", lang))
+	}
+
+	ctx := request("/products/1", "en")
+	assert.Equal(t, "MISS", string(ctx.Response.Header.Peek("X-Cache")))
+	ctx = request("/products/1", "en")
+	assert.Equal(t, "HIT", string(ctx.Response.Header.Peek("X-Cache")))
+	assert.Equal(t, `{"calls":1}`, string(ctx.Response.Body()))
+	assert.Equal(t, "application/json; charset=UTF-8", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek("X-Handler")))
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek("X-Request")))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 2, authorized)
+	// the cache hits are not the aborted requests
+	assert.Equal(t, 0, aborted)
+
+	ctx = request("/products/1", "de")
+	assert.Equal(t, `{"calls":2}`, string(ctx.Response.Body()))
+	ctx = request("/products/2", "en")
+	assert.Equal(t, `{"calls":3}`, string(ctx.Response.Body()))
+
+	request("/cookie", "")
+	ctx = request("/cookie", "")
+	assert.Equal(t, "", string(ctx.Response.Header.Peek("X-Cache")))
+	assert.Equal(t, 5, calls)
+
+	assert.Equal(t, 2, engine.PurgeCache("/products/1"))
+	ctx = request("/products/1", "en")
+	assert.Equal(t, `{"calls":6}`, string(ctx.Response.Body()))
+
+	ctx = serveTestRequest(engine, "/purge?pattern=/products/*", withMethod("POST"))
+	assert.JSONEq(t, `{"purged":2}`, string(ctx.Response.Body()))
+}
+
+func TestRouteCacheForLaterMethods(t *testing.T) {
+	engine := New()
+	calls := 0
+	route := engine.To("POST", "/items", func(c *Context) {}).CacheFor(time.Minute)
+	route.GET(func(c *Context) {
+		calls++
+		c.String(200, "items")
+	})
+	serveTestRequest(engine, "/items")
+	ctx := serveTestRequest(engine, "/items")
+	assert.Equal(t, "HIT", string(ctx.Response.Header.Peek("X-Cache")))
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,40 @@
+package tokay
+
+import (
+	"net/http"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// cborEncMode encodes the structs with the "cbor" (or "json") field tags and the times as RFC 3339 strings.
+var cborEncMode, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+
+// CBOR marshals the given interface object and writes the CBOR (RFC 8949) response.
+// The struct fields are named by the "cbor" tags or the "json" ones.
+func (c *Context) CBOR(statusCode int, obj interface{}) {
+	data, err := cborEncMode.Marshal(obj)
+	if err != nil {
+		c.Error(err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c.Data(statusCode, "application/cbor", data)
+}
+
+// BindCBOR binds the passed struct pointer with CBOR request body data
+func (c *Context) BindCBOR(obj interface{}) error {
+	return c.validate(cbor.Unmarshal(c.Body(), obj), obj)
+}
+
+// Negotiate writes the obj as CBOR, XML or JSON (the default) according to the Accept header.
+//
+//	c.Negotiate(200, reading)
+func (c *Context) Negotiate(statusCode int, obj interface{}) {
+	switch c.Accepts("application/json", "application/cbor", "application/xml", "text/xml") {
+	case "application/cbor":
+		c.CBOR(statusCode, obj)
+	case "application/xml", "text/xml":
+		c.XML(statusCode, obj)
+	default:
+		c.JSON(statusCode, obj)
+	}
+}
@@ -0,0 +1,55 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type cborReading struct {
+	Sensor string  `json:"sensor" valid:"required"`
+	Value  float64 `cbor:"v"`
+}
+
+func TestContextCBOR(t *testing.T) {
+	engine := New()
+	engine.POST("/readings", func(c *Context) {
+		var r cborReading
+		if err := c.Bind(&r); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.Negotiate(201, r)
+	})
+
+	body, err := cbor.Marshal(cborReading{Sensor: "t1", Value: 21.5})
+	assert.Nil(t, err)
+	request := func(contentType, accept string, body []byte) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, "/readings", withMethod("POST"), withHeader("Accept", accept), withBody(contentType, string(body)))
+	}
+
+	ctx := request("application/cbor", "application/cbor", body)
+	assert.Equal(t, 201, ctx.Response.StatusCode())
+	assert.Equal(t, "application/cbor", string(ctx.Response.Header.ContentType()))
+	var r cborReading
+	assert.Nil(t, cbor.Unmarshal(ctx.Response.Body(), &r))
+	assert.Equal(t, cborReading{Sensor: "t1", Value: 21.5}, r)
+
+	ctx = request("application/cbor", "", body)
+	assert.Equal(t, `{"sensor":"t1","Value":21.5}`, string(ctx.Response.Body()))
+
+	ctx = request("application/json", "application/cbor;q=0.9, application/json;q=0.5", []byte(`{"sensor":"t2","Value":1}`))
+	assert.Nil(t, cbor.Unmarshal(ctx.Response.Body(), &r))
+	assert.Equal(t, "t2", r.Sensor)
+
+	ctx = request("application/xml", "text/xml", []byte(`<cborReading><Sensor>t3</Sensor></cborReading>`))
+	assert.Contains(t, string(ctx.Response.Body()), "<Sensor>t3</Sensor>")
+
+	body, _ = cbor.Marshal(map[string]float64{"v": 1})
+	ctx = request("application/cbor", "", body)
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+	ctx = request("application/cbor", "", []byte{0xff})
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+}
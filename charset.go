@@ -0,0 +1,97 @@
+package tokay
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Charset returns the charset parameter of the request Content-Type header in lower case,
+// or an empty string if it is missing.
+func (c *Context) Charset() string {
+	_, params, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(params["charset"])
+}
+
+// Body returns the request body converted to UTF-8 from the charset of the Content-Type header
+// (ISO-8859-1, Windows-1251, UTF-16 etc). The body is returned as is for UTF-8 and unknown charsets.
+// Use c.Request.Body() to get the raw body. The returned body is valid until the request modification.
+func (c *Context) Body() []byte {
+	if c.decodedBody != nil {
+		return c.decodedBody
+	}
+	body := c.Request.Body()
+	if enc := c.requestEncoding(); enc != nil {
+		// the byte order mark overrides the charset and is removed
+		if decoded, _, err := transform.Bytes(unicode.BOMOverride(enc.NewDecoder()), body); err == nil {
+			c.decodedBody = decoded
+			return decoded
+		}
+	}
+	return body
+}
+
+// PostArgs returns POST arguments with the values converted to UTF-8 from the charset of the
+// Content-Type header (see Body).
+func (c *Context) PostArgs() *fasthttp.Args {
+	args := c.RequestCtx.PostArgs()
+	enc := c.requestEncoding()
+	if enc == nil {
+		return args
+	}
+	if c.decodedArgs == nil {
+		c.decodedArgs = &fasthttp.Args{}
+		decoder := enc.NewDecoder()
+		args.VisitAll(func(key, value []byte) {
+			if k, err := decoder.Bytes(key); err == nil {
+				key = k
+			}
+			if v, err := decoder.Bytes(value); err == nil {
+				value = v
+			}
+			c.decodedArgs.AddBytesKV(key, value)
+		})
+	}
+	return c.decodedArgs
+}
+
+// requestEncoding returns the encoding of the non-UTF-8 request charset, or nil.
+func (c *Context) requestEncoding() encoding.Encoding {
+	charset := c.Charset()
+	if charset == "" || charset == "utf-8" || charset == "utf8" || charset == "us-ascii" {
+		return nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil || enc == unicode.UTF8 {
+		return nil
+	}
+	return enc
+}
+
+// unmarshalXML decodes the XML body. The body converted to UTF-8 (see Body) is decoded ignoring
+// the encoding declaration, otherwise the declared encoding is converted.
+func unmarshalXML(body []byte, converted bool, obj interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if converted {
+			return input, nil
+		}
+		enc, err := htmlindex.Get(charset)
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	}
+	return decoder.Decode(obj)
+}
@@ -0,0 +1,51 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+func TestContextCharset(t *testing.T) {
+	type user struct {
+		Name string `json:"name" xml:"name" form:"name"`
+	}
+	cp1251, _ := charmap.Windows1251.NewEncoder().String("Привет")
+	utf16, _ := unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewEncoder().String(`{"name":"Grüße"}`)
+	latin1, _ := charmap.ISO8859_1.NewEncoder().String("Grüße")
+	post := withMethod("POST")
+
+	c := newTestContext("/", post, withBody("text/plain; charset=Windows-1251", cp1251))
+	assert.Equal(t, "windows-1251", c.Charset())
+	assert.Equal(t, "Привет", string(c.Body()))
+	assert.Equal(t, cp1251, string(c.Request.Body()))
+
+	var u user
+	c = newTestContext("/", post, withBody("application/json; charset=utf-16", utf16))
+	assert.Nil(t, c.Bind(&u))
+	assert.Equal(t, "Grüße", u.Name)
+
+	c = newTestContext("/", post, withBody("application/xml; charset=iso-8859-1", `<?xml version="1.0" encoding="ISO-8859-1"?><user><name>`+latin1+`</name></user>`))
+	assert.Nil(t, c.Bind(&u))
+	assert.Equal(t, "Grüße", u.Name)
+
+	// the declared encoding is converted when the Content-Type has no charset
+	c = newTestContext("/", post, withBody("application/xml", `<?xml version="1.0" encoding="windows-1251"?><user><name>`+cp1251+`</name></user>`))
+	assert.Nil(t, c.Bind(&u))
+	assert.Equal(t, "Привет", u.Name)
+	c = newTestContext("/", post, withBody("application/xml", `<?xml version="1.0" encoding="unknown"?><user><name>x</name></user>`))
+	assert.NotNil(t, c.Bind(&u))
+
+	c = newTestContext("/", post, withBody("application/x-www-form-urlencoded; charset=windows-1251", "name=%CF%F0%E8%E2%E5%F2"))
+	assert.Nil(t, c.Bind(&u))
+	assert.Equal(t, "Привет", u.Name)
+	assert.Equal(t, "Привет", c.PostForm("name"))
+
+	c = newTestContext("/", post, withBody("text/plain; charset=unknown", "raw"))
+	assert.Equal(t, "raw", string(c.Body()))
+	c = newTestContext("/", post, withBody("text/plain", "Привет"))
+	assert.Equal(t, "", c.Charset())
+	assert.Equal(t, "Привет", string(c.Body()))
+}
@@ -0,0 +1,49 @@
+package tokay
+
+import (
+	"crypto/x509"
+	"net/http"
+)
+
+// ClientCertKey is the context data key for the verified TLS client certificate.
+const ClientCertKey = "tlsClientCert"
+
+// TLSClientCert returns the leaf certificate presented by the client (mutual TLS).
+// Nil is returned for non-TLS requests and when the client has not sent a certificate.
+// Use c.TLSConnectionState() for the full TLS connection state.
+func (c *Context) TLSClientCert() *x509.Certificate {
+	if state := c.TLSConnectionState(); state != nil && len(state.PeerCertificates) > 0 {
+		return state.PeerCertificates[0]
+	}
+	return nil
+}
+
+// RequireClientCert returns a middleware which requires a TLS client certificate.
+// The optional validator may reject the certificate (for example, by subject or organization).
+// The certificate is stored in the context data with ClientCertKey and its subject common
+// name is stored as AuthUserKey.
+//
+//	api := engine.Group("/internal", tokay.RequireClientCert(func(cert *x509.Certificate) error {
+//		if cert.Subject.CommonName != "billing" {
+//			return errors.New("unknown service")
+//		}
+//		return nil
+//	}))
+func RequireClientCert(validator func(cert *x509.Certificate) error) Handler {
+	return func(c *Context) {
+		cert := c.TLSClientCert()
+		if cert == nil {
+			c.AbortWithError(http.StatusUnauthorized, nil)
+			return
+		}
+		if validator != nil {
+			if err := validator(cert); err != nil {
+				c.AbortWithError(http.StatusForbidden, nil)
+				return
+			}
+		}
+
+		c.Set(ClientCertKey, cert)
+		c.Set(AuthUserKey, cert.Subject.CommonName)
+	}
+}
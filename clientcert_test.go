@@ -0,0 +1,88 @@
+package tokay
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequireClientCert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	serverCert, serverKey := writeTestCert(t, dir, "server", "localhost")
+	billingCert, billingKey := writeTestCert(t, dir, "billing")
+	reportsCert, reportsKey := writeTestCert(t, dir, "reports")
+	// the self-signed client certificates are their own authorities
+	caFile := filepath.Join(dir, "ca.pem")
+	var pem []byte
+	for _, file := range []string{billingCert, reportsCert} {
+		b, err := ioutil.ReadFile(file)
+		assert.Nil(t, err)
+		pem = append(pem, b...)
+	}
+	assert.Nil(t, ioutil.WriteFile(caFile, pem, 0600))
+
+	pool := x509.NewCertPool()
+	engine := New(&Config{ClientAuth: tls.VerifyClientCertIfGiven, ClientCAs: pool, ClientCAFile: caFile})
+	engine.GET("/", func(c *Context) {
+		c.String(200, "public")
+	})
+	engine.GET("/internal", RequireClientCert(func(cert *x509.Certificate) error {
+		if cert.Subject.CommonName != "billing" {
+			return errors.New("unknown service")
+		}
+		return nil
+	}), func(c *Context) {
+		assert.Equal(t, c.TLSClientCert(), c.Get(ClientCertKey))
+		c.String(200, c.Get(AuthUserKey).(string))
+	})
+	go engine.RunTLS("127.0.0.1:0", serverCert, serverKey, "")
+	for i := 0; i < 50 && engine.Addr() == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !assert.NotNil(t, engine.Addr()) {
+		return
+	}
+	defer engine.Close()
+	url := "https://" + engine.Addr().String()
+
+	get := func(certFile, keyFile, path string) (int, string) {
+		config := &tls.Config{InsecureSkipVerify: true}
+		if certFile != "" {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			assert.Nil(t, err)
+			config.Certificates = []tls.Certificate{cert}
+		}
+		client := &fasthttp.Client{TLSConfig: config}
+		code, body, err := client.Get(nil, url+path)
+		assert.Nil(t, err)
+		return code, string(body)
+	}
+
+	code, body := get(billingCert, billingKey, "/internal")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "billing", body)
+	code, _ = get(reportsCert, reportsKey, "/internal")
+	assert.Equal(t, 403, code)
+	code, _ = get("", "", "/internal")
+	assert.Equal(t, 401, code)
+	code, body = get("", "", "/")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "public", body)
+
+	// the certificates of the file are added to a copy of the configured pool
+	assert.Len(t, pool.Subjects(), 0)
+
+	c := newTestContext("/")
+	assert.Nil(t, c.TLSClientCert())
+}
@@ -0,0 +1,156 @@
+package tokay
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// clientWatchInterval is the period of checking the client connection for the Done channel.
+const clientWatchInterval = 100 * time.Millisecond
+
+// Context implements context.Context, so it can be passed to the standard library and the database drivers.
+var _ context.Context = (*Context)(nil)
+
+// doneSignal is the Done channel of the request closed with the cancellation reason.
+type doneSignal struct {
+	ch   chan struct{}
+	once sync.Once
+	err  error
+}
+
+// close closes the channel with the reason, the first reason wins.
+func (s *doneSignal) close(err error) {
+	s.once.Do(func() {
+		s.err = err
+		close(s.ch)
+	})
+}
+
+// closedWith reports whether the channel is closed with the reason.
+func (s *doneSignal) closedWith(err error) bool {
+	select {
+	case <-s.ch:
+		return s.err == err
+	default:
+		return false
+	}
+}
+
+// Done returns the channel closed when the client disconnects (the connection is closed by the client
+// or reset), the server shuts down or the deadline of the Timeout middleware passes, so the long-running
+// handlers can stop the work nobody waits for.
+// The connection is checked every 100 milliseconds after the first Done call until the handlers return.
+// The clients half-closing the connection after the request are treated as gone. Done overrides
+// the fasthttp.RequestCtx one, which is closed on the server shutdown only.
+//
+//	for _, row := range rows {
+//		select {
+//		case <-c.Done():
+//			return
+//		default:
+//		}
+//		process(row)
+//	}
+func (c *Context) Done() <-chan struct{} {
+	signal := c.doneSignal()
+	if c.stopWatcher == nil {
+		c.stopWatcher = make(chan struct{})
+		var serverDone <-chan struct{}
+		conn := c.Conn()
+		if conn != nil {
+			serverDone = c.RequestCtx.Done()
+		}
+		go watchClient(conn, serverDone, signal, c.stopWatcher)
+	}
+	return signal.ch
+}
+
+// doneSignal returns the Done channel of the request without starting the client connection watcher.
+func (c *Context) doneSignal() *doneSignal {
+	if c.done == nil {
+		c.done = &doneSignal{ch: make(chan struct{})}
+	}
+	return c.done
+}
+
+// Err returns context.Canceled after Done is closed by the client disconnection or the server shutdown,
+// context.DeadlineExceeded after the deadline of the Timeout middleware, nil otherwise.
+func (c *Context) Err() error {
+	select {
+	case <-c.Done():
+		return c.done.err
+	default:
+		return nil
+	}
+}
+
+// Deadline returns the time when the work done for the request should be canceled (see Timeout).
+// The ok is false when no deadline is set.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	return c.deadline, !c.deadline.IsZero()
+}
+
+// Value returns the context data item (see Set) for the string key or the user value of fasthttp.RequestCtx.
+func (c *Context) Value(key interface{}) interface{} {
+	if name, ok := key.(string); ok {
+		if value, ok := c.GetEx(name); ok {
+			return value
+		}
+	}
+	return c.RequestCtx.Value(key)
+}
+
+// IsClientGone checks whether the client has closed the connection.
+func (c *Context) IsClientGone() bool {
+	if c.done != nil && c.done.closedWith(context.Canceled) {
+		return true
+	}
+	return connClosed(c.Conn())
+}
+
+// stopWatching stops the client connection watcher started by Done.
+func (c *Context) stopWatching() {
+	if c.stopWatcher != nil {
+		close(c.stopWatcher)
+		c.stopWatcher = nil
+	}
+}
+
+// watchClient closes the done signal when the client connection is closed or the server shuts down.
+func watchClient(conn net.Conn, serverDone <-chan struct{}, done *doneSignal, stop chan struct{}) {
+	if conn == nil && serverDone == nil {
+		return
+	}
+	ticker := time.NewTicker(clientWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-serverDone:
+			done.close(context.Canceled)
+			return
+		case <-ticker.C:
+			if connClosed(conn) {
+				done.close(context.Canceled)
+				return
+			}
+		}
+	}
+}
+
+// rawConn returns the TCP (or Unix) connection under the TLS and the graceful connection wrappers.
+func rawConn(conn net.Conn) net.Conn {
+	for {
+		switch c := conn.(type) {
+		case *gracefulConn:
+			conn = c.Conn
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return conn
+		}
+	}
+}
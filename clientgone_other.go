@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package tokay
+
+import "net"
+
+// connClosed is not supported on the platform: the client is never detected as gone.
+func connClosed(conn net.Conn) bool {
+	return false
+}
@@ -0,0 +1,50 @@
+package tokay
+
+import (
+	"bufio"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContextDone(t *testing.T) {
+	engine := New(&Config{Logger: &testLogger{}})
+	result := make(chan string, 1)
+	engine.GET("/report", func(c *Context) {
+		select {
+		case <-c.Done():
+			result <- fmt.Sprintf("gone %v %v", c.IsClientGone(), c.Err())
+		case <-time.After(5 * time.Second):
+			result <- "timeout"
+		}
+	})
+	engine.GET("/ok", func(c *Context) {
+		c.String(200, fmt.Sprint(c.IsClientGone(), c.Err()))
+	})
+	conn, err := runTestServer(engine)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer engine.Close()
+
+	fmt.Fprint(conn, "GET /ok HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	var resp fasthttp.Response
+	assert.Nil(t, resp.Read(bufio.NewReader(conn)))
+	assert.Equal(t, "false <nil>", string(resp.Body()))
+
+	fmt.Fprint(conn, "GET /report HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	time.Sleep(50 * time.Millisecond)
+	conn.Close()
+	select {
+	case r := <-result:
+		assert.Equal(t, "gone true context canceled", r)
+	case <-time.After(6 * time.Second):
+		t.Fatal("handler is not finished")
+	}
+
+	ctx := serveTestRequest(engine, "/ok")
+	assert.Equal(t, "false <nil>", string(ctx.Response.Body()))
+}
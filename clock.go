@@ -0,0 +1,55 @@
+package tokay
+
+import (
+	"math/rand"
+	"time"
+)
+
+// globalRandSource is the rand.Source of the math/rand top-level functions, safe for concurrent use.
+type globalRandSource struct{}
+
+func (globalRandSource) Int63() int64   { return rand.Int63() }
+func (globalRandSource) Uint64() uint64 { return rand.Uint64() }
+func (globalRandSource) Seed(int64)     {}
+
+// SetClock replaces the time source of Context.Now (time.Now by default),
+// for example with a fixed time in tests.
+//
+//	engine.SetClock(func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) })
+func (engine *Engine) SetClock(now func() time.Time) {
+	engine.clock = now
+}
+
+// SetRandSource replaces the random source of Context.Rand (the math/rand top-level source by default).
+// The function is called once per request using Context.Rand, for example with a fixed seed in tests.
+//
+//	engine.SetRandSource(func() rand.Source { return rand.NewSource(1) })
+func (engine *Engine) SetRandSource(source func() rand.Source) {
+	engine.randSource = source
+}
+
+// Now returns the request time: the time of the engine clock (see Engine.SetClock) taken on the first call,
+// so the logs, cookies and tokens of the request share a single timestamp.
+func (c *Context) Now() time.Time {
+	if c.now.IsZero() {
+		if c.engine.clock != nil {
+			c.now = c.engine.clock()
+		} else {
+			c.now = time.Now()
+		}
+	}
+	return c.now
+}
+
+// Rand returns the random numbers generator of the request backed by the engine random source
+// (see Engine.SetRandSource). It must not be used by several goroutines at once.
+func (c *Context) Rand() *rand.Rand {
+	if c.rand == nil {
+		if c.engine.randSource != nil {
+			c.rand = rand.New(c.engine.randSource())
+		} else {
+			c.rand = rand.New(globalRandSource{})
+		}
+	}
+	return c.rand
+}
@@ -0,0 +1,38 @@
+package tokay
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextNowAndRand(t *testing.T) {
+	engine := New()
+	fixed := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	ticks := 0
+	engine.SetClock(func() time.Time {
+		ticks++
+		return fixed.Add(time.Duration(ticks) * time.Second)
+	})
+	engine.SetRandSource(func() rand.Source { return rand.NewSource(1) })
+	engine.Use(func(c *Context) {
+		c.Header("X-Time", c.Now().Format(time.RFC3339))
+	})
+	engine.GET("/", func(c *Context) {
+		c.String(200, c.Now().Format(time.RFC3339)+" "+strconv.Itoa(c.Rand().Intn(1000000))+" "+strconv.Itoa(c.Rand().Intn(1000000)))
+	})
+
+	ctx := serveTestRequest(engine, "/")
+	assert.Equal(t, "2024-01-02T03:04:06Z", string(ctx.Response.Header.Peek("X-Time")))
+	first := string(ctx.Response.Body())
+	ctx = serveTestRequest(engine, "/")
+	assert.Equal(t, "2024-01-02T03:04:07Z", string(ctx.Response.Header.Peek("X-Time")))
+	assert.Equal(t, first[len("2024-01-02T03:04:06Z"):], string(ctx.Response.Body())[len("2024-01-02T03:04:07Z"):])
+
+	c := &Context{engine: New()}
+	assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+	assert.True(t, c.Rand().Float64() < 1)
+}
@@ -0,0 +1,43 @@
+package tokay
+
+import (
+	"github.com/valyala/fasthttp"
+)
+
+var noopRequestHandler = func(*fasthttp.RequestCtx) {}
+
+// Compress returns a middleware that transparently compresses response body
+// with brotli, gzip or deflate depending on the request 'Accept-Encoding' header.
+// Responses marked by NoCompress (or c.DisableCompression()) are sent as is, the others get
+// the "Vary: Accept-Encoding" header.
+// The optional levels are brotli level and gzip/deflate level.
+//
+//	engine.Use(tokay.Compress())
+//	engine.Use(tokay.Compress(fasthttp.CompressBrotliBestSpeed, fasthttp.CompressBestSpeed))
+func Compress(levels ...int) Handler {
+	levels = append(levels, fasthttp.CompressBrotliDefaultCompression, fasthttp.CompressDefaultCompression)
+	if len(levels) == 3 {
+		levels[1] = levels[2]
+	}
+	compress := fasthttp.CompressHandlerBrotliLevel(noopRequestHandler, levels[0], levels[1])
+	return func(c *Context) {
+		c.Next()
+		if !c.noCompress && len(c.Response.Header.Peek("Content-Encoding")) == 0 {
+			c.Response.Header.Add("Vary", "Accept-Encoding")
+			compress(c.RequestCtx)
+		}
+	}
+}
+
+// NoCompress is a route middleware that marks the response as non-compressible
+// (for example, already compressed images, video or archives).
+//
+//	engine.GET("/media/<id>", tokay.NoCompress, mediaHandler)
+func NoCompress(c *Context) {
+	c.DisableCompression()
+}
+
+// DisableCompression marks the current response as non-compressible for the Compress middleware.
+func (c *Context) DisableCompression() {
+	c.noCompress = true
+}
@@ -0,0 +1,83 @@
+package tokay
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("compressible text ", 100)
+	engine := New()
+	engine.Use(Compress())
+	engine.GET("/text", func(c *Context) {
+		c.String(200, body)
+	})
+	engine.GET("/media", NoCompress, func(c *Context) {
+		c.String(200, body)
+	})
+	engine.GET("/archive", func(c *Context) {
+		c.DisableCompression()
+		c.String(200, body)
+	})
+
+	ctx := serveTestRequest(engine, "/text", withHeader("Accept-Encoding", "gzip"))
+	assert.Equal(t, "gzip", string(ctx.Response.Header.Peek("Content-Encoding")))
+	assert.Equal(t, "Accept-Encoding", string(ctx.Response.Header.Peek("Vary")))
+	unzipped, err := ctx.Response.BodyGunzip()
+	assert.Nil(t, err)
+	assert.Equal(t, body, string(unzipped))
+
+	ctx = serveTestRequest(engine, "/text", withHeader("Accept-Encoding", "br, gzip"))
+	assert.Equal(t, "br", string(ctx.Response.Header.Peek("Content-Encoding")))
+
+	ctx = serveTestRequest(engine, "/text")
+	assert.Empty(t, ctx.Response.Header.Peek("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", string(ctx.Response.Header.Peek("Vary")))
+	assert.Equal(t, body, string(ctx.Response.Body()))
+
+	for _, uri := range []string{"/media", "/archive"} {
+		ctx = serveTestRequest(engine, uri, withHeader("Accept-Encoding", "br, gzip"))
+		assert.Empty(t, ctx.Response.Header.Peek("Content-Encoding"), uri)
+		assert.Empty(t, ctx.Response.Header.Peek("Vary"), uri)
+		assert.Equal(t, body, string(ctx.Response.Body()), uri)
+	}
+}
+
+func TestStaticPrecompressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"app.js":    "plain",
+		"app.js.gz": "gzipped",
+		"app.js.br": "brotli",
+	}
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+
+	engine := New()
+	engine.Use(Compress())
+	engine.StaticWithConfig("/static", dir, StaticConfig{Precompressed: true})
+
+	tests := []struct {
+		acceptEncoding, contentEncoding, body string
+	}{
+		{"br, gzip", "br", "brotli"},
+		{"gzip", "gzip", "gzipped"},
+		{"", "", "plain"},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, "/static/app.js", withHeader("Accept-Encoding", test.acceptEncoding))
+		assert.Equal(t, 200, ctx.Response.StatusCode(), test.acceptEncoding)
+		assert.Equal(t, test.contentEncoding, string(ctx.Response.Header.Peek("Content-Encoding")), test.acceptEncoding)
+		assert.Equal(t, "Accept-Encoding", string(ctx.Response.Header.Peek("Vary")), test.acceptEncoding)
+		assert.Equal(t, test.body, string(ctx.Response.Body()), test.acceptEncoding)
+		assert.Contains(t, string(ctx.Response.Header.ContentType()), "javascript", test.acceptEncoding)
+	}
+}
@@ -0,0 +1,204 @@
+package tokay
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/night-codes/go-json"
+	"gopkg.in/yaml.v3"
+)
+
+// configSetters maps configuration keys (as used in config files) to the Config fields.
+// Environment variable names are built from the same keys: PREFIX_READ_TIMEOUT etc.
+var configSetters = map[string]func(cfg *Config, value string) error{
+	"debug": func(cfg *Config, value string) (err error) {
+		cfg.Debug, err = strconv.ParseBool(value)
+		return
+	},
+	"hide_banner": func(cfg *Config, value string) (err error) {
+		cfg.HideBanner, err = strconv.ParseBool(value)
+		return
+	},
+	"env": func(cfg *Config, value string) error {
+		cfg.Env = value
+		return nil
+	},
+	"server_header": func(cfg *Config, value string) error {
+		cfg.ServerHeader = value
+		return nil
+	},
+	"addr": func(cfg *Config, value string) error {
+		cfg.Addr = value
+		return nil
+	},
+	"cert_file": func(cfg *Config, value string) error {
+		cfg.CertFile = value
+		return nil
+	},
+	"key_file": func(cfg *Config, value string) error {
+		cfg.KeyFile = value
+		return nil
+	},
+	"tls_reload_interval": func(cfg *Config, value string) (err error) {
+		cfg.TLSReloadInterval, err = parseDuration(value)
+		return
+	},
+	"client_ca_file": func(cfg *Config, value string) error {
+		cfg.ClientCAFile = value
+		return nil
+	},
+	"client_auth": func(cfg *Config, value string) error {
+		clientAuth, ok := clientAuthTypes[strings.ToLower(value)]
+		if !ok {
+			return fmt.Errorf("unknown client auth type %q", value)
+		}
+		cfg.ClientAuth = clientAuth
+		return nil
+	},
+	"read_timeout": func(cfg *Config, value string) (err error) {
+		cfg.ReadTimeout, err = parseDuration(value)
+		return
+	},
+	"write_timeout": func(cfg *Config, value string) (err error) {
+		cfg.WriteTimeout, err = parseDuration(value)
+		return
+	},
+	"idle_timeout": func(cfg *Config, value string) (err error) {
+		cfg.IdleTimeout, err = parseDuration(value)
+		return
+	},
+	"max_graceful_wait_time": func(cfg *Config, value string) (err error) {
+		cfg.MaxGracefulWaitTime, err = parseDuration(value)
+		return
+	},
+	"graceful_timeout": func(cfg *Config, value string) (err error) {
+		cfg.GracefulTimeout, err = parseDuration(value)
+		return
+	},
+	"max_request_body_size": func(cfg *Config, value string) (err error) {
+		cfg.MaxRequestBodySize, err = strconv.Atoi(value)
+		return
+	},
+	"templates_dirs": func(cfg *Config, value string) error {
+		cfg.TemplatesDirs = splitList(value)
+		return nil
+	},
+	"templates_extensions": func(cfg *Config, value string) error {
+		cfg.TemplatesExtensions = splitList(value)
+		return nil
+	},
+	"left_template_delimiter": func(cfg *Config, value string) error {
+		cfg.LeftTemplateDelimiter = value
+		return nil
+	},
+	"right_template_delimiter": func(cfg *Config, value string) error {
+		cfg.RightTemplateDelimiter = value
+		return nil
+	},
+}
+
+// clientAuthTypes are names of the "client_auth" config values.
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// ConfigFromEnv creates a Config from environment variables with the given prefix.
+// For example, with prefix "APP": APP_ADDR=":8080", APP_DEBUG=true, APP_READ_TIMEOUT=5s,
+// APP_CERT_FILE, APP_KEY_FILE, APP_TEMPLATES_DIRS="views,layouts" (comma separated lists).
+func ConfigFromEnv(prefix string) (*Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+	cfg := &Config{}
+	for key, set := range configSetters {
+		name := prefix + strings.ToUpper(key)
+		if value, ok := os.LookupEnv(name); ok {
+			if err := set(cfg, value); err != nil {
+				return nil, fmt.Errorf("tokay: invalid %s value: %v", name, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// ConfigFromFile creates a Config from JSON, YAML or TOML file (selected by file extension).
+// Keys are the same as ConfigFromEnv uses, in lower case: "addr", "debug", "read_timeout" etc.
+// Durations are strings like "5s" or integer number of seconds.
+func ConfigFromFile(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("tokay: unsupported config file format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	for key, value := range values {
+		key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+		set, ok := configSetters[key]
+		if !ok {
+			return nil, fmt.Errorf("tokay: unknown config key %q in %s", key, path)
+		}
+		if err := set(cfg, configValue(value)); err != nil {
+			return nil, fmt.Errorf("tokay: invalid %s value: %v", key, err)
+		}
+	}
+	return cfg, nil
+}
+
+// configValue converts decoded config file value into the string form used by configSetters.
+func configValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i := range v {
+			items[i] = configValue(v[i])
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// parseDuration parses duration string ("1m30s") or integer number of seconds.
+func parseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
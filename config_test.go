@@ -0,0 +1,65 @@
+package tokay
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	os.Setenv("TOKAYTEST_ADDR", ":8081")
+	os.Setenv("TOKAYTEST_DEBUG", "true")
+	os.Setenv("TOKAYTEST_READ_TIMEOUT", "5s")
+	os.Setenv("TOKAYTEST_TEMPLATES_DIRS", "views, layouts")
+	defer func() {
+		os.Unsetenv("TOKAYTEST_ADDR")
+		os.Unsetenv("TOKAYTEST_DEBUG")
+		os.Unsetenv("TOKAYTEST_READ_TIMEOUT")
+		os.Unsetenv("TOKAYTEST_TEMPLATES_DIRS")
+	}()
+
+	cfg, err := ConfigFromEnv("TOKAYTEST")
+	assert.Nil(t, err)
+	assert.Equal(t, ":8081", cfg.Addr)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 5*time.Second, cfg.ReadTimeout)
+	assert.Equal(t, []string{"views", "layouts"}, cfg.TemplatesDirs)
+
+	os.Setenv("TOKAYTEST_DEBUG", "maybe")
+	_, err = ConfigFromEnv("TOKAYTEST_")
+	assert.NotNil(t, err)
+}
+
+func TestConfigFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"app.json": `{"addr": ":9000", "debug": true, "write_timeout": 3, "max_request_body_size": 10485760, "templates_dirs": ["views"]}`,
+		"app.yaml": "addr: \":9000\"\ndebug: true\nwrite_timeout: 3s\ntemplates_dirs:\n  - views\n",
+		"app.toml": "addr = \":9000\"\ndebug = true\nwrite_timeout = \"3s\"\ntemplates_dirs = [\"views\"]\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0644))
+		cfg, err := ConfigFromFile(path)
+		assert.Nil(t, err, name)
+		assert.Equal(t, ":9000", cfg.Addr, name)
+		assert.True(t, cfg.Debug, name)
+		assert.Equal(t, 3*time.Second, cfg.WriteTimeout, name)
+		assert.Equal(t, []string{"views"}, cfg.TemplatesDirs, name)
+	}
+
+	path := filepath.Join(dir, "app.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"unknown": 1}`), 0644))
+	_, err = ConfigFromFile(path)
+	assert.NotNil(t, err)
+
+	_, err = ConfigFromFile(filepath.Join(dir, "app.ini"))
+	assert.NotNil(t, err)
+}
@@ -1,13 +1,15 @@
 package tokay
 
 import (
-	"encoding/xml"
+	"errors"
 	"fmt"
+	"math/rand"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/night-codes/go-json"
@@ -24,14 +26,33 @@ type Context struct {
 	*fasthttp.RequestCtx
 	Serialize SerializeFunc // the function serializing the given data of arbitrary type into a byte array.
 
-	engine   *Engine
-	aborted  bool
-	pnames   []string        // list of route parameter names
-	pvalues  []string        // list of parameter values corresponding to pnames
-	data     *dataMap        // data items managed by Get and Set
-	index    int             // the index of the currently executing handler in handlers
-	handlers []Handler       // the handlers associated with the current route
-	WSConn   *websocket.Conn // websocket connection
+	engine       *Engine
+	aborted      bool
+	abortReason  string          // the machine-readable abort reason (see AbortWithReason)
+	reroutes     int             // the number of the internal dispatches (see ReRoute)
+	now          time.Time       // the request time returned by Now()
+	rand         *rand.Rand      // the request random numbers generator returned by Rand()
+	noCompress   bool            // response must not be compressed by Compress middleware
+	originalPath string          // the request path before the prefixes were stripped (see OriginalPath)
+	route        *Route          // the matched route, nil if no route matches the request
+	matchTrace   []MatchStep     // the route matching decisions (see MatchTrace)
+	logger       FieldLogger     // the request logger returned by Logger()
+	pnames       []string        // list of route parameter names
+	pvalues      []string        // list of parameter values corresponding to pnames
+	data         *dataMap        // data items managed by Get and Set
+	dataGen      uint64          // the generation of data acquired for the request (see items)
+	released     string          // the request of the context poisoned at its end in the Debug mode (see assertActive)
+	retained     bool            // the context is used after the request end and must not be reused (see Websocket)
+	index        int             // the index of the currently executing handler in handlers
+	handlers     []Handler       // the handlers associated with the current route
+	WSConn       *websocket.Conn // websocket connection
+
+	decodedBody []byte         // the request body converted to UTF-8 by Body()
+	decodedArgs *fasthttp.Args // the POST arguments converted to UTF-8 by PostArgs()
+
+	done        *doneSignal   // closed when the client disconnects or the deadline passes (see Done)
+	deadline    time.Time     // the deadline set by the Timeout middleware (see Deadline)
+	stopWatcher chan struct{} // stops the client connection watcher at the request end
 }
 
 // Engine returns the Engine that is handling the incoming HTTP request.
@@ -39,13 +60,21 @@ func (c *Context) Engine() *Engine {
 	return c.engine
 }
 
+// Route returns the route matching the current request.
+// Nil is returned if the request is handled by the NotFound handlers.
+func (c *Context) Route() *Route {
+	return c.route
+}
+
 // SetContentType sets response Content-Type.
 func (c *Context) SetContentType(contentType string) {
+	c.assertActive()
 	c.RequestCtx.SetContentType(contentType)
 }
 
 // SetStatusCode sets response status code.
 func (c *Context) SetStatusCode(statusCode int) {
+	c.assertActive()
 	c.RequestCtx.SetStatusCode(statusCode)
 }
 
@@ -83,8 +112,14 @@ func (c *Context) RemoveCookie(name string) {
 }
 
 // File sends local file contents from the given path as response body.
+// The Content-Type is set by the file extension (see Engine.AddMIMEType).
+// The requests for multiple byte ranges get the multipart/byteranges response.
 func (c *Context) File(filepath string) {
+	if c.serveByteRanges(filepath) {
+		return
+	}
 	c.SendFile(filepath)
+	c.setFileContentType(filepath)
 }
 
 // Websocket upgrades the HTTP server connection to the WebSocket protocol.
@@ -99,7 +134,12 @@ func (c *Context) Websocket(fn func(), bufferSizes ...int) error {
 		bufferSizes = append(bufferSizes, bufferSizes[0])
 	}
 
+	engine := c.engine
+	// the connection handler runs after the end of the request
+	c.retained = true
 	return websocket.Upgrade(c.RequestCtx, func(conn *websocket.Conn) {
+		engine.websockets.add(conn)
+		defer engine.websockets.remove(conn)
 		c.WSConn = conn
 		fn()
 	}, bufferSizes[0], bufferSizes[1])
@@ -155,13 +195,17 @@ func (c *Context) ClientIP() string {
 }
 
 // Redirect returns a HTTP redirect to the specific location.
+// The absolute paths are redirected to the scheme and the host requested by the client
+// when the request is forwarded by a trusted proxy (see Engine.TrustProxies).
 func (c *Context) Redirect(statusCode int, uri string) {
-	c.RequestCtx.Redirect(uri, statusCode)
+	c.assertActive()
+	c.RequestCtx.Redirect(c.redirectLocation(uri), statusCode)
 }
 
 // Param returns the named parameter value that is found in the URL path matching the current route.
 // If the named parameter cannot be found, an empty string will be returned.
 func (c *Context) Param(name string) string {
+	c.assertActive()
 	for i, n := range c.pnames {
 		if n == name {
 			return c.pvalues[i]
@@ -199,20 +243,51 @@ func (c *Context) ParamBool(name string) bool {
 }
 
 // Copy context (instance will be contain copies of Request and Response)
+// Data items are shared with the original context, use Snapshot for a detached copy of the request data.
+// Unlike the original context, the copy can be used after the end of the request (for example in goroutines).
 func (c *Context) Copy() *Context {
+	data := c.items()
+	data.Lock()
+	data.shared = true
+	data.Unlock()
 	ret := *c
 	ret.init(&fasthttp.RequestCtx{})
+	releaseDataMap(ret.data)
 	c.Request.CopyTo(&ret.Request)
 	c.Response.CopyTo(&ret.Response)
 	ret.WSConn = c.WSConn
-	ret.data = c.data
+	ret.data, ret.dataGen = data, c.dataGen
+	ret.pvalues = append([]string(nil), c.pvalues...) // pvalues slice is reused by the pooled context
 	return &ret
 }
 
+// items returns the context data, it panics if the context is used after the end of its request.
+func (c *Context) items() *dataMap {
+	c.assertActive()
+	if atomic.LoadUint64(&c.data.gen) != c.dataGen {
+		panic(errContextReleased)
+	}
+	return c.data
+}
+
+// assertActive panics if the context is poisoned at the end of its request (see Engine.releaseContext).
+func (c *Context) assertActive() {
+	if c.released != "" {
+		panic(errContextReleased + ": the context of " + c.released + " is captured by a goroutine or a callback")
+	}
+}
+
+// poison detaches the context from its request, so the Context methods panic on the further use.
+func (c *Context) poison() {
+	c.released = c.Method() + " " + c.OriginalPath()
+	c.RequestCtx = nil
+	c.handlers, c.route = nil, nil
+}
+
 // Get returns the named data item previously registered with the context by calling Set.
 // If the named data item cannot be found, nil will be returned.
 func (c *Context) Get(name string) (value interface{}) {
-	return c.data.Get(name)
+	return c.items().Get(name)
 }
 
 // MultipartForm is the parsed multipart form, including file uploads.
@@ -222,6 +297,7 @@ func (c *Context) MultipartForm() (*multipart.Form, error) {
 
 // GetHeader returns value from request headers.
 func (c *Context) GetHeader(key string) string {
+	c.assertActive()
 	return string(c.Request.Header.Peek(key))
 }
 
@@ -229,6 +305,7 @@ func (c *Context) GetHeader(key string) string {
 // It writes a header in the response. If value == "", this method removes the header
 // `c.Response.Header.Del(key)`
 func (c *Context) Header(key, value string) {
+	c.assertActive()
 	if len(value) == 0 {
 		c.Response.Header.Del(key)
 	} else {
@@ -238,17 +315,17 @@ func (c *Context) Header(key, value string) {
 
 // GetEx returns the named data item and info about data item exists.
 func (c *Context) GetEx(name string) (value interface{}, ok bool) {
-	return c.data.GetEx(name)
+	return c.items().GetEx(name)
 }
 
 // Set stores the named data item in the context so that it can be retrieved later.
 func (c *Context) Set(name string, value interface{}) {
-	c.data.Set(name, value)
+	c.items().Set(name, value)
 }
 
 // Unset the named data item in the context.
 func (c *Context) Unset(name string) {
-	c.data.Delete(name)
+	c.items().Delete(name)
 }
 
 // Next calls the rest of the handlers associated with the current route.
@@ -256,22 +333,68 @@ func (c *Context) Unset(name string) {
 // Next is normally used when a handler needs to do some postprocessing after the rest of the handlers
 // are executed.
 func (c *Context) Next() {
+	c.assertActive()
 	c.index++
 	for n := len(c.handlers); c.index < n; c.index++ {
 		c.handlers[c.index](c)
 	}
 }
 
+// maxReRoutes is the maximal number of the internal dispatches of a request.
+const maxReRoutes = 10
+
+// ReRoute dispatches the request internally to the route of the method and path (with the optional query),
+// like the request was sent by the client: the handlers of the new route (including the engine and group
+// middlewares) are called and the rest of the current handlers are skipped. The response is kept, so
+// the error pages can use its status. The host checks and the redirects are not applied again.
+//
+//	engine.GET("/old-catalog/<id>", func(c *tokay.Context) {
+//		c.ReRoute("GET", "/products/"+c.Param("id"))
+//	})
+func (c *Context) ReRoute(method, path string) {
+	if c.reroutes >= maxReRoutes {
+		c.AbortWithError(http.StatusInternalServerError, errors.New("too many internal dispatches"))
+		return
+	}
+	c.reroutes++
+	c.originalPath = ""
+	c.Request.Header.SetMethod(method)
+	if strings.IndexByte(path, '?') >= 0 {
+		c.Request.SetRequestURI(path)
+	} else {
+		c.Request.URI().SetPath(path)
+	}
+	c.aborted = false
+	c.logger = nil
+	c.engine.match(c)
+	c.index = -1
+	c.Next()
+	// the handlers loops of the previous chain must stop
+	c.index = 1 << 30
+}
+
 // Error sets response status code to the given value and sets response body to the given message.
 func (c *Context) Error(msg string, statusCode int) {
+	c.assertActive()
 	c.RequestCtx.Error(msg, statusCode)
 }
 
 // Abort skips the rest of the handlers associated with the current route.
 // Abort is normally used when a handler handles the request normally and wants to skip the rest of the handlers.
 // If a handler wants to indicate an error condition, it should simply return the error without calling Abort.
+// The OnAbort hooks of the engine are called.
 func (c *Context) Abort() {
+	c.skipHandlers()
 	c.aborted = true
+	for _, fn := range c.engine.onAbort {
+		fn(c)
+	}
+}
+
+// skipHandlers skips the rest of the handlers without aborting the context, for the requests
+// answered by the router itself and the cached responses.
+func (c *Context) skipHandlers() {
+	c.assertActive()
 	c.index = len(c.handlers)
 }
 
@@ -294,7 +417,21 @@ func (c *Context) AbortWithError(statusCode int, err error) {
 	c.Abort()
 }
 
-// IsAborted returns true if the current context was aborted.
+// AbortWithReason calls `AbortWithStatus()` and stores the machine-readable reason of the abort
+// for the observability middlewares and the OnAbort hooks (see AbortReason).
+//
+//	c.AbortWithReason(429, "quota_exceeded")
+func (c *Context) AbortWithReason(statusCode int, reason string) {
+	c.abortReason = reason
+	c.AbortWithStatus(statusCode)
+}
+
+// AbortReason returns the reason given to AbortWithReason or an empty string.
+func (c *Context) AbortReason() string {
+	return c.abortReason
+}
+
+// IsAborted returns true if the current context was aborted by a handler (see Abort).
 func (c *Context) IsAborted() bool {
 	return c.aborted
 }
@@ -303,10 +440,11 @@ func (c *Context) IsAborted() bool {
 // The parameters should be given in the sequence of name1, value1, name2, value2, and so on.
 // If a parameter in the route is not provided a value, the parameter token will remain in the resulting URL.
 // Parameter values will be properly URL encoded.
+// The URL includes the X-Forwarded-Prefix path of a trusted proxy (see Engine.TrustProxies).
 // The method returns an empty string if the URL creation fails.
 func (c *Context) URL(route string, pairs ...interface{}) string {
 	if r := c.engine.routes[route]; r != nil {
-		return r.URL(pairs...)
+		return c.forwardedPrefix() + r.URL(pairs...)
 	}
 	return ""
 }
@@ -325,8 +463,29 @@ func (c *Context) WriteData(data interface{}) (err error) {
 // init sets the request and response of the context and resets all other properties.
 func (c *Context) init(ctx *fasthttp.RequestCtx) {
 	c.RequestCtx = ctx
-	c.data = newDataMap()
+	c.data, c.dataGen = acquireDataMap()
+	if c.engine != nil && len(c.pvalues) < c.engine.maxParams {
+		// the routes with more parameters are added after the context was created
+		c.pvalues = make([]string, c.engine.maxParams)
+	}
 	c.index = -1
+	c.retained = false
+	c.released = ""
+	c.aborted = false
+	c.abortReason = ""
+	c.reroutes = 0
+	c.now = time.Time{}
+	c.rand = nil
+	c.done = nil
+	c.deadline = time.Time{}
+	c.stopWatcher = nil
+	c.route = nil
+	c.matchTrace = nil
+	c.originalPath = ""
+	c.logger = nil
+	c.noCompress = false
+	c.decodedBody = nil
+	c.decodedArgs = nil
 	c.Serialize = Serialize
 }
 
@@ -358,29 +517,35 @@ func Serialize(data interface{}) (bytes []byte, err error) {
 // JSON serializes the given struct as JSON into the response body.
 // It also sets the Content-Type as "application/json".
 func (c *Context) JSON(statusCode int, obj interface{}) {
+	c.assertActive()
 	c.engine.Render.JSON(c.RequestCtx, statusCode, obj)
 }
 
 // JSONP marshals the given interface object and writes the JSON response.
 func (c *Context) JSONP(statusCode int, callbackName string, obj interface{}) {
+	c.assertActive()
 	c.engine.Render.JSONP(c.RequestCtx, statusCode, callbackName, obj)
 }
 
 // HTML renders the HTTP template specified by its file name.
 // It also updates the HTTP code and sets the Content-Type as "text/html".
+// The device variant of the template is rendered if it exists (see Engine.TemplateVariants).
 func (c *Context) HTML(statusCode int, name string, obj interface{}) {
-	c.engine.Render.HTML(c.RequestCtx, statusCode, name, obj)
+	c.assertActive()
+	c.engine.Render.HTML(c.RequestCtx, statusCode, c.templateVariant(name), obj)
 }
 
 // XML serializes the given struct as XML into the response body.
 // It also sets the Content-Type as "application/xml".
 func (c *Context) XML(statusCode int, obj interface{}) {
+	c.assertActive()
 	c.engine.Render.XML(c.RequestCtx, statusCode, obj)
 }
 
 // JS renders the JS template specified by its file name.
 // It also updates the HTTP code and sets the Content-Type as "text/javascript".
 func (c *Context) JS(statusCode int, name string, obj interface{}) {
+	c.assertActive()
 	c.engine.Render.JS(c.RequestCtx, statusCode, name, obj)
 }
 
@@ -396,17 +561,12 @@ func (c *Context) String(statusCode int, format string, values ...interface{}) {
 
 // Data writes some data into the body stream and updates the HTTP code.
 func (c *Context) Data(statusCode int, contentType string, data []byte) {
+	c.assertActive()
 	c.SetStatusCode(statusCode)
 	c.SetContentType(contentType)
 	c.Write(data)
 }
 
-// Body returns request body
-// The returned body is valid until the request modification.
-func (c *Context) Body() []byte {
-	return c.Request.Body()
-}
-
 // ContentType returns the Content-Type header of the request.
 func (c *Context) ContentType() string {
 	return filterFlags(c.GetHeader("Content-Type"))
@@ -466,6 +626,7 @@ func (c *Context) PostFormArrayEx(key string) ([]string, bool) {
 // Query returns the keyed url query value if it exists, otherwise it
 // returns an empty string "".
 func (c *Context) Query(key string) string {
+	c.assertActive()
 	return string(c.QueryArgs().Peek(key))
 }
 
@@ -511,6 +672,7 @@ func (c *Context) QueryDefault(key, defaultValue string) string {
 // QueryArray returns a slice of strings for a given query key.
 // The length of the slice depends on the number of params with the given key.
 func (c *Context) QueryArray(key string) []string {
+	c.assertActive()
 	var ret []string
 	retBytes := c.QueryArgs().PeekMulti(key)
 	for k := range retBytes {
@@ -519,9 +681,69 @@ func (c *Context) QueryArray(key string) []string {
 	return ret
 }
 
+// QueryArraySplit returns a slice of strings for a given query key with every value split
+// by the separator, so "?ids=1,2&ids=3" gives ["1", "2", "3"] for the "," separator.
+// Empty elements are skipped.
+func (c *Context) QueryArraySplit(key, sep string) []string {
+	var ret []string
+	for _, value := range c.QueryArgs().PeekMulti(key) {
+		for _, item := range strings.Split(string(value), sep) {
+			if item = strings.TrimSpace(item); item != "" {
+				ret = append(ret, item)
+			}
+		}
+	}
+	return ret
+}
+
+// queryArray returns QueryArray or QueryArraySplit result depending on the optional separator.
+func (c *Context) queryArray(key string, sep []string) []string {
+	if len(sep) != 0 {
+		return c.QueryArraySplit(key, sep[0])
+	}
+	return c.QueryArray(key)
+}
+
+// QueryIntArray returns a slice of integer values for a given query key. The optional separator
+// splits the values like QueryArraySplit ("?ids=1,2,3"). Invalid values are skipped.
+func (c *Context) QueryIntArray(key string, sep ...string) []int {
+	var ret []int
+	for _, value := range c.queryArray(key, sep) {
+		if i, err := strconv.Atoi(value); err == nil {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
+
+// QueryFloat64Array returns a slice of float64 values for a given query key. The optional separator
+// splits the values like QueryArraySplit. Invalid values are skipped.
+func (c *Context) QueryFloat64Array(key string, sep ...string) []float64 {
+	var ret []float64
+	for _, value := range c.queryArray(key, sep) {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
+// QueryBoolArray returns a slice of boolean values for a given query key. The optional separator
+// splits the values like QueryArraySplit. Invalid values are skipped.
+func (c *Context) QueryBoolArray(key string, sep ...string) []bool {
+	var ret []bool
+	for _, value := range c.queryArray(key, sep) {
+		if b, err := strconv.ParseBool(value); err == nil {
+			ret = append(ret, b)
+		}
+	}
+	return ret
+}
+
 // QueryEx is like Query(), it returns the keyed url query value if it exists `(value, true)`
 // (even when the value is an empty string), otherwise it returns `("", false)`.
 func (c *Context) QueryEx(key string) (string, bool) {
+	c.assertActive()
 	args := c.QueryArgs()
 	return string(args.Peek(key)), args.Has(key)
 }
@@ -541,6 +763,53 @@ func (c *Context) QueryArrayEx(key string) ([]string, bool) {
 	return ret, false
 }
 
+// QueryMap returns a map of the query values with keys like "prefix[name]"
+// (for example, "?filter[status]=active&filter[type]=post" with the "filter" prefix
+// returns {"status": "active", "type": "post"}).
+func (c *Context) QueryMap(prefix string) map[string]string {
+	ret := make(map[string]string)
+	c.QueryArgs().VisitAll(func(key, value []byte) {
+		k := string(key)
+		if len(k) > len(prefix)+2 && strings.HasPrefix(k, prefix) && k[len(prefix)] == '[' && k[len(k)-1] == ']' {
+			ret[k[len(prefix)+1:len(k)-1]] = string(value)
+		}
+	})
+	return ret
+}
+
+// QueryValues returns all query values as url.Values.
+func (c *Context) QueryValues() url.Values {
+	ret := url.Values{}
+	c.QueryArgs().VisitAll(func(key, value []byte) {
+		ret.Add(string(key), string(value))
+	})
+	return ret
+}
+
+// WithQuery returns the current request URI with the query parameter set to the value,
+// keeping the order of the other query parameters. Empty value removes the parameter.
+// Handy to build pagination links:
+//
+//	next := c.WithQuery("page", strconv.Itoa(page+1))
+func (c *Context) WithQuery(key, value string) string {
+	uri := c.RequestURI()
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		uri = uri[:i]
+	}
+	args := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(args)
+	c.QueryArgs().CopyTo(args)
+	if value == "" {
+		args.Del(key)
+	} else {
+		args.Set(key, value)
+	}
+	if args.Len() != 0 {
+		uri += "?" + args.String()
+	}
+	return uri
+}
+
 // Referer returns request referer.
 func (c *Context) Referer() string {
 	return string(c.RequestCtx.Referer())
@@ -548,65 +817,107 @@ func (c *Context) Referer() string {
 
 // Method returns request method.
 func (c *Context) Method() string {
-	return string(c.RequestCtx.Method())
+	c.assertActive()
+	return internMethod(c.RequestCtx.Method())
 }
 
 // Path returns requested path.
 func (c *Context) Path() string {
+	c.assertActive()
 	return string(c.RequestCtx.Path())
 }
 
 // Host returns Host header value.
 func (c *Context) Host() string {
+	c.assertActive()
 	return string(c.RequestCtx.Host())
 }
 
 // RequestURI returns RequestURI.
 func (c *Context) RequestURI() string {
+	c.assertActive()
 	return string(c.RequestCtx.RequestURI())
 }
 
-// binding validate
-func validate(err error, obj interface{}) error {
+// validate returns the binding error or validates the bound struct with the engine validator
+// (see Engine.SetValidator), govalidator by default.
+func (c *Context) validate(err error, obj interface{}) error {
 	if err != nil {
 		return err
 	}
+	if c.engine != nil && c.engine.validator != nil {
+		return c.engine.validator(obj)
+	}
 	_, err = govalidator.ValidateStruct(obj)
 	return err
 }
 
-// BindJSON binds the passed struct pointer with JSON request body data
+// BindJSON binds the passed struct pointer with JSON request body data.
+// The decoding errors are returned as *BindError with the error position.
 func (c *Context) BindJSON(obj interface{}) error {
-	return validate(json.Unmarshal(c.Request.Body(), obj), obj)
+	body := c.Body()
+	if err := json.Unmarshal(body, obj); err != nil {
+		return newBindError(body, err)
+	}
+	return c.validate(nil, obj)
 }
 
 // BindXML binds the passed struct pointer with XML request body data
 func (c *Context) BindXML(obj interface{}) error {
-	return validate(xml.Unmarshal(c.Request.Body(), obj), obj)
+	body := c.Body()
+	return c.validate(unmarshalXML(body, c.decodedBody != nil, obj), obj)
 }
 
 // BindPostForm binds the passed struct pointer with form data
 func (c *Context) BindPostForm(obj interface{}) error {
-	return validate(mapArgs(obj, c.PostArgs()), obj)
+	return c.validate(mapArgs(obj, c.PostArgs()), obj)
 }
 
 // BindQuery binds the passed struct pointer with Query data
 func (c *Context) BindQuery(obj interface{}) error {
-	return validate(mapArgs(obj, c.QueryArgs()), obj)
+	return c.validate(mapArgs(obj, c.QueryArgs()), obj)
+}
+
+// BindParams binds the passed struct pointer with the route parameters named by the "param" field tags
+//
+//	type OrderParams struct {
+//		UserID  string `param:"id" valid:"uuid"`
+//		OrderID int    `param:"oid"`
+//	}
+func (c *Context) BindParams(obj interface{}) error {
+	args := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(args)
+	for i, name := range c.pnames {
+		args.Add(name, c.pvalues[i])
+	}
+	return c.validate(mapTaggedArgs(obj, args, "param"), obj)
 }
 
 // Bind checks the Content-Type to select a binding engine automatically,
 // depending the "Content-Type" header different bindings are used.
+// The decoders registered by Engine.RegisterDecoder are checked first.
 func (c *Context) Bind(obj interface{}) error {
+	c.assertActive()
 	if c.Method() == "GET" {
 		return c.BindQuery(obj)
 	}
 
-	switch c.ContentType() {
+	contentType := c.ContentType()
+	if bind, ok := c.engine.binders[strings.ToLower(contentType)]; ok {
+		return c.validate(bind(c, obj), obj)
+	}
+	if decode, ok := c.engine.decoders[strings.ToLower(contentType)]; ok {
+		return c.BindWith(obj, decode)
+	}
+	switch contentType {
 	case "application/json":
 		return c.BindJSON(obj)
 	case "application/xml", "text/xml":
 		return c.BindXML(obj)
+	case "application/cbor":
+		return c.BindCBOR(obj)
+	case "multipart/form-data":
+		return c.BindMultipart(obj)
 	default:
 		return c.BindPostForm(obj)
 	}
@@ -0,0 +1,244 @@
+package tokay
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// testRequestOption sets up the request of serveTestRequest and newTestContext.
+type testRequestOption func(ctx *fasthttp.RequestCtx)
+
+// withMethod sets the request method, GET by default.
+func withMethod(method string) testRequestOption {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.Request.Header.SetMethod(method)
+	}
+}
+
+// withHeader sets the request header.
+func withHeader(key, value string) testRequestOption {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.Request.Header.Set(key, value)
+	}
+}
+
+// withBody sets the request body and its Content-Type (if not empty).
+func withBody(contentType, body string) testRequestOption {
+	return func(ctx *fasthttp.RequestCtx) {
+		if contentType != "" {
+			ctx.Request.Header.SetContentType(contentType)
+		}
+		ctx.Request.SetBodyString(body)
+	}
+}
+
+// withRemoteIP sets the client address.
+func withRemoteIP(ip string) testRequestOption {
+	return func(ctx *fasthttp.RequestCtx) {
+		ctx.SetRemoteAddr(&net.TCPAddr{IP: net.ParseIP(ip), Port: 4000})
+	}
+}
+
+// newTestRequest returns the request to the uri.
+func newTestRequest(uri string, options ...testRequestOption) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.SetRequestURI(uri)
+	for _, option := range options {
+		option(ctx)
+	}
+	return ctx
+}
+
+// serveTestRequest handles the request to the uri (GET unless the options set the method) with the engine.
+func serveTestRequest(engine *Engine, uri string, options ...testRequestOption) *fasthttp.RequestCtx {
+	ctx := newTestRequest(uri, options...)
+	engine.HandleRequest(ctx)
+	return ctx
+}
+
+// newTestContext returns the context of the request to the uri for calling the Context methods
+// directly, without routing.
+func newTestContext(uri string, options ...testRequestOption) *Context {
+	c := &Context{engine: New()}
+	c.init(newTestRequest(uri, options...))
+	return c
+}
+
+func TestContextBindParams(t *testing.T) {
+	type orderParams struct {
+		UserID  string `param:"id" valid:"uuid"`
+		OrderID int    `param:"oid"`
+		Name    string
+	}
+	var params orderParams
+	var err error
+	engine := New()
+	engine.GET("/users/<id>/orders/<oid>", func(c *Context) {
+		params = orderParams{}
+		err = c.BindParams(&params)
+	})
+
+	serveTestRequest(engine, "/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8/orders/42")
+	assert.Nil(t, err)
+	assert.Equal(t, orderParams{UserID: "6ba7b810-9dad-11d1-80b4-00c04fd430c8", OrderID: 42}, params)
+
+	serveTestRequest(engine, "/users/123/orders/42")
+	assert.NotNil(t, err)
+
+	serveTestRequest(engine, "/users/6ba7b810-9dad-11d1-80b4-00c04fd430c8/orders/abc")
+	assert.NotNil(t, err)
+}
+
+func TestContextQueryHelpers(t *testing.T) {
+	var c *Context
+	engine := New()
+	engine.GET("/posts", func(ctx *Context) {
+		c = ctx.Copy()
+	})
+	serveTestRequest(engine, "/posts?filter[status]=active&page=2&filter[type]=post&filter=x&tag=a&tag=b")
+
+	assert.Equal(t, map[string]string{"status": "active", "type": "post"}, c.QueryMap("filter"))
+	assert.Equal(t, map[string]string{}, c.QueryMap("sort"))
+	assert.Equal(t, []string{"a", "b"}, c.QueryValues()["tag"])
+	assert.Equal(t, "2", c.QueryValues().Get("page"))
+	assert.Equal(t, "/posts?filter%5Bstatus%5D=active&page=3&filter%5Btype%5D=post&filter=x&tag=a&tag=b", c.WithQuery("page", "3"))
+	assert.Equal(t, "/posts?filter%5Bstatus%5D=active&filter%5Btype%5D=post&filter=x&tag=a&tag=b", c.WithQuery("page", ""))
+	assert.Equal(t, "/posts?filter%5Bstatus%5D=active&page=2&filter%5Btype%5D=post&filter=x&tag=a&tag=b&sort=name", c.WithQuery("sort", "name"))
+}
+
+func TestContextQueryTypedArrays(t *testing.T) {
+	var c *Context
+	engine := New()
+	engine.GET("/items", func(ctx *Context) {
+		c = ctx.Copy()
+	})
+	serveTestRequest(engine, "/items?id=1&id=x&id=3&ids=4,5,,6&ids=7&price=1.5&price=2&flags=true,0,maybe")
+
+	assert.Equal(t, []int{1, 3}, c.QueryIntArray("id"))
+	assert.Equal(t, []int{4, 5, 6, 7}, c.QueryIntArray("ids", ","))
+	assert.Equal(t, []string{"4", "5", "6", "7"}, c.QueryArraySplit("ids", ","))
+	assert.Equal(t, []float64{1.5, 2}, c.QueryFloat64Array("price"))
+	assert.Equal(t, []bool{true, false}, c.QueryBoolArray("flags", ","))
+	assert.Nil(t, c.QueryIntArray("missing"))
+}
+
+func TestContextAbortWithReason(t *testing.T) {
+	engine := New()
+	var reasons []string
+	engine.OnAbort(func(c *Context) {
+		reasons = append(reasons, c.Path()+" "+c.AbortReason())
+	})
+	var observed string
+	engine.Use(func(c *Context) {
+		c.Next()
+		observed = c.AbortReason()
+	})
+	engine.GET("/quota", func(c *Context) {
+		c.AbortWithReason(429, "quota_exceeded")
+	}, func(c *Context) {
+		c.String(200, "unreachable")
+	})
+	engine.GET("/denied", func(c *Context) {
+		c.AbortWithStatus(403)
+	})
+	engine.GET("/ok", func(c *Context) {})
+	assert.Nil(t, engine.Redirects(map[string]string{"/old": "/ok"}))
+
+	ctx := serveTestRequest(engine, "/quota")
+	assert.Equal(t, 429, ctx.Response.StatusCode())
+	assert.Equal(t, "", string(ctx.Response.Body()))
+	assert.Equal(t, "quota_exceeded", observed)
+
+	serveTestRequest(engine, "/denied")
+	assert.Equal(t, "", observed)
+	serveTestRequest(engine, "/ok")
+
+	// the router answers don't call the hooks
+	ctx = serveTestRequest(engine, "/ok", withMethod("OPTIONS"))
+	assert.Equal(t, "GET, OPTIONS", string(ctx.Response.Header.Peek("Allow")))
+	assert.Equal(t, 405, serveTestRequest(engine, "/ok", withMethod("POST")).Response.StatusCode())
+	assert.Equal(t, 301, serveTestRequest(engine, "/old").Response.StatusCode())
+	assert.Equal(t, []string{"/quota quota_exceeded", "/denied "}, reasons)
+}
+
+func TestContextReRoute(t *testing.T) {
+	engine := New()
+	var trace []string
+	engine.Use(func(c *Context) {
+		trace = append(trace, "mw "+c.Path())
+		c.Next()
+	})
+	engine.GET("/products/<id>", func(c *Context) {
+		c.String(200, "product "+c.Param("id")+" "+c.Query("ref"))
+	})
+	engine.GET("/old/<id>", func(c *Context) {
+		c.ReRoute("GET", "/products/"+c.Param("id")+"?ref=old")
+	}, func(c *Context) {
+		trace = append(trace, "skipped")
+	})
+	engine.POST("/errors/404", func(c *Context) {
+		c.String(c.Response.StatusCode(), "custom not found")
+	})
+	engine.GET("/missing", func(c *Context) {
+		c.SetStatusCode(404)
+		c.ReRoute("POST", "/errors/404")
+	})
+	engine.GET("/loop", func(c *Context) {
+		c.ReRoute("GET", "/loop")
+	})
+
+	ctx := serveTestRequest(engine, "/old/7")
+	assert.Equal(t, "product 7 old", string(ctx.Response.Body()))
+	assert.Equal(t, []string{"mw /old/7", "mw /products/7"}, trace)
+
+	ctx = serveTestRequest(engine, "/missing")
+	assert.Equal(t, 404, ctx.Response.StatusCode())
+	assert.Equal(t, "custom not found", string(ctx.Response.Body()))
+
+	ctx = serveTestRequest(engine, "/loop")
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+}
+
+func TestContextReleasedData(t *testing.T) {
+	engine := New()
+	var retained, copied *Context
+	engine.GET("/", func(c *Context) {
+		c.Set("user", "john")
+		retained = c
+		if c.Query("copy") != "" {
+			copied = c.Copy()
+		}
+	})
+	serveTestRequest(engine, "/")
+	assert.PanicsWithValue(t, errContextReleased, func() { retained.Get("user") })
+	assert.PanicsWithValue(t, errContextReleased, func() { retained.Namespace("auth").Set("user", "x") })
+
+	serveTestRequest(engine, "/?copy=1")
+	assert.Equal(t, "john", copied.Get("user"))
+	assert.Equal(t, "john", retained.Get("user"), "the data shared with a copy is kept")
+
+	engine.Debug = true
+	serveTestRequest(engine, "/")
+	first := retained
+	serveTestRequest(engine, "/")
+	assert.True(t, first != retained, "contexts are not reused in the Debug mode")
+	assert.Panics(t, func() { first.Keys() })
+}
+
+func TestContextPoisoned(t *testing.T) {
+	engine := New()
+	engine.Debug = true
+	var retained *Context
+	engine.GET("/users/<id>", func(c *Context) {
+		retained = c
+	})
+	serveTestRequest(engine, "/users/1")
+	message := errContextReleased + ": the context of GET /users/1 is captured by a goroutine or a callback"
+	assert.PanicsWithValue(t, message, func() { retained.Param("id") })
+	assert.PanicsWithValue(t, message, func() { retained.String(200, "late") })
+	assert.PanicsWithValue(t, message, func() { retained.Set("user", "john") })
+	assert.PanicsWithValue(t, message, func() { retained.Next() })
+}
@@ -0,0 +1,97 @@
+package tokay
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type (
+	// ControllerRoute declares a custom route of the controller (see RoutesDeclarer).
+	ControllerRoute struct {
+		// Methods are the HTTP methods separated by commas, like "GET" or "PUT,PATCH".
+		Methods string
+		// Path is the route path relative to the mount point, like "/<id>/archive".
+		Path    string
+		Handler Handler
+	}
+
+	// RoutesDeclarer is implemented by the controllers declaring routes besides the conventional ones (see Mount).
+	RoutesDeclarer interface {
+		Routes() []ControllerRoute
+	}
+
+	// resourceAction is a conventional RESTful action.
+	resourceAction struct {
+		name, methods, path string
+	}
+)
+
+// resourceActions are the conventional RESTful actions in the registration order.
+var resourceActions = []resourceAction{
+	{"Index", "GET", ""},
+	{"New", "GET", "/new"},
+	{"Create", "POST", ""},
+	{"Show", "GET", "/<id>"},
+	{"Edit", "GET", "/<id>/edit"},
+	{"Update", "PUT,PATCH", "/<id>"},
+	{"Delete", "DELETE", "/<id>"},
+}
+
+// Mount registers the routes of the controller methods named by the RESTful conventions
+// (the methods must have the func(*tokay.Context) signature):
+//
+//	Index   GET        /
+//	New     GET        /new
+//	Create  POST       /
+//	Show    GET        /<id>
+//	Edit    GET        /<id>/edit
+//	Update  PUT, PATCH /<id>
+//	Delete  DELETE     /<id>
+//
+// The routes declared by the RoutesDeclarer controllers are registered as well.
+// The handlers are called before the controller methods. Mount returns the registered routes.
+//
+//	engine.Group("/users").Mount(&UsersController{db: db}, authHandler)
+func (r *RouterGroup) Mount(controller interface{}, handlers ...Handler) []*Route {
+	v := reflect.ValueOf(controller)
+	routes := newRouteSet(r)
+	for _, action := range resourceActions {
+		method := v.MethodByName(action.name)
+		if !method.IsValid() {
+			continue
+		}
+		handler, ok := method.Interface().(func(*Context))
+		if !ok {
+			panic(fmt.Sprintf("Mount: %T.%s method must be func(*tokay.Context)", controller, action.name))
+		}
+		routes.add(action.methods, action.path, append(handlers[:len(handlers):len(handlers)], handler))
+	}
+	if declarer, ok := controller.(RoutesDeclarer); ok {
+		for _, route := range declarer.Routes() {
+			routes.add(route.Methods, route.Path, append(handlers[:len(handlers):len(handlers)], route.Handler))
+		}
+	}
+	return routes.list
+}
+
+// routeSet registers routes reusing the Route of the same path.
+type routeSet struct {
+	group  *RouterGroup
+	byPath map[string]*Route
+	list   []*Route
+}
+
+func newRouteSet(group *RouterGroup) *routeSet {
+	return &routeSet{group: group, byPath: make(map[string]*Route)}
+}
+
+// add registers the handlers of the route path for the methods.
+func (s *routeSet) add(methods, path string, handlers []Handler) *Route {
+	route, ok := s.byPath[path]
+	if !ok {
+		route = newRoute(path, s.group)
+		s.byPath[path] = route
+		s.list = append(s.list, route)
+	}
+	return route.To(methods, handlers...)
+}
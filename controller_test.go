@@ -0,0 +1,67 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type usersController struct {
+	prefix string
+}
+
+func (u *usersController) Index(c *Context)  { c.String(200, u.prefix+"index") }
+func (u *usersController) Show(c *Context)   { c.String(200, u.prefix+"show "+c.Param("id")) }
+func (u *usersController) Create(c *Context) { c.String(201, u.prefix+"create") }
+func (u *usersController) Update(c *Context) { c.String(200, u.prefix+"update "+c.Param("id")) }
+func (u *usersController) Delete(c *Context) { c.String(204, "") }
+func (u *usersController) Helper() string    { return "not a route" }
+func (u *usersController) Routes() []ControllerRoute {
+	return []ControllerRoute{
+		{Methods: "POST", Path: "/<id>/archive", Handler: func(c *Context) { c.String(200, u.prefix+"archive "+c.Param("id")) }},
+	}
+}
+
+func TestMount(t *testing.T) {
+	engine := New()
+	routes := engine.Group("/users").Mount(&usersController{prefix: "users "}, func(c *Context) {
+		c.Header("X-Mounted", "1")
+	})
+	var paths []string
+	for _, route := range routes {
+		paths = append(paths, route.Path())
+	}
+	assert.Equal(t, []string{"/users", "/users/<id>", "/users/<id>/archive"}, paths)
+	assert.Equal(t, []string{"GET", "POST"}, routes[0].Info().Methods)
+	assert.Equal(t, []string{"GET", "PUT", "PATCH", "DELETE"}, routes[1].Info().Methods)
+
+	tests := []struct {
+		method, uri string
+		status      int
+		body        string
+	}{
+		{"GET", "/users", 200, "users index"},
+		{"POST", "/users", 201, "users create"},
+		{"GET", "/users/5", 200, "users show 5"},
+		{"PATCH", "/users/5", 200, "users update 5"},
+		{"DELETE", "/users/5", 204, ""},
+		{"POST", "/users/5/archive", 200, "users archive 5"},
+		{"GET", "/users/new", 200, "users show new"},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, test.uri, withMethod(test.method))
+		assert.Equal(t, test.status, ctx.Response.StatusCode(), test.method+" "+test.uri)
+		assert.Equal(t, test.body, string(ctx.Response.Body()), test.method+" "+test.uri)
+		assert.Equal(t, "1", string(ctx.Response.Header.Peek("X-Mounted")), test.method+" "+test.uri)
+	}
+}
+
+type invalidController struct{}
+
+func (invalidController) Index() {}
+
+func TestMountInvalidMethod(t *testing.T) {
+	assert.Panics(t, func() {
+		New().Mount(invalidController{})
+	})
+}
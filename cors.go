@@ -0,0 +1,202 @@
+package tokay
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CORSConfig is a struct for specifying CORS middleware options.
+type CORSConfig struct {
+	// AllowOrigins is the list of allowed origins. "*" allows any origin. Default is ["*"].
+	AllowOrigins []string
+	// AllowOriginFunc is the custom origin check used instead of AllowOrigins.
+	AllowOriginFunc func(origin string) bool
+	// AllowMethods is the list of allowed methods. By default the methods registered
+	// for the requested path are allowed (see Engine.AllowedMethods).
+	AllowMethods []string
+	// AllowHeaders is the list of allowed request headers. By default the headers requested
+	// by the preflight request are allowed.
+	AllowHeaders []string
+	// ExposeHeaders is the list of response headers exposed to the browser scripts.
+	ExposeHeaders []string
+	// AllowCredentials allows requests with credentials (cookies, authorization headers).
+	AllowCredentials bool
+	// MaxAge is the time the preflight results may be cached by browsers (Access-Control-Max-Age).
+	// The computed preflight responses are cached by the server for the same time. Default is 10 minutes.
+	MaxAge time.Duration
+	// CacheSize is the maximum number of the cached preflight responses. Default is 1000,
+	// negative value disables the server-side cache.
+	CacheSize int
+}
+
+type (
+	// preflightCache keeps the computed preflight responses by origin, method and headers.
+	preflightCache struct {
+		sync.RWMutex
+		size    int
+		entries map[string]*preflightEntry
+	}
+
+	// preflightEntry is the computed preflight response.
+	preflightEntry struct {
+		allowed bool
+		methods string
+		headers string
+		expires time.Time
+	}
+)
+
+// CORS returns a middleware handling the Cross-Origin Resource Sharing requests.
+// Preflight requests (OPTIONS with Access-Control-Request-Method) are answered with 204 No Content
+// (403 Forbidden for the disallowed origins and methods) and not passed to the next handlers.
+//
+//	engine.Use(tokay.CORS(tokay.CORSConfig{
+//		AllowOrigins:     []string{"https://app.example.com"},
+//		AllowCredentials: true,
+//	}))
+func CORS(config CORSConfig) Handler {
+	if len(config.AllowOrigins) == 0 {
+		config.AllowOrigins = []string{"*"}
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = 10 * time.Minute
+	}
+	if config.CacheSize == 0 {
+		config.CacheSize = 1000
+	}
+	allowOrigin := config.AllowOriginFunc
+	if allowOrigin == nil {
+		allowOrigin = func(origin string) bool {
+			for _, o := range config.AllowOrigins {
+				if o == "*" || strings.EqualFold(o, origin) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	anyOrigin := config.AllowOriginFunc == nil && !config.AllowCredentials && inStrings("*", config.AllowOrigins)
+	maxAge := strconv.Itoa(int(config.MaxAge / time.Second))
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	cache := &preflightCache{size: config.CacheSize, entries: make(map[string]*preflightEntry)}
+
+	preflight := func(c *Context, origin, method, headers string) *preflightEntry {
+		entry := &preflightEntry{expires: time.Now().Add(config.MaxAge)}
+		if !allowOrigin(origin) {
+			return entry
+		}
+		methods := config.AllowMethods
+		if len(methods) == 0 {
+			methods = c.engine.AllowedMethods(c.Path())
+		}
+		if !inStrings(method, methods) {
+			return entry
+		}
+		entry.allowed = true
+		entry.methods = strings.Join(methods, ", ")
+		entry.headers = headers
+		if len(config.AllowHeaders) != 0 {
+			entry.headers = strings.Join(config.AllowHeaders, ", ")
+		}
+		return entry
+	}
+
+	return func(c *Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			return
+		}
+		c.Response.Header.Add("Vary", "Origin")
+		method := c.GetHeader("Access-Control-Request-Method")
+
+		if c.Method() != "OPTIONS" || method == "" {
+			if !allowOrigin(origin) {
+				return
+			}
+			setAllowOrigin(c, origin, anyOrigin, config.AllowCredentials)
+			if exposeHeaders != "" {
+				c.Header("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			return
+		}
+
+		headers := c.GetHeader("Access-Control-Request-Headers")
+		key := origin + "\n" + method + "\n" + headers
+		if len(config.AllowMethods) == 0 {
+			// the allowed methods depend on the requested path
+			if allowed := c.engine.findAllowedMethods(c.Path()); allowed != nil {
+				key += "\n" + allowed.header
+			}
+		}
+		entry := cache.get(key)
+		if entry == nil {
+			entry = preflight(c, origin, method, headers)
+			cache.set(key, entry)
+		}
+
+		c.Response.Header.Add("Vary", "Access-Control-Request-Method")
+		c.Response.Header.Add("Vary", "Access-Control-Request-Headers")
+		if !entry.allowed {
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		setAllowOrigin(c, origin, anyOrigin, config.AllowCredentials)
+		c.Header("Access-Control-Allow-Methods", entry.methods)
+		if entry.headers != "" {
+			c.Header("Access-Control-Allow-Headers", entry.headers)
+		}
+		c.Header("Access-Control-Max-Age", maxAge)
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// setAllowOrigin sets the Access-Control-Allow-Origin and Access-Control-Allow-Credentials headers.
+func setAllowOrigin(c *Context, origin string, anyOrigin, credentials bool) {
+	if anyOrigin {
+		c.Header("Access-Control-Allow-Origin", "*")
+	} else {
+		c.Header("Access-Control-Allow-Origin", origin)
+	}
+	if credentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// get returns the cached preflight response which is not expired, or nil.
+func (p *preflightCache) get(key string) *preflightEntry {
+	if p.size < 0 {
+		return nil
+	}
+	p.RLock()
+	entry := p.entries[key]
+	p.RUnlock()
+	if entry != nil && time.Now().Before(entry.expires) {
+		return entry
+	}
+	return nil
+}
+
+// set caches the preflight response. The expired entries are evicted when the cache is full,
+// the whole cache is cleared if it is still full.
+func (p *preflightCache) set(key string, entry *preflightEntry) {
+	if p.size < 0 {
+		return
+	}
+	p.Lock()
+	if len(p.entries) >= p.size {
+		now := time.Now()
+		for k, e := range p.entries {
+			if !now.Before(e.expires) {
+				delete(p.entries, k)
+			}
+		}
+		if len(p.entries) >= p.size {
+			p.entries = make(map[string]*preflightEntry)
+		}
+	}
+	p.entries[key] = entry
+	p.Unlock()
+}
@@ -0,0 +1,60 @@
+package tokay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	engine := New()
+	engine.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com"},
+		ExposeHeaders:    []string{"X-Total"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	}))
+	engine.To("GET,PUT", "/users/<id>", func(c *Context) {
+		c.String(200, "OK")
+	})
+
+	ctx := serveTestRequest(engine, "/users/1", withHeader("Origin", "https://app.example.com"))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "https://app.example.com", string(ctx.Response.Header.Peek("Access-Control-Allow-Origin")))
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek("Access-Control-Allow-Credentials")))
+	assert.Equal(t, "X-Total", string(ctx.Response.Header.Peek("Access-Control-Expose-Headers")))
+
+	ctx = serveTestRequest(engine, "/users/1", withHeader("Origin", "https://evil.com"))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Empty(t, ctx.Response.Header.Peek("Access-Control-Allow-Origin"))
+
+	for i := 0; i < 2; i++ {
+		ctx = serveTestRequest(engine, "/users/1", withMethod("OPTIONS"), withHeader("Origin", "https://app.example.com"),
+			withHeader("Access-Control-Request-Method", "PUT"), withHeader("Access-Control-Request-Headers", "Content-Type"))
+		assert.Equal(t, 204, ctx.Response.StatusCode())
+		assert.Equal(t, "GET, OPTIONS, PUT", string(ctx.Response.Header.Peek("Access-Control-Allow-Methods")))
+		assert.Equal(t, "Content-Type", string(ctx.Response.Header.Peek("Access-Control-Allow-Headers")))
+		assert.Equal(t, "3600", string(ctx.Response.Header.Peek("Access-Control-Max-Age")))
+	}
+
+	ctx = serveTestRequest(engine, "/users/1", withMethod("OPTIONS"), withHeader("Origin", "https://app.example.com"),
+		withHeader("Access-Control-Request-Method", "DELETE"))
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+	ctx = serveTestRequest(engine, "/users/1", withMethod("OPTIONS"), withHeader("Origin", "https://evil.com"),
+		withHeader("Access-Control-Request-Method", "GET"))
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+}
+
+func TestPreflightCache(t *testing.T) {
+	cache := &preflightCache{size: 2, entries: make(map[string]*preflightEntry)}
+	cache.set("a", &preflightEntry{allowed: true, expires: time.Now().Add(time.Hour)})
+	cache.set("b", &preflightEntry{expires: time.Now().Add(-time.Second)})
+	assert.NotNil(t, cache.get("a"))
+	assert.Nil(t, cache.get("b"))
+
+	cache.set("c", &preflightEntry{expires: time.Now().Add(time.Hour)})
+	assert.Len(t, cache.entries, 2)
+	assert.NotNil(t, cache.get("a"))
+	assert.NotNil(t, cache.get("c"))
+}
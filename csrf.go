@@ -0,0 +1,131 @@
+package tokay
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// CSRFMetaKey is the route metadata key of the CSRF protection mode set by Route.CSRF.
+	CSRFMetaKey = "csrf"
+	// CSRFTokenKey is the context data item with the CSRF token of the request (see Context.CSRFToken).
+	CSRFTokenKey = "csrf_token"
+)
+
+// CSRFConfig is a struct for specifying CSRF middleware options.
+type CSRFConfig struct {
+	// CookieName is the name of the cookie with the token. Default is "_csrf".
+	CookieName string
+	// HeaderName is the request header with the token. Default is "X-CSRF-Token".
+	HeaderName string
+	// FormField is the form field with the token checked when the header is missing. Default is "_csrf".
+	FormField string
+	// SameSite is the SameSite attribute of the token cookie. Default is Lax.
+	SameSite fasthttp.CookieSameSite
+	// OptIn checks only the routes and the groups marked with CSRF(true),
+	// by default all the routes are checked except the ones marked with CSRF(false).
+	OptIn bool
+}
+
+// CSRF marks the route as CSRF-enforced or CSRF-exempt (like the webhook receivers) for the CSRF
+// middleware, overriding the mode of the group.
+//
+//	engine.POST("/webhooks/stripe", stripeWebhook).CSRF(false)
+func (r *Route) CSRF(enforce bool) *Route {
+	return r.SetMeta(CSRFMetaKey, enforce)
+}
+
+// CSRF marks the routes of the group and the subgroups created after the call as CSRF-enforced or
+// CSRF-exempt for the CSRF middleware (see Route.CSRF).
+//
+//	hooks := engine.Group("/hooks").CSRF(false)
+func (r *RouterGroup) CSRF(enforce bool) *RouterGroup {
+	r.csrf = &enforce
+	return r
+}
+
+// csrfEnforced reports whether the CSRF middleware checks the route.
+func csrfEnforced(route *Route, optIn bool) bool {
+	if route == nil {
+		return !optIn
+	}
+	if enforce, ok := route.Meta(CSRFMetaKey).(bool); ok {
+		return enforce
+	}
+	if route.group.csrf != nil {
+		return *route.group.csrf
+	}
+	return !optIn
+}
+
+// CSRF returns the middleware protecting against the cross-site request forgery with the double submit
+// cookie: the token is kept in the SameSite cookie and the requests with the methods other than GET, HEAD,
+// OPTIONS and TRACE must send it in the header or the form field, otherwise they get 403 Forbidden with
+// the "csrf" abort reason. The routes can be exempted or enforced with Route.CSRF and RouterGroup.CSRF.
+// The token of the request is returned by Context.CSRFToken for the forms.
+//
+//	engine.Use(tokay.CSRF(tokay.CSRFConfig{}))
+func CSRF(config CSRFConfig) Handler {
+	if config.CookieName == "" {
+		config.CookieName = "_csrf"
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "X-CSRF-Token"
+	}
+	if config.FormField == "" {
+		config.FormField = "_csrf"
+	}
+	if config.SameSite == fasthttp.CookieSameSiteDisabled {
+		config.SameSite = fasthttp.CookieSameSiteLaxMode
+	}
+	return func(c *Context) {
+		if !csrfEnforced(c.route, config.OptIn) {
+			c.Next()
+			return
+		}
+		token := string(c.Request.Header.Cookie(config.CookieName))
+		switch c.Method() {
+		case "GET", "HEAD", "OPTIONS", "TRACE":
+		default:
+			sent := c.GetHeader(config.HeaderName)
+			if sent == "" {
+				sent = c.PostForm(config.FormField)
+			}
+			if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				c.AbortWithReason(http.StatusForbidden, "csrf")
+				return
+			}
+		}
+		if token == "" {
+			token = newCSRFToken()
+			cookie := fasthttp.AcquireCookie()
+			cookie.SetKey(config.CookieName)
+			cookie.SetValue(token)
+			cookie.SetPath("/")
+			cookie.SetSecure(c.Scheme() == "https")
+			cookie.SetSameSite(config.SameSite)
+			c.Response.Header.SetCookie(cookie)
+			fasthttp.ReleaseCookie(cookie)
+		}
+		c.Set(CSRFTokenKey, token)
+		c.Next()
+	}
+}
+
+// CSRFToken returns the CSRF token of the request set by the CSRF middleware for the hidden form field,
+// the empty string for the exempt routes.
+func (c *Context) CSRFToken() string {
+	token, _ := c.Get(CSRFTokenKey).(string)
+	return token
+}
+
+// newCSRFToken returns a random token.
+func newCSRFToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
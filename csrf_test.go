@@ -0,0 +1,63 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCSRF(t *testing.T) {
+	engine := New()
+	engine.Use(CSRF(CSRFConfig{}))
+	var token string
+	engine.GET("/form", func(c *Context) {
+		token = c.CSRFToken()
+	})
+	engine.POST("/form", func(c *Context) {
+		c.String(200, "saved")
+	})
+	engine.POST("/webhook", func(c *Context) {
+		c.String(200, "hook")
+	}).CSRF(false)
+	hooks := engine.Group("/hooks").CSRF(false)
+	hooks.POST("/a", func(c *Context) {})
+	hooks.Group("/v2").POST("/b", func(c *Context) {})
+	hooks.POST("/checked", func(c *Context) {}).CSRF(true)
+
+	ctx := serveTestRequest(engine, "/form")
+	cookie := fasthttp.AcquireCookie()
+	cookie.SetKey("_csrf")
+	if assert.True(t, ctx.Response.Header.Cookie(cookie)) {
+		assert.Equal(t, token, string(cookie.Value()))
+		assert.Equal(t, fasthttp.CookieSameSiteLaxMode, cookie.SameSite())
+	}
+	assert.Len(t, token, 43)
+
+	post, tokenCookie := withMethod("POST"), withHeader("Cookie", "_csrf="+token)
+	assert.Equal(t, 403, serveTestRequest(engine, "/form", post).Response.StatusCode())
+	assert.Equal(t, 403, serveTestRequest(engine, "/form", post, tokenCookie).Response.StatusCode())
+	assert.Equal(t, 403, serveTestRequest(engine, "/form", post, tokenCookie, withHeader("X-CSRF-Token", "forged")).Response.StatusCode())
+	assert.Equal(t, 200, serveTestRequest(engine, "/form", post, tokenCookie, withHeader("X-CSRF-Token", token)).Response.StatusCode())
+
+	ctx = serveTestRequest(engine, "/form", post, tokenCookie, withBody("application/x-www-form-urlencoded", "_csrf="+token))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	assert.Equal(t, 200, serveTestRequest(engine, "/webhook", post).Response.StatusCode())
+	assert.Equal(t, 200, serveTestRequest(engine, "/hooks/a", post).Response.StatusCode())
+	assert.Equal(t, 200, serveTestRequest(engine, "/hooks/v2/b", post).Response.StatusCode())
+	assert.Equal(t, 403, serveTestRequest(engine, "/hooks/checked", post).Response.StatusCode())
+}
+
+func TestCSRFOptIn(t *testing.T) {
+	engine := New()
+	engine.Use(CSRF(CSRFConfig{OptIn: true}))
+	engine.POST("/api", func(c *Context) {})
+	engine.POST("/form", func(c *Context) {}).CSRF(true)
+	forms := engine.Group("/forms").CSRF(true)
+	forms.POST("/a", func(c *Context) {})
+
+	assert.Equal(t, 200, serveTestRequest(engine, "/api", withMethod("POST")).Response.StatusCode())
+	assert.Equal(t, 403, serveTestRequest(engine, "/form", withMethod("POST")).Response.StatusCode())
+	assert.Equal(t, 403, serveTestRequest(engine, "/forms/a", withMethod("POST")).Response.StatusCode())
+}
@@ -2,17 +2,54 @@ package tokay
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 type dataMap struct {
 	sync.RWMutex
 	M map[string]interface{}
+	// gen is the generation of the map, incremented when the map is released to the pool
+	gen uint64
+	// shared maps are used by the context copies and are never released (see Context.Copy)
+	shared bool
+}
+
+// errContextReleased is the panic value of the access to the context data after the request end.
+const errContextReleased = "tokay: Context data used after the end of the request (use Context.Copy in goroutines)"
+
+// dataMapPool keeps the released data maps.
+var dataMapPool = sync.Pool{
+	New: func() interface{} {
+		return newDataMap()
+	},
 }
 
 func newDataMap() *dataMap {
 	return &dataMap{M: make(map[string]interface{})}
 }
 
+// acquireDataMap returns an empty data map from the pool and its generation.
+func acquireDataMap() (*dataMap, uint64) {
+	m := dataMapPool.Get().(*dataMap)
+	return m, atomic.LoadUint64(&m.gen)
+}
+
+// releaseDataMap clears the map and returns it to the pool, the contexts retaining
+// the map panic on its access. The shared maps are kept.
+func releaseDataMap(m *dataMap) {
+	m.Lock()
+	if m.shared {
+		m.Unlock()
+		return
+	}
+	atomic.AddUint64(&m.gen, 1)
+	for key := range m.M {
+		delete(m.M, key)
+	}
+	m.Unlock()
+	dataMapPool.Put(m)
+}
+
 func (m *dataMap) Copy() (c map[string]interface{}) {
 	c = make(map[string]interface{}, len(m.M))
 
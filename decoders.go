@@ -0,0 +1,56 @@
+package tokay
+
+import "strings"
+
+type (
+	// DecoderFunc decodes the request body into the struct pointer (see Engine.RegisterDecoder).
+	DecoderFunc func(data []byte, obj interface{}) error
+
+	// BinderFunc binds the request data into the struct pointer (see Engine.RegisterBinder).
+	BinderFunc func(c *Context, obj interface{}) error
+
+	// ValidatorFunc validates the bound struct (see Engine.SetValidator).
+	ValidatorFunc func(obj interface{}) error
+)
+
+// RegisterDecoder registers the request body decoder of the content type (without parameters) used by
+// Context.Bind, so the custom and vendor content types are bound like JSON or XML. The registered
+// decoders take precedence over the built-in ones. The bound structs are validated as well.
+//
+//	engine.RegisterDecoder("application/x-amz-json-1.1", json.Unmarshal)
+func (engine *Engine) RegisterDecoder(contentType string, fn DecoderFunc) {
+	if engine.decoders == nil {
+		engine.decoders = make(map[string]DecoderFunc)
+	}
+	engine.decoders[strings.ToLower(contentType)] = fn
+}
+
+// RegisterBinder registers the binder of the content type (without parameters) used by Context.Bind,
+// for the content types needing more than the request body, like the protobuf messages with the
+// type in the Content-Type parameters. The binders take precedence over the registered decoders and
+// the built-in ones. The bound structs are validated as well.
+//
+//	engine.RegisterBinder("application/x-msgpack", func(c *tokay.Context, obj interface{}) error {
+//		return msgpack.Unmarshal(c.Body(), obj)
+//	})
+func (engine *Engine) RegisterBinder(contentType string, fn BinderFunc) {
+	if engine.binders == nil {
+		engine.binders = make(map[string]BinderFunc)
+	}
+	engine.binders[strings.ToLower(contentType)] = fn
+}
+
+// SetValidator replaces the validation of the structs bound by the Context.Bind* methods, for example
+// with go-playground/validator. The nil validator restores the default govalidator validation
+// (see the "valid" struct tags).
+//
+//	validate := validator.New()
+//	engine.SetValidator(validate.Struct)
+func (engine *Engine) SetValidator(fn ValidatorFunc) {
+	engine.validator = fn
+}
+
+// BindWith binds the passed struct pointer with the request body decoded by the decoder.
+func (c *Context) BindWith(obj interface{}, decode DecoderFunc) error {
+	return c.validate(decode(c.Body(), obj), obj)
+}
@@ -0,0 +1,93 @@
+package tokay
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/night-codes/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineRegisterDecoder(t *testing.T) {
+	type item struct {
+		Name string `json:"name" valid:"required"`
+	}
+	engine := New()
+	engine.RegisterDecoder("application/x-amz-json-1.1", json.Unmarshal)
+	engine.RegisterDecoder("Text/Plain", func(data []byte, obj interface{}) error {
+		if strings.Contains(string(data), "!") {
+			return errors.New("bad name")
+		}
+		obj.(*item).Name = string(data)
+		return nil
+	})
+	engine.POST("/", func(c *Context) {
+		var obj item
+		if err := c.Bind(&obj); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.String(200, obj.Name)
+	})
+
+	tests := []struct {
+		contentType, body string
+		status            int
+		response          string
+	}{
+		{"application/x-amz-json-1.1", `{"name":"amz"}`, 200, "amz"},
+		{"text/plain; charset=utf-8", "plain", 200, "plain"},
+		{"text/plain", "plain!", 400, "bad name"},
+		{"text/plain", "", 400, "Name: non zero value required;"},
+		{"application/json", `{"name":"json"}`, 200, "json"},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, "/", withMethod("POST"), withBody(test.contentType, test.body))
+		assert.Equal(t, test.status, ctx.Response.StatusCode(), test.contentType)
+		assert.Equal(t, test.response, string(ctx.Response.Body()), test.contentType)
+	}
+}
+
+func TestEngineRegisterBinder(t *testing.T) {
+	type item struct {
+		Name string `valid:"required"`
+	}
+	engine := New()
+	engine.RegisterDecoder("text/plain", func(data []byte, obj interface{}) error {
+		return errors.New("decoder is overridden")
+	})
+	engine.RegisterBinder("text/plain", func(c *Context, obj interface{}) error {
+		obj.(*item).Name = string(c.Body()) + c.Query("suffix")
+		return nil
+	})
+	engine.POST("/", func(c *Context) {
+		var obj item
+		if err := c.Bind(&obj); err != nil {
+			c.String(400, err.Error())
+			return
+		}
+		c.String(200, obj.Name)
+	})
+
+	for uri, expected := range map[string]string{"/?suffix=!": "name!", "/": "name"} {
+		ctx := serveTestRequest(engine, uri, withMethod("POST"), withBody("text/plain", "name"))
+		assert.Equal(t, expected, string(ctx.Response.Body()))
+	}
+
+	engine.SetValidator(func(obj interface{}) error {
+		if obj.(*item).Name != "name" {
+			return errors.New("invalid name")
+		}
+		return nil
+	})
+	ctx := serveTestRequest(engine, "/?suffix=!", withMethod("POST"), withBody("text/plain", ""))
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+	assert.Equal(t, "invalid name", string(ctx.Response.Body()))
+
+	engine.SetValidator(nil)
+	ctx.Request.SetRequestURI("/")
+	ctx.Response.Reset()
+	engine.HandleRequest(ctx)
+	assert.Equal(t, "Name: non zero value required;", string(ctx.Response.Body()))
+}
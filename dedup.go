@@ -0,0 +1,129 @@
+package tokay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type (
+	// DedupConfig is a struct for specifying Dedup middleware options.
+	DedupConfig struct {
+		// Headers are the request headers hashed together with the body, like the delivery
+		// signature or the event type. The headers with per-delivery values (timestamps,
+		// delivery IDs of the retries) must not be listed.
+		Headers []string
+		// TTL is the period the duplicates are dropped within. Default is 24 hours.
+		TTL time.Duration
+		// Store keeps the hashes of the seen requests. Default is the in-memory store,
+		// use a shared store for multiple server instances.
+		Store DedupStore
+	}
+
+	// DedupStore keeps the request hashes seen by the Dedup middleware.
+	DedupStore interface {
+		// Add stores the key for the ttl. It returns false if the key is already stored.
+		Add(key string, ttl time.Duration) (bool, error)
+		// Remove deletes the key, so the next request with the same hash is handled again.
+		Remove(key string) error
+	}
+
+	// memoryDedupStore keeps the request hashes in memory.
+	memoryDedupStore struct {
+		sync.Mutex
+		keys    map[string]time.Time // expiration times by keys
+		cleaned time.Time
+	}
+)
+
+// NewMemoryDedupStore creates the DedupStore keeping the request hashes in memory.
+func NewMemoryDedupStore() DedupStore {
+	return &memoryDedupStore{keys: make(map[string]time.Time), cleaned: time.Now()}
+}
+
+// Add implements DedupStore.
+func (s *memoryDedupStore) Add(key string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.Lock()
+	defer s.Unlock()
+	if now.Sub(s.cleaned) > time.Minute {
+		for k, expires := range s.keys {
+			if now.After(expires) {
+				delete(s.keys, k)
+			}
+		}
+		s.cleaned = now
+	}
+	if expires, ok := s.keys[key]; ok && now.Before(expires) {
+		return false, nil
+	}
+	s.keys[key] = now.Add(ttl)
+	return true, nil
+}
+
+// Remove implements DedupStore.
+func (s *memoryDedupStore) Remove(key string) error {
+	s.Lock()
+	delete(s.keys, key)
+	s.Unlock()
+	return nil
+}
+
+// Dedup returns a middleware dropping the duplicate webhook deliveries: the requests with the same method,
+// path, body and configured headers seen within the TTL are answered with 200 OK (and the X-Duplicate header)
+// without calling the next handlers. The failed requests (5xx responses) are forgotten, so the sender retries
+// are handled again. The requests are handled if the store fails.
+//
+//	engine.POST("/webhooks/stripe", tokay.Dedup(tokay.DedupConfig{
+//		Headers: []string{"Stripe-Signature"},
+//		TTL:     time.Hour,
+//	}), stripeWebhook)
+func Dedup(config ...DedupConfig) Handler {
+	var cfg DedupConfig
+	if len(config) != 0 {
+		cfg = config[0]
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryDedupStore()
+	}
+
+	return func(c *Context) {
+		key := dedupKey(c, cfg.Headers)
+		added, err := cfg.Store.Add(key, cfg.TTL)
+		if err != nil {
+			c.Logger().Warn("dedup store failed", "error", err)
+			return
+		}
+		if !added {
+			c.Header("X-Duplicate", "true")
+			c.AbortWithStatus(http.StatusOK)
+			return
+		}
+		c.Next()
+		if c.Response.StatusCode() >= http.StatusInternalServerError {
+			if err := cfg.Store.Remove(key); err != nil {
+				c.Logger().Warn("dedup store failed", "error", err)
+			}
+		}
+	}
+}
+
+// dedupKey returns the hash of the request method, path, headers and body.
+func dedupKey(c *Context, headers []string) string {
+	h := sha256.New()
+	h.Write(c.Request.Header.Method())
+	h.Write([]byte{0})
+	h.Write(c.URI().Path())
+	for _, header := range headers {
+		h.Write([]byte{0})
+		h.Write(c.Request.Header.Peek(header))
+	}
+	h.Write([]byte{0})
+	h.Write(c.PostBody())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,68 @@
+package tokay
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type failingDedupStore struct{}
+
+func (failingDedupStore) Add(string, time.Duration) (bool, error) { return false, errors.New("down") }
+func (failingDedupStore) Remove(string) error                     { return nil }
+
+func TestDedup(t *testing.T) {
+	engine := New()
+	engine.SetLogger(&testLogger{})
+	calls, status := 0, 200
+	engine.POST("/hook", Dedup(DedupConfig{Headers: []string{"X-Event"}, TTL: time.Minute}), func(c *Context) {
+		calls++
+		c.String(status, "handled")
+	})
+	engine.POST("/unstored", Dedup(DedupConfig{Store: failingDedupStore{}}), func(c *Context) {
+		calls++
+	})
+
+	post := func(uri, event, body string) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, uri, withMethod("POST"), withHeader("X-Event", event), withBody("", body))
+	}
+
+	ctx := post("/hook", "created", `{"id":1}`)
+	assert.Equal(t, "handled", string(ctx.Response.Body()))
+	ctx = post("/hook", "created", `{"id":1}`)
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek("X-Duplicate")))
+	assert.Equal(t, 1, calls)
+
+	post("/hook", "updated", `{"id":1}`)
+	post("/hook", "created", `{"id":2}`)
+	assert.Equal(t, 3, calls)
+
+	status = 500
+	post("/hook", "created", `{"id":3}`)
+	status = 200
+	ctx = post("/hook", "created", `{"id":3}`)
+	assert.Equal(t, "", string(ctx.Response.Header.Peek("X-Duplicate")))
+	assert.Equal(t, 5, calls)
+
+	post("/unstored", "", "a")
+	post("/unstored", "", "a")
+	assert.Equal(t, 7, calls)
+}
+
+func TestMemoryDedupStore(t *testing.T) {
+	store := NewMemoryDedupStore()
+	added, _ := store.Add("a", 10*time.Millisecond)
+	assert.True(t, added)
+	added, _ = store.Add("a", 10*time.Millisecond)
+	assert.False(t, added)
+	time.Sleep(20 * time.Millisecond)
+	added, _ = store.Add("a", time.Minute)
+	assert.True(t, added)
+	store.Remove("a")
+	added, _ = store.Add("a", time.Minute)
+	assert.True(t, added)
+}
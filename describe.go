@@ -0,0 +1,101 @@
+package tokay
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+)
+
+type (
+	// EngineDescription is a machine-readable model of the engine routing (see Engine.Describe).
+	// Handlers are described by their function names.
+	EngineDescription struct {
+		Middlewares []string           `json:"middlewares"` // handlers registered by engine.Use
+		Groups      []GroupDescription `json:"groups"`
+		Routes      []RouteDescription `json:"routes"`
+		NotFound    []string           `json:"notFound"` // handlers invoked when no route matches
+	}
+
+	// GroupDescription describes a route group.
+	GroupDescription struct {
+		Path        string   `json:"path"`
+		Middlewares []string `json:"middlewares"` // the group handlers including the inherited ones
+	}
+
+	// RouteDescription describes a route registered with a HTTP method.
+	RouteDescription struct {
+		Name     string   `json:"name"`
+		Method   string   `json:"method"`
+		Path     string   `json:"path"`
+		Handlers []string `json:"handlers"` // the full handlers chain in the invocation order
+	}
+)
+
+// Describe returns the description of the engine middlewares, route groups, routes and
+// NotFound handlers chain. It is handy to generate architecture docs and to test that
+// the middlewares are wired in the intended order:
+//
+//	d := engine.Describe()
+//	assert.Equal(t, []string{"main.auth", "main.listUsers"}, d.Routes[0].Handlers)
+func (engine *Engine) Describe() EngineDescription {
+	d := EngineDescription{
+		Middlewares: handlerNames(engine.handlers),
+		Groups:      make([]GroupDescription, 0, len(engine.groups)),
+		Routes:      make([]RouteDescription, 0, len(engine.routeList)),
+		NotFound:    handlerNames(engine.notFoundHandlers),
+	}
+	for _, group := range engine.groups {
+		d.Groups = append(d.Groups, GroupDescription{Path: group.path, Middlewares: handlerNames(group.handlers)})
+	}
+	for _, route := range engine.routeList {
+		for _, method := range route.methods {
+			d.Routes = append(d.Routes, RouteDescription{
+				Name:     route.name,
+				Method:   method,
+				Path:     route.path,
+				Handlers: handlerNames(route.chains[method]),
+			})
+		}
+	}
+	return d
+}
+
+// handlerName returns the name of the handler function.
+func handlerName(h Handler) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+}
+
+// handlerNames returns the names of the handler functions.
+func handlerNames(handlers []Handler) []string {
+	names := make([]string, len(handlers))
+	for i, h := range handlers {
+		names[i] = handlerName(h)
+	}
+	return names
+}
+
+// PrintRoutes writes the table of the routes with their methods, names and the last handlers,
+// for example to print the routing table on the startup:
+//
+//	engine.PrintRoutes(os.Stdout)
+func (engine *Engine) PrintRoutes(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tNAME\tHANDLER")
+	for _, route := range engine.routeList {
+		for _, method := range route.methods {
+			handler := ""
+			if chain := route.chains[method]; len(chain) != 0 {
+				handler = handlerName(chain[len(chain)-1])
+			}
+			name := route.name
+			if name == route.path || strings.HasSuffix(route.path, "<:.*>") && name == route.path[:len(route.path)-5]+"*" {
+				name = ""
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", method, route.path, name, handler)
+		}
+	}
+	return tw.Flush()
+}
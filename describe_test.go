@@ -0,0 +1,61 @@
+package tokay
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func describeLogger(*Context)  {}
+func describeAuth(*Context)    {}
+func describeHandler(*Context) {}
+
+func TestEngineDescribe(t *testing.T) {
+	engine := New()
+	engine.Use(describeLogger)
+	engine.GET("/", describeHandler)
+	api := engine.Group("/api", describeAuth)
+	api.To("GET,POST", "/users", describeHandler).Name("users")
+
+	d := engine.Describe()
+	assert.Equal(t, []string{"github.com/night-codes/tokay.describeLogger"}, d.Middlewares)
+	assert.Equal(t, []GroupDescription{{Path: "/api", Middlewares: []string{"github.com/night-codes/tokay.describeAuth"}}}, d.Groups)
+	assert.Equal(t, []RouteDescription{
+		{Name: "/", Method: "GET", Path: "/", Handlers: []string{
+			"github.com/night-codes/tokay.describeLogger",
+			"github.com/night-codes/tokay.describeHandler",
+		}},
+		{Name: "users", Method: "GET", Path: "/api/users", Handlers: []string{
+			"github.com/night-codes/tokay.describeAuth",
+			"github.com/night-codes/tokay.describeHandler",
+		}},
+		{Name: "users", Method: "POST", Path: "/api/users", Handlers: []string{
+			"github.com/night-codes/tokay.describeAuth",
+			"github.com/night-codes/tokay.describeHandler",
+		}},
+	}, d.Routes)
+	assert.Equal(t, []string{"github.com/night-codes/tokay.describeLogger"}, d.NotFound[:1])
+}
+
+func TestPrintRoutes(t *testing.T) {
+	engine := New()
+	engine.GET("/", describeHandler)
+	engine.To("GET,POST", "/users", describeAuth, describeHandler).Name("users")
+	engine.GET("/static/*", describeHandler)
+
+	var buf bytes.Buffer
+	assert.Nil(t, engine.PrintRoutes(&buf))
+	assert.Equal(t, `METHOD  PATH           NAME   HANDLER
+GET     /                     github.com/night-codes/tokay.describeHandler
+GET     /users         users  github.com/night-codes/tokay.describeHandler
+POST    /users         users  github.com/night-codes/tokay.describeHandler
+GET     /static/<:.*>         github.com/night-codes/tokay.describeHandler
+`, buf.String())
+
+	routes := engine.Routes()
+	assert.Equal(t, []string{
+		"github.com/night-codes/tokay.describeAuth",
+		"github.com/night-codes/tokay.describeHandler",
+	}, routes[1].Handlers["POST"])
+}
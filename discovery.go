@@ -0,0 +1,376 @@
+package tokay
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/night-codes/go-json"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// Service describes the server instance announced to the service registry (see RegisterService).
+	Service struct {
+		// ID is the unique instance ID. Default is "name-host-port".
+		ID string `json:"id"`
+		// Name is the service name.
+		Name string `json:"name"`
+		// Address is the advertised host. Default is the listener IP or the host name
+		// when the server listens on all interfaces.
+		Address string `json:"address"`
+		// Port is the advertised port. Default is the listener port.
+		Port int      `json:"port"`
+		Tags []string `json:"tags,omitempty"`
+		// Meta is the arbitrary instance metadata.
+		Meta map[string]string `json:"meta,omitempty"`
+		// HealthPath is the path of the health check endpoint, like "/health". The registry checks
+		// the instance health with HTTP GET requests when it is set.
+		HealthPath string `json:"health,omitempty"`
+		// HealthInterval is the interval of the health checks. Default is 10 seconds.
+		HealthInterval time.Duration `json:"-"`
+	}
+
+	// ServiceRegistry announces the service instances to the service discovery system.
+	ServiceRegistry interface {
+		Register(service *Service) error
+		Deregister(service *Service) error
+	}
+
+	// ConsulConfig is a struct for specifying Consul registry options.
+	ConsulConfig struct {
+		// Address is the Consul agent HTTP API address. Default is "http://127.0.0.1:8500".
+		Address string
+		// Token is the ACL token.
+		Token string
+		// DeregisterAfter is the time after which the critical instance is deregistered by Consul.
+		// Default is 1 minute.
+		DeregisterAfter time.Duration
+		// Timeout is the Consul API requests timeout. Default is 5 seconds.
+		Timeout time.Duration
+	}
+
+	// EtcdConfig is a struct for specifying etcd registry options.
+	EtcdConfig struct {
+		// Endpoint is the etcd v3 HTTP gateway address. Default is "http://127.0.0.1:2379".
+		Endpoint string
+		// Prefix is the key prefix. Instances are stored at the "prefix/name/id" keys as JSON.
+		// Default is "/services".
+		Prefix string
+		// TTL is the lease TTL: the key disappears when the server stops refreshing it. Default is 30 seconds.
+		TTL time.Duration
+		// Timeout is the etcd API requests timeout. Default is 5 seconds.
+		Timeout time.Duration
+		// Logger receives the lease keepalive errors. Default is the logger of the engine
+		// the registry is used with (see RegisterService).
+		Logger FieldLogger
+	}
+
+	consulRegistry struct {
+		config ConsulConfig
+		client *fasthttp.Client
+	}
+
+	etcdRegistry struct {
+		config EtcdConfig
+		client *fasthttp.Client
+		mu     sync.Mutex
+		leases map[string]*etcdLease // leases by service IDs
+	}
+
+	etcdLease struct {
+		id   string // guarded by the registry mu, changed on the re-registration
+		stop chan struct{}
+	}
+)
+
+// RegisterService announces the server to the service registry once the listener is created
+// (so the port of the ":0" address is known) and deregisters it on Shutdown.
+// Registration errors are logged and don't prevent the server from serving requests.
+//
+//	registry := tokay.NewConsulRegistry(tokay.ConsulConfig{})
+//	engine.RegisterService(registry, tokay.Service{Name: "users", HealthPath: "/health"})
+func (engine *Engine) RegisterService(registry ServiceRegistry, service Service) {
+	if r, ok := registry.(*etcdRegistry); ok && r.config.Logger == nil {
+		r.config.Logger = engine.logger
+	}
+	var mu sync.Mutex
+	var registered *Service
+	engine.onListen = append(engine.onListen, func(addr net.Addr) {
+		s := service.instance(addr)
+		if err := registry.Register(s); err != nil {
+			engine.logger.Error("service registration failed", "service", s.Name, "error", err)
+			return
+		}
+		engine.logger.Info("service registered", "service", s.Name, "id", s.ID)
+		mu.Lock()
+		registered = s
+		mu.Unlock()
+	})
+	engine.OnStop(func(ctx context.Context) error {
+		mu.Lock()
+		s := registered
+		registered = nil
+		mu.Unlock()
+		if s == nil {
+			return nil
+		}
+		engine.logger.Info("service deregistered", "service", s.Name, "id", s.ID)
+		return registry.Deregister(s)
+	})
+}
+
+// instance returns a copy of the service with the default fields taken from the listener address.
+func (s Service) instance(addr net.Addr) *Service {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		if s.Port == 0 {
+			s.Port = tcpAddr.Port
+		}
+		if s.Address == "" && !tcpAddr.IP.IsUnspecified() {
+			s.Address = tcpAddr.IP.String()
+		}
+	}
+	if s.Address == "" {
+		s.Address, _ = os.Hostname()
+	}
+	if s.ID == "" {
+		s.ID = s.Name + "-" + s.Address + "-" + strconv.Itoa(s.Port)
+	}
+	if s.HealthInterval <= 0 {
+		s.HealthInterval = 10 * time.Second
+	}
+	return &s
+}
+
+// healthURL returns the health check URL or "" if the service has no health endpoint.
+func (s *Service) healthURL() string {
+	if s.HealthPath == "" {
+		return ""
+	}
+	return "http://" + net.JoinHostPort(s.Address, strconv.Itoa(s.Port)) + s.HealthPath
+}
+
+// NewConsulRegistry creates the registry of the Consul agent HTTP API.
+func NewConsulRegistry(config ConsulConfig) ServiceRegistry {
+	if config.Address == "" {
+		config.Address = "http://127.0.0.1:8500"
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	if config.DeregisterAfter <= 0 {
+		config.DeregisterAfter = time.Minute
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &consulRegistry{config: config, client: &fasthttp.Client{}}
+}
+
+// Register implements ServiceRegistry.
+func (r *consulRegistry) Register(s *Service) error {
+	registration := map[string]interface{}{
+		"ID":      s.ID,
+		"Name":    s.Name,
+		"Address": s.Address,
+		"Port":    s.Port,
+		"Tags":    s.Tags,
+		"Meta":    s.Meta,
+	}
+	if url := s.healthURL(); url != "" {
+		registration["Check"] = map[string]string{
+			"HTTP":                           url,
+			"Interval":                       s.HealthInterval.String(),
+			"DeregisterCriticalServiceAfter": r.config.DeregisterAfter.String(),
+		}
+	}
+	body, err := json.Marshal(registration)
+	if err != nil {
+		return err
+	}
+	return r.do("/v1/agent/service/register", body)
+}
+
+// Deregister implements ServiceRegistry.
+func (r *consulRegistry) Deregister(s *Service) error {
+	return r.do("/v1/agent/service/deregister/"+s.ID, nil)
+}
+
+func (r *consulRegistry) do(path string, body []byte) error {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.Header.SetMethod("PUT")
+	req.SetRequestURI(r.config.Address + path)
+	if r.config.Token != "" {
+		req.Header.Set("X-Consul-Token", r.config.Token)
+	}
+	req.SetBody(body)
+	if err := r.client.DoTimeout(req, resp, r.config.Timeout); err != nil {
+		return err
+	}
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("consul: %d %s", resp.StatusCode(), resp.Body())
+	}
+	return nil
+}
+
+// NewEtcdRegistry creates the registry of the etcd v3 HTTP gateway. The instance keys are attached
+// to the lease which is refreshed while the server is registered.
+func NewEtcdRegistry(config EtcdConfig) ServiceRegistry {
+	if config.Endpoint == "" {
+		config.Endpoint = "http://127.0.0.1:2379"
+	}
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	if config.Prefix == "" {
+		config.Prefix = "/services"
+	}
+	if config.TTL < time.Second {
+		config.TTL = 30 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &etcdRegistry{config: config, client: &fasthttp.Client{}, leases: make(map[string]*etcdLease)}
+}
+
+// Register implements ServiceRegistry.
+func (r *etcdRegistry) Register(s *Service) error {
+	id, err := r.register(s)
+	if err != nil {
+		return err
+	}
+	lease := &etcdLease{id: id, stop: make(chan struct{})}
+	r.mu.Lock()
+	r.leases[s.ID] = lease
+	r.mu.Unlock()
+	go r.keepAlive(s, lease)
+	return nil
+}
+
+// register grants the lease and puts the instance key attached to it.
+func (r *etcdRegistry) register(s *Service) (leaseID string, err error) {
+	var grant struct {
+		ID string `json:"ID"`
+	}
+	if err := r.do("/v3/lease/grant", map[string]interface{}{"TTL": int64(r.config.TTL / time.Second)}, &grant); err != nil {
+		return "", err
+	}
+	if grant.ID == "" {
+		return "", errors.New("etcd: no lease ID granted")
+	}
+	value, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	if err = r.do("/v3/kv/put", map[string]interface{}{
+		"key":   base64.StdEncoding.EncodeToString([]byte(r.key(s))),
+		"value": base64.StdEncoding.EncodeToString(value),
+		"lease": grant.ID,
+	}, nil); err != nil {
+		return "", err
+	}
+	return grant.ID, nil
+}
+
+// Deregister implements ServiceRegistry.
+func (r *etcdRegistry) Deregister(s *Service) error {
+	r.mu.Lock()
+	lease := r.leases[s.ID]
+	delete(r.leases, s.ID)
+	var id string
+	if lease != nil {
+		id = lease.id
+		close(lease.stop)
+	}
+	r.mu.Unlock()
+	if lease == nil {
+		return nil
+	}
+	return r.do("/v3/lease/revoke", map[string]interface{}{"ID": id}, nil)
+}
+
+// key returns the etcd key of the service instance.
+func (r *etcdRegistry) key(s *Service) string {
+	return strings.TrimSuffix(r.config.Prefix, "/") + "/" + s.Name + "/" + s.ID
+}
+
+// keepAlive refreshes the lease every third of the TTL until the lease is stopped. The instance
+// is registered again when the lease has expired (etcd was unavailable longer than the TTL).
+func (r *etcdRegistry) keepAlive(s *Service, lease *etcdLease) {
+	logger := r.config.Logger
+	if logger == nil {
+		logger = &stdLogger{}
+	}
+	ticker := time.NewTicker(r.config.TTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-lease.stop:
+			return
+		case <-ticker.C:
+		}
+		r.mu.Lock()
+		id := lease.id
+		r.mu.Unlock()
+		var keepalive struct {
+			Result struct {
+				TTL string `json:"TTL"`
+			} `json:"result"`
+		}
+		if err := r.do("/v3/lease/keepalive", map[string]interface{}{"ID": id}, &keepalive); err != nil {
+			logger.Warn("etcd lease keepalive failed", "service", s.Name, "id", s.ID, "error", err)
+			continue
+		}
+		if ttl, _ := strconv.Atoi(keepalive.Result.TTL); ttl > 0 {
+			continue
+		}
+		id, err := r.register(s)
+		if err != nil {
+			logger.Error("service re-registration failed", "service", s.Name, "id", s.ID, "error", err)
+			continue
+		}
+		r.mu.Lock()
+		select {
+		case <-lease.stop:
+			// deregistered meanwhile
+			r.mu.Unlock()
+			r.do("/v3/lease/revoke", map[string]interface{}{"ID": id}, nil)
+			return
+		default:
+			lease.id = id
+		}
+		r.mu.Unlock()
+		logger.Info("service re-registered", "service", s.Name, "id", s.ID)
+	}
+}
+
+func (r *etcdRegistry) do(path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+	req.Header.SetMethod("POST")
+	req.SetRequestURI(r.config.Endpoint + path)
+	req.SetBody(body)
+	if err = r.client.DoTimeout(req, resp, r.config.Timeout); err != nil {
+		return err
+	}
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("etcd: %d %s", resp.StatusCode(), resp.Body())
+	}
+	if response != nil {
+		return json.Unmarshal(resp.Body(), response)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package tokay
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/night-codes/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// recordedRequests collects the requests received by the fake registry server.
+type recordedRequests struct {
+	sync.Mutex
+	requests []string
+	bodies   map[string]string
+}
+
+func (r *recordedRequests) handler(response string) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		r.Lock()
+		r.requests = append(r.requests, string(ctx.Method())+" "+string(ctx.Path()))
+		r.bodies[string(ctx.Path())] = string(ctx.PostBody())
+		r.Unlock()
+		ctx.SetBodyString(response)
+	}
+}
+
+func TestServiceInstance(t *testing.T) {
+	s := Service{Name: "users", HealthPath: "/health"}.instance(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8080})
+	assert.Equal(t, "10.0.0.1", s.Address)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "users-10.0.0.1-8080", s.ID)
+	assert.Equal(t, "http://10.0.0.1:8080/health", s.healthURL())
+	assert.Equal(t, 10*time.Second, s.HealthInterval)
+
+	s = Service{Name: "users", Address: "users.local", ID: "u1"}.instance(&net.TCPAddr{IP: net.IPv4zero, Port: 80})
+	assert.Equal(t, "users.local", s.Address)
+	assert.Equal(t, "u1", s.ID)
+	assert.Equal(t, "", s.healthURL())
+}
+
+func TestConsulRegistry(t *testing.T) {
+	rec := &recordedRequests{bodies: make(map[string]string)}
+	target, stop := runUpstream(t, rec.handler(""))
+	defer stop()
+
+	registry := NewConsulRegistry(ConsulConfig{Address: target + "/"})
+	s := Service{Name: "users", HealthPath: "/health", Tags: []string{"v1"}}.instance(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 8080})
+	assert.Nil(t, registry.Register(s))
+	assert.Nil(t, registry.Deregister(s))
+
+	assert.Equal(t, []string{
+		"PUT /v1/agent/service/register",
+		"PUT /v1/agent/service/deregister/users-10.0.0.1-8080",
+	}, rec.requests)
+	var registration struct {
+		ID    string
+		Port  int
+		Tags  []string
+		Check map[string]string
+	}
+	assert.Nil(t, json.Unmarshal([]byte(rec.bodies["/v1/agent/service/register"]), &registration))
+	assert.Equal(t, "users-10.0.0.1-8080", registration.ID)
+	assert.Equal(t, 8080, registration.Port)
+	assert.Equal(t, []string{"v1"}, registration.Tags)
+	assert.Equal(t, "http://10.0.0.1:8080/health", registration.Check["HTTP"])
+	assert.Equal(t, "10s", registration.Check["Interval"])
+}
+
+func TestEtcdRegistry(t *testing.T) {
+	rec := &recordedRequests{bodies: make(map[string]string)}
+	target, stop := runUpstream(t, rec.handler(`{"ID":"42","TTL":"30"}`))
+	defer stop()
+
+	registry := NewEtcdRegistry(EtcdConfig{Endpoint: target})
+	s := Service{Name: "users", ID: "u1", Address: "10.0.0.1", Port: 8080}.instance(nil)
+	assert.Nil(t, registry.Register(s))
+	assert.Nil(t, registry.Deregister(s))
+	assert.Nil(t, registry.Deregister(s))
+
+	assert.Equal(t, []string{"POST /v3/lease/grant", "POST /v3/kv/put", "POST /v3/lease/revoke"}, rec.requests)
+	var put struct {
+		Key, Value, Lease string
+	}
+	assert.Nil(t, json.Unmarshal([]byte(rec.bodies["/v3/kv/put"]), &put))
+	key, _ := base64.StdEncoding.DecodeString(put.Key)
+	assert.Equal(t, "/services/users/u1", string(key))
+	assert.Equal(t, "42", put.Lease)
+	assert.JSONEq(t, `{"ID":"42"}`, rec.bodies["/v3/lease/revoke"])
+}
+
+func TestEtcdRegistryLostLease(t *testing.T) {
+	var mu sync.Mutex
+	var grants int
+	var requests []string
+	target, stop := runUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests = append(requests, string(ctx.Path()))
+		switch string(ctx.Path()) {
+		case "/v3/lease/grant":
+			grants++
+			ctx.SetBodyString(`{"ID":"` + strconv.Itoa(grants) + `","TTL":"1"}`)
+		case "/v3/lease/keepalive":
+			if strings.Contains(string(ctx.PostBody()), `"1"`) {
+				ctx.SetBodyString(`{"result":{"ID":"1"}}`) // expired
+			} else {
+				ctx.SetBodyString(`{"result":{"ID":"2","TTL":"1"}}`)
+			}
+		}
+	})
+	defer stop()
+
+	logger := &eventLogger{}
+	registry := NewEtcdRegistry(EtcdConfig{Endpoint: target, TTL: time.Second, Logger: logger})
+	s := Service{Name: "users", ID: "u1", Address: "10.0.0.1", Port: 8080}.instance(nil)
+	assert.Nil(t, registry.Register(s))
+	for i := 0; i < 100; i++ {
+		logger.Lock()
+		n := len(logger.events)
+		logger.Unlock()
+		if n != 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Nil(t, registry.Deregister(s))
+
+	logger.Lock()
+	assert.Equal(t, []string{"service re-registered service users id u1"}, logger.events)
+	logger.Unlock()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, grants)
+	assert.Equal(t, []string{"/v3/lease/grant", "/v3/kv/put", "/v3/lease/keepalive", "/v3/lease/grant", "/v3/kv/put"}, requests[:5])
+	assert.Equal(t, "/v3/lease/revoke", requests[len(requests)-1])
+}
+
+// testRegistry records the registered services.
+type testRegistry struct {
+	sync.Mutex
+	registered, deregistered []*Service
+}
+
+func (r *testRegistry) Register(s *Service) error {
+	r.Lock()
+	defer r.Unlock()
+	r.registered = append(r.registered, s)
+	return nil
+}
+
+func (r *testRegistry) Deregister(s *Service) error {
+	r.Lock()
+	defer r.Unlock()
+	r.deregistered = append(r.deregistered, s)
+	return nil
+}
+
+func TestRegisterService(t *testing.T) {
+	engine := New(&Config{Logger: &testLogger{}})
+	registry := &testRegistry{}
+	engine.RegisterService(registry, Service{Name: "users"})
+	conn, err := runTestServer(engine)
+	if !assert.Nil(t, err) {
+		return
+	}
+	conn.Close()
+	assert.Nil(t, engine.Shutdown(context.Background()))
+
+	registry.Lock()
+	defer registry.Unlock()
+	if assert.Len(t, registry.registered, 1) {
+		assert.Equal(t, engine.Port(), registry.registered[0].Port)
+		assert.Equal(t, "127.0.0.1", registry.registered[0].Address)
+	}
+	assert.Equal(t, registry.registered, registry.deregistered)
+}
@@ -0,0 +1,194 @@
+package tokay
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// dnsCacheTTL is the lifetime of the cached DNS lookups.
+	dnsCacheTTL = 10 * time.Minute
+	// dnsTimeout is the timeout of the DNS lookup.
+	dnsTimeout = 2 * time.Second
+	// maxDNSCacheEntries is the maximum number of cached DNS lookups.
+	maxDNSCacheEntries = 10000
+)
+
+type (
+	// resolver looks up the host names and addresses (net.DefaultResolver).
+	resolver interface {
+		LookupAddr(ctx context.Context, addr string) ([]string, error)
+		LookupHost(ctx context.Context, host string) ([]string, error)
+	}
+
+	// dnsCache keeps the results of the DNS lookups.
+	dnsCache struct {
+		sync.Mutex
+		resolver resolver
+		entries  map[string]*dnsEntry
+	}
+
+	// dnsEntry is a cached DNS lookup result.
+	dnsEntry struct {
+		names   []string
+		err     error
+		expires time.Time
+	}
+
+	// CrawlerConfig is a struct for specifying VerifyCrawlers middleware options.
+	CrawlerConfig struct {
+		// Crawlers are the domains of the crawler hosts by the User-Agent tokens.
+		// Default is DefaultCrawlers.
+		Crawlers map[string][]string
+		// TrustForwardedFor verifies the client IP from the X-Forwarded-For and X-Real-Ip headers
+		// (see Context.ClientIP) instead of the remote address. Enable it only behind
+		// a reverse proxy overwriting these headers.
+		TrustForwardedFor bool
+	}
+)
+
+// DefaultCrawlers are the host domains of the well-known search engine crawlers by their User-Agent tokens.
+var DefaultCrawlers = map[string][]string{
+	"Googlebot":   {"googlebot.com", "google.com", "googleusercontent.com"},
+	"Bingbot":     {"search.msn.com"},
+	"Applebot":    {"applebot.apple.com"},
+	"YandexBot":   {"yandex.ru", "yandex.net", "yandex.com"},
+	"Baiduspider": {"baidu.com", "baidu.jp"},
+	"DuckDuckBot": {"duckduckgo.com"},
+}
+
+func newDNSCache() *dnsCache {
+	return &dnsCache{resolver: net.DefaultResolver, entries: make(map[string]*dnsEntry)}
+}
+
+// lookup returns the cached result of the lookup of the key.
+func (cache *dnsCache) lookup(key string, fn func(ctx context.Context) ([]string, error)) ([]string, error) {
+	now := time.Now()
+	cache.Lock()
+	entry := cache.entries[key]
+	cache.Unlock()
+	if entry != nil && now.Before(entry.expires) {
+		return entry.names, entry.err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+	names, err := fn(ctx)
+	if dnsErr, ok := err.(*net.DNSError); ok && (dnsErr.IsTimeout || dnsErr.IsTemporary) {
+		return nil, err
+	}
+
+	cache.Lock()
+	defer cache.Unlock()
+	if len(cache.entries) >= maxDNSCacheEntries {
+		for k, e := range cache.entries {
+			if now.After(e.expires) {
+				delete(cache.entries, k)
+			}
+		}
+		if len(cache.entries) >= maxDNSCacheEntries {
+			return names, err
+		}
+	}
+	cache.entries[key] = &dnsEntry{names: names, err: err, expires: now.Add(dnsCacheTTL)}
+	return names, err
+}
+
+// reverse returns the host names of the IP address without the trailing dots.
+func (cache *dnsCache) reverse(ip string) ([]string, error) {
+	return cache.lookup("addr "+ip, func(ctx context.Context) ([]string, error) {
+		names, err := cache.resolver.LookupAddr(ctx, ip)
+		for i, name := range names {
+			names[i] = strings.TrimSuffix(name, ".")
+		}
+		return names, err
+	})
+}
+
+// forwardConfirmed reports whether the IP address has the host name within the domains
+// resolving back to the same address.
+func (cache *dnsCache) forwardConfirmed(ip string, domains []string) bool {
+	names, _ := cache.reverse(ip)
+	for _, name := range names {
+		if !inDomains(name, domains) {
+			continue
+		}
+		addrs, _ := cache.lookup("host "+name, func(ctx context.Context) ([]string, error) {
+			return cache.resolver.LookupHost(ctx, name)
+		})
+		for _, addr := range addrs {
+			if sameIP(addr, ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inDomains reports whether the host name is one of the domains or their subdomain.
+func inDomains(name string, domains []string) bool {
+	name = strings.ToLower(name)
+	for _, domain := range domains {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameIP compares the IP addresses in their canonical forms.
+func sameIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	return ipA != nil && ipA.Equal(ipB)
+}
+
+// ReverseDNS returns the host name of the client IP (see ClientIP) by the reverse DNS lookup.
+// The lookups are cached for 10 minutes.
+func (c *Context) ReverseDNS() (string, error) {
+	names, err := c.engine.dns.reverse(c.ClientIP())
+	if len(names) == 0 {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// VerifyCrawlers returns a middleware rejecting with 403 Forbidden the requests claiming to be
+// from the crawlers (by the User-Agent header) which IP addresses are not verified by the
+// forward-confirmed reverse DNS: the host name of the IP must be within the crawler domains
+// and must resolve back to the same IP. The lookups are cached for 10 minutes.
+//
+//	engine.GET("/catalog", tokay.VerifyCrawlers(), catalogHandler)
+func VerifyCrawlers(config ...CrawlerConfig) Handler {
+	var cfg CrawlerConfig
+	if len(config) != 0 {
+		cfg = config[0]
+	}
+	if cfg.Crawlers == nil {
+		cfg.Crawlers = DefaultCrawlers
+	}
+	tokens := make(map[string][]string, len(cfg.Crawlers))
+	for token, domains := range cfg.Crawlers {
+		tokens[strings.ToLower(token)] = domains
+	}
+
+	return func(c *Context) {
+		ua := strings.ToLower(string(c.Request.Header.UserAgent()))
+		for token, domains := range tokens {
+			if !strings.Contains(ua, token) {
+				continue
+			}
+			ip := c.RemoteIP().String()
+			if cfg.TrustForwardedFor {
+				ip = c.ClientIP()
+			}
+			if !c.engine.dns.forwardConfirmed(ip, domains) {
+				c.AbortWithError(http.StatusForbidden, nil)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package tokay
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testResolver struct {
+	addrs, hosts map[string][]string
+	lookups      int
+}
+
+func (r *testResolver) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	r.lookups++
+	if names, ok := r.addrs[addr]; ok {
+		return names, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+func (r *testResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.lookups++
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func newTestResolver() *testResolver {
+	return &testResolver{
+		addrs: map[string][]string{
+			"66.249.66.1": {"crawl-66-249-66-1.googlebot.com."},
+			"10.0.0.1":    {"fake.googlebot.com.evil.com."},
+			"10.0.0.2":    {"spoofed.googlebot.com."},
+		},
+		hosts: map[string][]string{
+			"crawl-66-249-66-1.googlebot.com": {"66.249.66.1"},
+			"spoofed.googlebot.com":           {"66.249.66.2"},
+		},
+	}
+}
+
+func TestContextReverseDNS(t *testing.T) {
+	engine := New()
+	resolver := newTestResolver()
+	engine.dns.resolver = resolver
+	var host string
+	var err error
+	engine.GET("/", func(c *Context) {
+		host, err = c.ReverseDNS()
+	})
+
+	ctx := serveTestRequest(engine, "/", withHeader("X-Forwarded-For", "66.249.66.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "crawl-66-249-66-1.googlebot.com", host)
+	engine.HandleRequest(ctx)
+	assert.Equal(t, 1, resolver.lookups)
+
+	ctx.Request.Header.Set("X-Forwarded-For", "192.0.2.1")
+	engine.HandleRequest(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, "", host)
+}
+
+func TestVerifyCrawlers(t *testing.T) {
+	engine := New()
+	engine.dns.resolver = newTestResolver()
+	engine.GET("/trusted", VerifyCrawlers(CrawlerConfig{TrustForwardedFor: true}), func(c *Context) {
+		c.String(200, "ok")
+	})
+	engine.GET("/remote", VerifyCrawlers(), func(c *Context) {
+		c.String(200, "ok")
+	})
+
+	request := func(uri, ip, ua string) int {
+		return serveTestRequest(engine, uri, withHeader("X-Forwarded-For", ip), withHeader("User-Agent", ua)).Response.StatusCode()
+	}
+
+	googlebot := "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+	assert.Equal(t, 200, request("/trusted", "66.249.66.1", googlebot))
+	assert.Equal(t, 403, request("/trusted", "10.0.0.1", googlebot))
+	assert.Equal(t, 403, request("/trusted", "10.0.0.2", googlebot))
+	assert.Equal(t, 403, request("/trusted", "192.0.2.1", googlebot))
+	assert.Equal(t, 200, request("/trusted", "192.0.2.1", "Mozilla/5.0 (X11; Linux x86_64)"))
+	assert.Equal(t, 403, request("/remote", "66.249.66.1", googlebot))
+}
@@ -1,18 +1,21 @@
 package tokay
 
 import (
+	"context"
+	"crypto/cipher"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"html/template"
+	"math/rand"
 	"net"
 	"net/http"
 	"os"
-	"reflect"
-	"runtime"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	render "github.com/night-codes/tokay-render"
@@ -39,8 +42,11 @@ type (
 		Render Render
 		// AppEngine usage marker
 		AppEngine bool
-		// Print debug messages to log
+		// Print debug messages to log and poison the contexts at the end of their requests,
+		// so the use of a context captured in a goroutine panics (see Context.Copy)
 		Debug bool
+		// HideBanner demotes the "server starting" and "server started" log events to the debug level
+		HideBanner bool
 
 		// DebugFunc is a middleware function
 		DebugFunc func(*Context, time.Duration)
@@ -58,14 +64,129 @@ type (
 		// and 307 for all other request methods.
 		RedirectTrailingSlash bool
 
+		// Enables the automatic responses to the OPTIONS requests with the Allow header listing the
+		// methods of the matched path (enabled by default). When disabled the OPTIONS requests without
+		// the own routes get 405 and OPTIONS is listed in the Allow headers of the routes with it only.
+		// It should be set before the routes are registered.
+		HandleOPTIONS bool
+
+		// Enables the automatic registration of the GET handlers for the HEAD requests to the routes
+		// without the own HEAD handlers. It should be set before the routes are registered.
+		HandleHEAD bool
+
+		// Enables the recording of the route matching decisions returned by Context.MatchTrace,
+		// for debugging the 404 responses in the development. It slows down the routing.
+		TraceRouting bool
+
 		pool             sync.Pool
 		routes           map[string]*Route
+		routeList        []*Route                  // routes in the registration order
+		candidates       map[string]*routeHandlers // route handlers by method and path pattern
+		groups           []*RouterGroup            // groups in the creation order
 		stores           storesMap
+		allowedStore     *store                     // allowed methods by route path patterns
+		allowed          map[string]*allowedMethods // allowed methods by route path
 		maxParams        int
 		notFound         []Handler
 		notFoundHandlers []Handler
+		// notFoundFor are the NotFound handlers by the methods (see NotFoundFor)
+		notFoundFor         map[string][]Handler
+		notFoundHandlersFor map[string][]Handler
 		// maxGracefulWaitTime is 'graceful shutdown' waiting duration
 		maxGracefulWaitTime time.Duration
+		shutdownProgress    func(openConns uint64, remaining time.Duration)
+		// addr, certFile and keyFile are used by Start
+		addr, certFile, keyFile string
+		// TLS certificates options
+		tlsReloadInterval time.Duration
+		getCertificate    func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+		certs             *certStore
+		sniCertificates   map[string]CertKeyPair
+		// mutual TLS options
+		clientAuth   tls.ClientAuthType
+		clientCAs    *x509.CertPool
+		clientCAFile string
+		// allowedHosts is the list of the Host header values allowed by AllowedHosts
+		allowedHosts []string
+		// panicReporters are registered by OnPanic
+		panicReporters []PanicReporter
+		// logger is the structured logger
+		logger FieldLogger
+		// events are the subscribers of the engine events (see On, Emit)
+		events eventBus
+		// lifecycle hooks (see OnStart, OnStop)
+		onStart []func() error
+		onStop  []func(ctx context.Context) error
+		// lifecycleMu guards onStop registered by the middlewares while serving
+		lifecycleMu sync.Mutex
+		// onReload are called by Reload
+		onReload []func() error
+		// pre and post are the handlers called before the routing and after the handlers chain (see Pre, Post)
+		pre, post []Handler
+		// onAbort are called when the handlers chain is aborted (see OnAbort)
+		onAbort []func(c *Context)
+		// onWriteError are called when the response cannot be written (see OnWriteError)
+		onWriteError []func(e *WriteError)
+		// onListen are called when the listener is created (see RegisterService)
+		onListen []func(addr net.Addr)
+		// stats are the request counters (see Stats)
+		stats *statsCounters
+		// listeners are the GracefulListeners of the running servers, closed by Close
+		listeners   map[*GracefulListener]struct{}
+		listenersMu sync.Mutex
+		// trackConns installs the connection state hook of the listeners once
+		trackConns sync.Once
+		// metrics is the request metrics sink, may be nil
+		metrics MetricsSink
+		// enforcer is used by the Authorize middlewares
+		enforcer PolicyEnforcer
+		// redirects is the *redirectTable installed by Redirects
+		redirects atomic.Value
+		// notFoundResolvers are registered by NotFoundResolvers
+		notFoundResolvers []*notFoundResolver
+		// draining becomes non-zero on Close: responses get the "Connection: close" header
+		draining int32
+		// websockets are the active WebSocket connections closed by Shutdown
+		websockets        wsRegistry
+		wsShutdownTimeout time.Duration
+		// cache keeps the responses of the routes with CacheFor
+		cache *responseCache
+		// decoders are the request body decoders by the content types (see RegisterDecoder)
+		decoders map[string]DecoderFunc
+		// binders are the request binders by the content types (see RegisterBinder)
+		binders map[string]BinderFunc
+		// validator validates the bound structs instead of govalidator (see SetValidator)
+		validator ValidatorFunc
+		// env is the environment name (see SetEnv)
+		env string
+		// clock and randSource back Context.Now and Context.Rand (see SetClock, SetRandSource)
+		clock      func() time.Time
+		randSource func() rand.Source
+		// trustedProxies are the networks of the proxies whose X-Forwarded-* headers are honored (see TrustProxies)
+		trustedProxies []*net.IPNet
+		// stateKeys encrypt and decrypt the state tokens, the first one encrypts (see SetStateKeys)
+		stateKeys []cipher.AEAD
+		// panicHandler renders the responses after the recovered panics (see PanicHandler)
+		panicHandler PanicHandlerFunc
+		// disabledMethods are the HTTP methods rejected before routing (see DisableMethods)
+		disabledMethods []string
+		// basePath is the path prefix the engine is mounted at (see BasePath)
+		basePath string
+		// memo keeps the pages of the routes with Memoize
+		memo *pageMemo
+		// dns caches the DNS lookups of ReverseDNS and VerifyCrawlers
+		dns *dnsCache
+		// variants selects the device template variants (see TemplateVariants)
+		variants *templateVariants
+		// mimeTypes are the media types by the file extensions (see AddMIMEType)
+		mimeTypes map[string]string
+		// jobs is the job status route registered by JobStatus
+		jobs *jobStatusRoute
+		// banner is the message of the "server started" event
+		banner string
+		// bound is the address of the listener (see listening), addrMu guards it and the banner
+		addrMu sync.RWMutex
+		bound  net.Addr
 	}
 
 	// Config is a struct for specifying configuration options for the tokay.Engine object.
@@ -74,6 +195,14 @@ type (
 		Debug bool
 		// DebugFunc is callback function that calls after context
 		DebugFunc func(*Context, time.Duration)
+		// HideBanner demotes the "server starting" and "server started" log events to the debug level
+		HideBanner bool
+		// Env is the environment name, like "production" (see Engine.SetEnv).
+		// Defaults to the TOKAY_ENV environment variable or "development".
+		Env string
+		// ServerHeader is the Server response header value. The header is not sent when it is empty
+		// (the default), so the server software is not disclosed.
+		ServerHeader string
 		// Extensions to parse template files from. Defaults to [".html"].
 		TemplatesExtensions []string
 		// Directories to load templates. Default is ["templates"].
@@ -85,10 +214,64 @@ type (
 		// Funcs is a slice of FuncMaps to apply to the template upon compilation. This is useful for helper functions. Defaults to [].
 		TemplatesFuncs template.FuncMap
 		// MaxGracefulWaitTime is 'graceful shutdown' waiting duration
+		//
+		// Deprecated: use GracefulTimeout.
 		MaxGracefulWaitTime time.Duration
+		// GracefulTimeout is the maximum time Close (and Shutdown) waits for the open connections
+		// to be closed. Default is 10 seconds.
+		GracefulTimeout time.Duration
+		// ShutdownProgress is called every second while the graceful shutdown waits for the open
+		// connections, with the number of open connections and the time left until the GracefulTimeout.
+		ShutdownProgress func(openConns uint64, remaining time.Duration)
+		// Logger is the structured logger. Default logger writes to stdout/stderr.
+		Logger FieldLogger
+		// Addr is the TCP address to listen by engine.Start(), for example ":8080".
+		Addr string
+		// CertFile and KeyFile are paths to TLS certificate and key files.
+		// engine.Start() serves HTTPS requests when they are set.
+		CertFile, KeyFile string
+		// ReadTimeout is the amount of time allowed to read the full request including body.
+		ReadTimeout time.Duration
+		// WriteTimeout is the maximum duration before timing out writes of the response.
+		WriteTimeout time.Duration
+		// IdleTimeout is the maximum amount of time to wait for the next request when keep-alive is enabled.
+		IdleTimeout time.Duration
+		// MaxRequestBodySize is the maximum request body size. Default is fasthttp.DefaultMaxRequestBodySize.
+		MaxRequestBodySize int
+		// TLSReloadInterval enables checking of the TLS certificate and key files for changes
+		// with the given interval, so renewed certificates are picked up without restart.
+		TLSReloadInterval time.Duration
+		// GetCertificate returns TLS certificate for the client hello. When set, RunTLS
+		// uses it instead of certificate files (which may be empty in this case).
+		GetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+		// Certificates are TLS certificates selected by the client SNI server name. Keys are host names
+		// like "example.com" or wildcards like "*.example.com". Certificate files passed to RunTLS
+		// are used for the other host names.
+		Certificates map[string]CertKeyPair
+		// ClientAuth is the policy for TLS client certificates authentication (mutual TLS).
+		ClientAuth tls.ClientAuthType
+		// ClientCAs is the pool of certificate authorities used to verify client certificates.
+		ClientCAs *x509.CertPool
+		// ClientCAFile is the path to PEM file with certificate authorities added to a copy of ClientCAs
+		// (copying the pool requires Go 1.19).
+		ClientCAFile string
+		// WebsocketShutdownTimeout is the time Shutdown waits for the WebSocket connections to close
+		// after sending them the close frames. Default is 5 seconds.
+		WebsocketShutdownTimeout time.Duration
+		// ConnState is called when a client connection changes state (new, active, idle, hijacked, closed).
+		// It is handy for the connection-level metrics and debugging of connection leaks.
+		ConnState func(conn net.Conn, state ConnState)
+		// MetricsSink receives the request count and duration metrics tagged with route, method and status
+		// (see NewStatsD).
+		MetricsSink MetricsSink
 	}
 )
 
+// CertKeyPair is a pair of TLS certificate and key file paths.
+type CertKeyPair struct {
+	CertFile, KeyFile string
+}
+
 var (
 	// AppEngine usage marker
 	AppEngine bool
@@ -113,9 +296,13 @@ func New(config ...*Config) *Engine {
 	var cfgDebug bool
 	var maxGracefulWaitTime = 10 * time.Second
 	var cfgDebugFunc func(*Context, time.Duration)
+	var cfg = &Config{}
 	rCfg := &render.Config{}
 	if len(config) != 0 && config[0] != nil {
-		if config[0].MaxGracefulWaitTime != 0 {
+		cfg = config[0]
+		if config[0].GracefulTimeout != 0 {
+			maxGracefulWaitTime = config[0].GracefulTimeout
+		} else if config[0].MaxGracefulWaitTime != 0 {
 			maxGracefulWaitTime = config[0].MaxGracefulWaitTime
 		}
 		if len(config[0].TemplatesDirs) != 0 {
@@ -137,19 +324,58 @@ func New(config ...*Config) *Engine {
 		AppEngine:             AppEngine,
 		routes:                make(map[string]*Route),
 		stores:                *newStoresMap(),
+		allowedStore:          newStore(),
+		allowed:               make(map[string]*allowedMethods),
+		stats:                 &statsCounters{},
+		cache:                 newResponseCache(),
+		memo:                  newPageMemo(),
+		dns:                   newDNSCache(),
+		mimeTypes:             newMIMETypes(),
+		candidates:            make(map[string]*routeHandlers),
 		Render:                r,
 		RedirectTrailingSlash: true,
+		HandleOPTIONS:         true,
 		Debug:                 cfgDebug,
+		HideBanner:            cfg.HideBanner,
 		DebugFunc:             cfgDebugFunc,
-		Server:                &fasthttp.Server{},
-		maxGracefulWaitTime:   maxGracefulWaitTime,
+		Server: &fasthttp.Server{
+			Name:                  cfg.ServerHeader,
+			NoDefaultServerHeader: cfg.ServerHeader == "",
+			ReadTimeout:           cfg.ReadTimeout,
+			WriteTimeout:          cfg.WriteTimeout,
+			IdleTimeout:           cfg.IdleTimeout,
+			MaxRequestBodySize:    cfg.MaxRequestBodySize,
+			ConnState:             cfg.ConnState,
+		},
+		maxGracefulWaitTime: maxGracefulWaitTime,
+		shutdownProgress:    cfg.ShutdownProgress,
+		addr:                cfg.Addr,
+		certFile:            cfg.CertFile,
+		keyFile:             cfg.KeyFile,
+		tlsReloadInterval:   cfg.TLSReloadInterval,
+		getCertificate:      cfg.GetCertificate,
+		sniCertificates:     cfg.Certificates,
+		clientAuth:          cfg.ClientAuth,
+		clientCAs:           cfg.ClientCAs,
+		clientCAFile:        cfg.ClientCAFile,
+		metrics:             cfg.MetricsSink,
+		wsShutdownTimeout:   cfg.WebsocketShutdownTimeout,
+		env:                 detectEnv(cfg.Env),
 		Close: func() error {
 			return errors.New("server is not runned")
 		},
 	}
+	if engine.wsShutdownTimeout <= 0 {
+		engine.wsShutdownTimeout = 5 * time.Second
+	}
+	engine.logger = cfg.Logger
+	if engine.logger == nil {
+		engine.logger = &stdLogger{engine: engine}
+	}
 	engine.RouterGroup = *newRouteGroup("", engine, make([]Handler, 0))
 	engine.NotFound(MethodNotAllowedHandler, NotFoundHandler)
 	engine.pool.New = func() interface{} {
+		atomic.AddUint64(&engine.stats.contexts, 1)
 		return &Context{
 			pvalues: make([]string, engine.maxParams),
 			engine:  engine,
@@ -158,33 +384,102 @@ func New(config ...*Config) *Engine {
 	return engine
 }
 
-func runmsg(addr string, ec chan error, message string) (err error) {
-	if message != "" {
-		select {
-		case err = <-ec:
-			return
-		case <-time.After(time.Second / 4):
-			if strings.Contains(message, "%s") {
-				fmt.Printf(message+"\n", addr)
-			} else {
-				fmt.Println(message)
-			}
+// run calls the OnStart functions and serves requests, logging the lifecycle events. The banner
+// is the message of the "started" event logged once the listener is created (see listening).
+func (engine *Engine) run(network, addr, banner string, serve func() error) error {
+	if err := engine.start(); err != nil {
+		return err
+	}
+	if engine.Server.Handler == nil {
+		// the running server of the engine serving on the other address reads it
+		engine.Server.Handler = engine.HandleRequest
+	}
+	engine.addrMu.Lock()
+	engine.banner = banner
+	engine.addrMu.Unlock()
+	engine.bannerLogger()("server starting", "network", network, "addr", addr)
+	err := serve()
+	if engine.Addr() != nil {
+		if err != nil {
+			engine.logger.Error("server stopped", "error", err)
+		} else {
+			engine.logger.Info("server stopped")
 		}
 	}
-	err = <-ec
-	return
+	return err
+}
+
+// listening remembers the address of the created listener and logs the "started" event.
+func (engine *Engine) listening(ln net.Listener) {
+	addr := ln.Addr()
+	engine.addrMu.Lock()
+	engine.bound = addr
+	msg := engine.banner
+	engine.addrMu.Unlock()
+
+	if msg == "" {
+		msg = "server started"
+	} else if strings.Contains(msg, "%s") {
+		msg = fmt.Sprintf(msg, addr)
+	}
+	keyvals := []interface{}{"network", addr.Network(), "addr", addr.String()}
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		keyvals = append(keyvals, "port", tcpAddr.Port)
+	}
+	engine.bannerLogger()(msg, keyvals...)
+	for _, fn := range engine.onListen {
+		fn(addr)
+	}
+}
+
+// Addr returns the address of the server listener or nil if the server is not started yet.
+// It is handy to learn the port chosen by the system when the server is run on ":0".
+func (engine *Engine) Addr() net.Addr {
+	engine.addrMu.RLock()
+	defer engine.addrMu.RUnlock()
+	return engine.bound
+}
+
+// Port returns the TCP port of the server listener or 0 if the server is not started yet
+// (or listens on a unix socket).
+func (engine *Engine) Port() int {
+	if addr, ok := engine.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return 0
+}
+
+// bannerLogger returns the logging function of the startup events: they are debug messages
+// when the banner is hidden.
+func (engine *Engine) bannerLogger() func(msg string, keyvals ...interface{}) {
+	engine.addrMu.RLock()
+	banner := engine.banner
+	engine.addrMu.RUnlock()
+	if engine.HideBanner || banner == "" {
+		return engine.logger.Debug
+	}
+	return engine.logger.Info
 }
 
 // Run attaches the engine to a fasthttp server and starts listening and serving HTTP requests.
 // It is a shortcut for engine.Server.ListenAndServe(addr, engine.HandleRequest) Note: this method will block the
 // calling goroutine indefinitely unless an error happens.
+// The optional message is logged when the server is started ("%s" is replaced with the bound address),
+// pass "" to log it at the debug level only.
 func (engine *Engine) Run(addr string, message ...string) error {
-	ec := make(chan error)
-	go func() {
-		engine.Server.Handler = engine.HandleRequest
-		ec <- listenAndServe(engine, addr)
-	}()
-	return runmsg(addr, ec, append(message, "HTTP server started at %s")[0])
+	return engine.run("tcp", addr, append(message, "HTTP server started at %s")[0], func() error {
+		return listenAndServe(engine, addr)
+	})
+}
+
+// Start runs the server on the Addr from the engine Config. It serves HTTPS requests
+// when CertFile and KeyFile are configured (see RunTLS) and HTTP requests otherwise (see Run).
+// Note: this method will block the calling goroutine indefinitely unless an error happens.
+func (engine *Engine) Start(message ...string) error {
+	if engine.certFile != "" || engine.keyFile != "" || engine.getCertificate != nil || len(engine.sniCertificates) != 0 {
+		return engine.RunTLS(engine.addr, engine.certFile, engine.keyFile, message...)
+	}
+	return engine.Run(engine.addr, message...)
 }
 
 // RunTLS attaches the engine to a fasthttp server and starts listening and
@@ -192,40 +487,31 @@ func (engine *Engine) Run(addr string, message ...string) error {
 // engine.Server.ListenAndServeTLS(addr, certFile, keyFile)
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
 func (engine *Engine) RunTLS(addr string, certFile, keyFile string, message ...string) error {
-	ec := make(chan error)
-	go func() {
-		engine.Server.Handler = engine.HandleRequest
-		ec <- listenAndServeTLS(engine, addr, certFile, keyFile)
-	}()
-	return runmsg(addr, ec, append(message, "HTTPS server started at %s")[0])
+	return engine.run("tcp", addr, append(message, "HTTPS server started at %s")[0], func() error {
+		return listenAndServeTLS(engine, addr, certFile, keyFile)
+	})
 }
 
 // RunUnix attaches the engine to a fasthttp server and starts listening and
 // serving HTTP requests through the specified unix socket (ie. a file).
 // Note: this method will block the calling goroutine indefinitely unless an error happens.
 func (engine *Engine) RunUnix(addr string, mode os.FileMode, message ...string) error {
-	ec := make(chan error)
-	go func() {
-		engine.Server.Handler = engine.HandleRequest
-		ec <- engine.Server.ListenAndServeUNIX(addr, mode)
-	}()
-	return runmsg(addr, ec, append(message, "Unix server started at %s")[0])
+	return engine.run("unix", addr, append(message, "Unix server started at %s")[0], func() error {
+		return listenAndServeUnix(engine, addr, mode)
+	})
 }
 
 // Serve serves incoming connections from the given listener using the given handler.
 // Serve blocks until the given listener returns permanent error.
 func (engine *Engine) Serve(addr string, cfg *tls.Config, message ...string) error {
-	ec := make(chan error)
-	go func() {
+	return engine.run("tcp", addr, append(message, "Server started at %s")[0], func() error {
 		ln, err := net.Listen("tcp4", addr)
 		if err != nil {
-			panic(err)
+			return err
 		}
-
-		lnTls := tls.NewListener(ln, cfg)
-		ec <- fasthttp.Serve(lnTls, engine.HandleRequest)
-	}()
-	return runmsg(addr, ec, append(message, "Server started at %s")[0])
+		engine.listening(ln)
+		return fasthttp.Serve(tls.NewListener(ln, cfg), engine.HandleRequest)
+	})
 }
 
 // HandleRequest handles the HTTP request.
@@ -233,35 +519,140 @@ func (engine *Engine) HandleRequest(ctx *fasthttp.RequestCtx) {
 	start := time.Now()
 	c := engine.pool.Get().(*Context)
 	c.init(ctx)
-	c.handlers, c.pnames = engine.find(string(ctx.Method()), string(ctx.Path()), c.pvalues)
+	engine.stats.begin()
+	if atomic.LoadInt32(&engine.draining) != 0 {
+		ctx.SetConnectionClose()
+	}
+	if len(engine.panicReporters) != 0 {
+		defer func() {
+			if err := recover(); err != nil {
+				engine.reportPanic(c, err)
+				panic(err)
+			}
+		}()
+	}
+	for _, h := range engine.pre {
+		if h(c); c.aborted {
+			break
+		}
+	}
+	c.index = -1
+	if c.aborted {
+		c.handlers, c.pnames = nil, nil
+	} else if engine.isDisabledMethod(c.Method()) {
+		c.handlers, c.pnames = []Handler{notImplementedHandler}, nil
+	} else if len(engine.allowedHosts) != 0 && !engine.isAllowedHost(string(ctx.Host())) {
+		c.handlers, c.pnames = []Handler{rejectHostHandler}, nil
+	} else if engine.basePath != "" && !c.stripPath(engine.basePath) {
+		c.handlers, c.pnames = engine.notFoundHandlers, nil
+	} else if redirect := engine.findRedirect(string(ctx.Path())); redirect != nil {
+		c.handlers, c.pnames = []Handler{redirect}, nil
+	} else {
+		engine.match(c)
+	}
 	fin := func() {
-		c.Next()
-		engine.pool.Put(c)
-		engine.debug(fmt.Sprintf("%-21s | %d | %9v | %-7s %-25s ", time.Now().Format("2006/01/02 - 15:04:05"), c.Response.StatusCode(), time.Since(start), string(ctx.Method()), string(ctx.Path())))
+		if engine.panicHandler != nil {
+			engine.nextRecovered(c)
+		} else {
+			c.Next()
+		}
+		for _, h := range engine.post {
+			h(c)
+		}
+		c.stopWatching()
+		engine.trackResponseWrite(c)
+		engine.stats.end(c.Response.StatusCode())
+		if engine.metrics != nil {
+			engine.recordMetrics(c, time.Since(start))
+		}
+		if engine.Debug {
+			engine.debug(fmt.Sprintf("%-21s | %d | %9v | %-7s %-25s ", time.Now().Format("2006/01/02 - 15:04:05"), c.Response.StatusCode(), time.Since(start), string(ctx.Method()), string(ctx.Path())))
+		}
 		if engine.DebugFunc != nil {
 			engine.DebugFunc(c, time.Since(start))
 		}
+		engine.releaseContext(c)
 	}
 	fin()
 }
 
+// releaseContext releases the context data and returns the context to the pool after the end of the request.
+// In the Debug mode the contexts are poisoned instead of being reused, so the use of a context retained
+// after its request (the classic "captured c in a goroutine" bug) panics with the message naming the request
+// instead of reading the data of another request.
+func (engine *Engine) releaseContext(c *Context) {
+	if c.retained {
+		return
+	}
+	releaseDataMap(c.data)
+	if engine.Debug {
+		c.poison()
+		return
+	}
+	engine.pool.Put(c)
+}
+
 // Route returns the named route.
 // Nil is returned if the named route cannot be found.
 func (engine *Engine) Route(name string) *Route {
 	return engine.routes[name]
 }
 
+// Routes returns the descriptions of all registered routes in the registration order.
+func (engine *Engine) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(engine.routeList))
+	for _, route := range engine.routeList {
+		if len(route.methods) != 0 {
+			routes = append(routes, route.Info())
+		}
+	}
+	return routes
+}
+
+// OnAbort registers the function called when a handler aborts the handlers chain (see Context.Abort),
+// for example to count the rejected requests by the reasons (see Context.AbortWithReason).
+// Functions are called in the registration order. The requests answered by the router itself
+// (the automatic OPTIONS responses, 405 Method Not Allowed, the rejected hosts, the redirect rules
+// and the resolved not found requests) and the cached responses (see Route.CacheFor and
+// Route.Memoize) don't call them.
+//
+//	engine.OnAbort(func(c *tokay.Context) {
+//		rejected.WithLabelValues(c.AbortReason()).Inc()
+//	})
+func (engine *Engine) OnAbort(fn func(c *Context)) {
+	engine.onAbort = append(engine.onAbort, fn)
+}
+
+// Pre appends the handlers called for every request before the routing, for example to rewrite
+// the request path or host. The route is matched after them, so Context.Route is nil there.
+// If a handler aborts the request, the rest of them and the routing are skipped (the Post
+// handlers are still called).
+//
+//	engine.Pre(func(c *tokay.Context) {
+//		c.Request.URI().SetPath(strings.TrimPrefix(c.Path(), "/v1"))
+//	})
+func (engine *Engine) Pre(handlers ...Handler) {
+	engine.pre = append(engine.pre, handlers...)
+}
+
+// Post appends the handlers called for every request after the handlers chain, even if it is aborted,
+// for example to set the common response headers or to log the final response.
+func (engine *Engine) Post(handlers ...Handler) {
+	engine.post = append(engine.post, handlers...)
+}
+
 // Use appends the specified handlers to the engine and shares them with all routes.
 func (engine *Engine) Use(handlers ...Handler) {
 	engine.RouterGroup.Use(handlers...)
-	engine.notFoundHandlers = combineHandlers(engine.handlers, engine.notFound)
+	engine.updateNotFoundHandlers()
 }
 
 // NotFound specifies the handlers that should be invoked when the engine cannot find any route matching a request.
-// Note that the handlers registered via Use will be invoked first in this case.
+// Note that the handlers registered via Use and the resolvers registered via NotFoundResolvers
+// will be invoked first in this case.
 func (engine *Engine) NotFound(handlers ...Handler) {
 	engine.notFound = handlers
-	engine.notFoundHandlers = combineHandlers(engine.handlers, engine.notFound)
+	engine.updateNotFoundHandlers()
 }
 
 // handleError is the error handler for handling any unhandled errors.
@@ -269,40 +660,115 @@ func (engine *Engine) handleError(c *Context, err error) {
 	c.Error(err.Error(), http.StatusInternalServerError)
 }
 
-func (engine *Engine) add(method, path string, handlers []Handler) {
+func (engine *Engine) add(method string, route *Route, handlers []Handler) {
 	for _, h := range handlers {
-		engine.debug(fmt.Sprintf("%-7s %-25s -->", method, path), runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name())
+		engine.debug(fmt.Sprintf("%-7s %-25s -->", method, route.path), handlerName(h))
 	}
 	store := engine.stores.Get(method)
 	if store == nil {
 		store = newStore()
 		engine.stores.Set(method, store)
 	}
-	if n := store.Add(path, handlers); n > engine.maxParams {
+	rh := &routeHandlers{route: route, handlers: handlers}
+	if n := store.Add(route.path, rh); n > engine.maxParams {
 		engine.maxParams = n
 	}
+	// the routes with the same method and path are the candidates checked by the route guards
+	key := method + " " + route.path
+	if head := engine.candidates[key]; head != nil {
+		for head.next != nil {
+			head = head.next
+		}
+		head.next = rh
+	} else {
+		engine.candidates[key] = rh
+	}
+	engine.addAllowedMethod(method, route.path)
+	engine.logRouteWarnings(method, route)
 }
 
-func (engine *Engine) find(method, path string, pvalues []string) (handlers []Handler, pnames []string) {
+func (engine *Engine) find(method, path string, pvalues []string) (rh *routeHandlers, pnames []string) {
 	var hh interface{}
 	if store := engine.stores.Get(method); store != nil {
 		if hh, pnames = store.Get(path, pvalues); hh != nil {
-			return hh.([]Handler), pnames
+			return hh.(*routeHandlers), pnames
 		}
 	}
+	return nil, pnames
+}
+
+// match finds the route handlers for the request, checking the route guards (see Route.When).
+func (engine *Engine) match(c *Context) {
+	if engine.TraceRouting {
+		method, path := c.Method(), c.Path()
+		defer func() {
+			c.matchTrace = engine.traceMatch(method, path, c.route)
+		}()
+	}
+	rh, pnames := engine.find(c.Method(), c.Path(), c.pvalues)
+	c.pnames = pnames
+	for ; rh != nil; rh = rh.next {
+		if rh.route.allows(c) {
+			c.route, c.handlers = rh.route, rh.handlers
+			if group := rh.route.group; group.stripPrefix && group.path != "" {
+				c.stripPath(group.path)
+			}
+			return
+		}
+	}
+	c.route, c.handlers = nil, engine.notFoundHandlers
+	if handlers, ok := engine.notFoundHandlersFor[c.Method()]; ok {
+		c.handlers = handlers
+	}
+}
 
-	return engine.notFoundHandlers, pnames
+// allowedMethods is the list of HTTP methods registered for a route path pattern.
+type allowedMethods struct {
+	methods []string // sorted methods including OPTIONS
+	header  string   // the Allow header value
 }
 
-func (engine *Engine) findAllowedMethods(path string) map[string]bool {
-	methods := make(map[string]bool)
-	pvalues := make([]string, engine.maxParams)
-	engine.stores.Range(func(m string, store routeStore) {
-		if handlers, _ := store.Get(path, pvalues); handlers != nil {
-			methods[m] = true
+// addAllowedMethod adds the method to the precomputed allowed methods of the route path.
+func (engine *Engine) addAllowedMethod(method, path string) {
+	if engine.isDisabledMethod(method) {
+		return
+	}
+	allowed := engine.allowed[path]
+	if allowed == nil {
+		allowed = &allowedMethods{}
+		if engine.HandleOPTIONS {
+			allowed.methods = []string{"OPTIONS"}
+			allowed.header = "OPTIONS"
 		}
-	})
-	return methods
+		engine.allowed[path] = allowed
+		engine.allowedStore.Add(path, allowed)
+	}
+	for _, m := range allowed.methods {
+		if m == method {
+			return
+		}
+	}
+	allowed.methods = append(allowed.methods, method)
+	sort.Strings(allowed.methods)
+	allowed.header = strings.Join(allowed.methods, ", ")
+}
+
+// findAllowedMethods returns the allowed methods of the first registered route path pattern
+// matching the path (the same precedence rule as the routing uses), or nil.
+func (engine *Engine) findAllowedMethods(path string) *allowedMethods {
+	if allowed, _ := engine.allowedStore.Get(path, make([]string, engine.maxParams)); allowed != nil {
+		return allowed.(*allowedMethods)
+	}
+	return nil
+}
+
+// AllowedMethods returns the sorted list of HTTP methods (including OPTIONS) allowed for the request path.
+// The list is precomputed while registering routes. Nil is returned if no route matches the path.
+func (engine *Engine) AllowedMethods(path string) []string {
+	if allowed := engine.findAllowedMethods(path); allowed != nil {
+		return append([]string(nil), allowed.methods...)
+	}
+	return nil
 }
 
 func (engine *Engine) debug(text ...interface{}) {
@@ -312,34 +778,33 @@ func (engine *Engine) debug(text ...interface{}) {
 }
 
 // NotFoundHandler returns a 404 HTTP error indicating a request has no matching route.
+// The error is JSON or HTML if the client prefers them (see routeError).
 func NotFoundHandler(c *Context) {
 	if c.engine.RedirectTrailingSlash && redirectTrailingSlash(c) {
 		return
 	}
-	c.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	routeError(c, http.StatusNotFound)
 }
 
 // MethodNotAllowedHandler handles the situation when a request has matching route without matching HTTP method.
 // In this case, the handler will respond with an Allow HTTP header listing the allowed HTTP methods.
 // Otherwise, the handler will do nothing and let the next handler (usually a NotFoundHandler) to handle the problem.
 func MethodNotAllowedHandler(c *Context) {
-	methods := c.Engine().findAllowedMethods(string(c.Path()))
-	if len(methods) == 0 {
+	engine := c.Engine()
+	allowed := engine.findAllowedMethods(c.Path())
+	autoOPTIONS := engine.HandleOPTIONS && c.Method() == "OPTIONS"
+	if allowed == nil || !autoOPTIONS && inStrings(c.Method(), allowed.methods) {
+		// no routes for the path, or the route guards rejected the request (see Route.When)
 		return
 	}
-	methods["OPTIONS"] = true
-	ms := make([]string, len(methods))
-	i := 0
-	for method := range methods {
-		ms[i] = method
-		i++
-	}
-	sort.Strings(ms)
-	c.Response.Header.Set("Allow", strings.Join(ms, ", "))
-	if string(c.Method()) != "OPTIONS" {
+	c.Response.Header.Set("Allow", allowed.header)
+	if !autoOPTIONS {
 		c.Response.SetStatusCode(http.StatusMethodNotAllowed)
+		if routeErrorType(c) != "text/plain" {
+			routeError(c, http.StatusMethodNotAllowed)
+		}
 	}
-	c.Abort()
+	c.skipHandlers()
 	return
 }
 
@@ -359,10 +824,9 @@ func redirectTrailingSlash(c *Context) bool {
 		path = path + "/"
 	}
 
-	methods := c.Engine().findAllowedMethods(path)
-	if len(methods) == 0 {
+	if c.Engine().findAllowedMethods(path) == nil {
 		return false
 	}
-	c.Redirect(statusCode, path)
+	c.Redirect(statusCode, c.appURL(path))
 	return true
 }
@@ -0,0 +1,56 @@
+package tokay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnginePrePost(t *testing.T) {
+	engine := New()
+	trace := ""
+	engine.Pre(func(c *Context) {
+		trace += "pre "
+		assert.Nil(t, c.Route())
+		if strings.HasPrefix(c.Path(), "/v1/") {
+			c.Request.URI().SetPath(strings.TrimPrefix(c.Path(), "/v1"))
+		}
+	}, func(c *Context) {
+		if c.Path() == "/blocked" {
+			c.AbortWithStatus(403)
+		}
+	})
+	engine.Post(func(c *Context) {
+		trace += "post"
+		c.Header("X-Status", string(c.Response.Header.Peek("X-Route")))
+	})
+	engine.Use(func(c *Context) {
+		trace += "use "
+	})
+	engine.GET("/users", func(c *Context) {
+		trace += "handler "
+		c.Header("X-Route", c.Route().Path())
+	})
+	engine.GET("/abort", func(c *Context) {
+		trace += "abort "
+		c.AbortWithStatus(401)
+	}, func(c *Context) {
+		trace += "skipped "
+	})
+
+	ctx := serveTestRequest(engine, "/v1/users")
+	assert.Equal(t, "pre use handler post", trace)
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "/users", string(ctx.Response.Header.Peek("X-Status")))
+
+	trace = ""
+	ctx = serveTestRequest(engine, "/abort")
+	assert.Equal(t, "pre use abort post", trace)
+	assert.Equal(t, 401, ctx.Response.StatusCode())
+
+	trace = ""
+	ctx = serveTestRequest(engine, "/blocked")
+	assert.Equal(t, "pre post", trace)
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+}
@@ -0,0 +1,64 @@
+package tokay
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment names.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// detectEnv returns the configured environment name, the TOKAY_ENV environment variable or "development".
+func detectEnv(env string) string {
+	if env == "" {
+		env = os.Getenv("TOKAY_ENV")
+	}
+	if env == "" {
+		env = EnvDevelopment
+	}
+	return strings.ToLower(strings.TrimSpace(env))
+}
+
+// SetEnv sets the environment name, like "production" or "staging" (see InEnv).
+func (engine *Engine) SetEnv(env string) {
+	engine.env = detectEnv(env)
+}
+
+// Env returns the environment name (see Config.Env).
+func (engine *Engine) Env() string {
+	return engine.env
+}
+
+// IsProduction reports whether the engine runs in the "production" environment.
+func (engine *Engine) IsProduction() bool {
+	return engine.env == EnvProduction
+}
+
+// InEnv returns the handler calling the handlers only in the environments from the comma-separated
+// list, so the debug middlewares (pprof, verbose errors etc.) are never enabled in production by mistake.
+// The environment is checked at request time, the handlers may call Context.Next like the route handlers.
+//
+//	engine.Use(tokay.InEnv("development,staging", debugToolbar, verboseErrors))
+func InEnv(envs string, handlers ...Handler) Handler {
+	names := strings.Split(strings.ToLower(envs), ",")
+	for i := range names {
+		names[i] = strings.TrimSpace(names[i])
+	}
+	return func(c *Context) {
+		if len(handlers) == 0 || !inStrings(c.engine.env, names) {
+			return
+		}
+		outer, index := c.handlers, c.index
+		// the last handler of the nested chain continues the outer one
+		c.handlers = append(append(make([]Handler, 0, len(handlers)+1), handlers...), func(c *Context) {
+			c.handlers, c.index = outer, index
+			c.Next()
+		})
+		c.index = -1
+		c.Next()
+		c.handlers, c.index = outer, len(outer)
+	}
+}
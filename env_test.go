@@ -0,0 +1,61 @@
+package tokay
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineEnv(t *testing.T) {
+	defer os.Setenv("TOKAY_ENV", os.Getenv("TOKAY_ENV"))
+	os.Setenv("TOKAY_ENV", "")
+	assert.Equal(t, "development", New().Env())
+	os.Setenv("TOKAY_ENV", "Production")
+	assert.True(t, New().IsProduction())
+	assert.Equal(t, "staging", New(&Config{Env: "staging"}).Env())
+
+	engine := New()
+	engine.SetEnv("staging")
+	assert.False(t, engine.IsProduction())
+	assert.Equal(t, "staging", engine.Env())
+
+	defer os.Unsetenv("TOKAYTEST_ENV")
+	os.Setenv("TOKAYTEST_ENV", "test")
+	cfg, err := ConfigFromEnv("TOKAYTEST")
+	if assert.Nil(t, err) {
+		assert.Equal(t, "test", cfg.Env)
+	}
+}
+
+func TestInEnv(t *testing.T) {
+	engine := New(&Config{Env: "development"})
+	trace := ""
+	engine.Use(InEnv("development, staging",
+		func(c *Context) { trace += "a" },
+		func(c *Context) {
+			trace += "b<"
+			c.Next()
+			trace += ">"
+		},
+	))
+	engine.Use(InEnv("production", func(c *Context) { trace += "p" }))
+	engine.Use(func(c *Context) { trace += "m" })
+	engine.GET("/", func(c *Context) { trace += "h" })
+	engine.GET("/abort", InEnv("development", func(c *Context) {
+		trace += "x"
+		c.Abort()
+	}), func(c *Context) { trace += "h" })
+
+	serveTestRequest(engine, "/")
+	assert.Equal(t, "ab<mh>", trace)
+
+	trace = ""
+	serveTestRequest(engine, "/abort")
+	assert.Equal(t, "ab<mx>", trace)
+
+	trace = ""
+	engine.SetEnv("production")
+	serveTestRequest(engine, "/")
+	assert.Equal(t, "pmh", trace)
+}
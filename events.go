@@ -0,0 +1,75 @@
+package tokay
+
+import (
+	"sync"
+)
+
+// EventHandler handles the events emitted by Engine.Emit.
+type EventHandler func(event string, payload interface{})
+
+type (
+	// eventBus is the in-process publish/subscribe registry of the engine.
+	eventBus struct {
+		sync.RWMutex
+		subscribers map[string][]eventSubscriber
+	}
+
+	eventSubscriber struct {
+		handler EventHandler
+		async   bool
+	}
+)
+
+// On subscribes the handler to the named event. The handler is called synchronously by Emit
+// in the subscription order. Panics in the handler are recovered and logged, so they don't
+// affect the emitter and other subscribers.
+//
+//	engine.On("user.created", func(event string, payload interface{}) {
+//		mailer.Welcome(payload.(*User))
+//	})
+func (engine *Engine) On(event string, handler EventHandler) {
+	engine.events.subscribe(event, eventSubscriber{handler: handler})
+}
+
+// OnAsync subscribes the handler to the named event. The handler is called in a separate goroutine.
+func (engine *Engine) OnAsync(event string, handler EventHandler) {
+	engine.events.subscribe(event, eventSubscriber{handler: handler, async: true})
+}
+
+// Emit delivers the payload to the subscribers of the named event.
+// It returns after all synchronous subscribers are called.
+func (engine *Engine) Emit(event string, payload interface{}) {
+	engine.events.RLock()
+	subscribers := engine.events.subscribers[event]
+	engine.events.RUnlock()
+
+	for _, s := range subscribers {
+		if s.async {
+			go engine.callEventHandler(s.handler, event, payload)
+		} else {
+			engine.callEventHandler(s.handler, event, payload)
+		}
+	}
+}
+
+// callEventHandler calls the handler and recovers its panic.
+func (engine *Engine) callEventHandler(handler EventHandler, event string, payload interface{}) {
+	defer func() {
+		if err := recover(); err != nil {
+			engine.logger.Error("event handler panic", "event", event, "error", err)
+		}
+	}()
+	handler(event, payload)
+}
+
+func (b *eventBus) subscribe(event string, s eventSubscriber) {
+	b.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[string][]eventSubscriber)
+	}
+	// copy on write: Emit iterates the slice without lock
+	subscribers := make([]eventSubscriber, len(b.subscribers[event]), len(b.subscribers[event])+1)
+	copy(subscribers, b.subscribers[event])
+	b.subscribers[event] = append(subscribers, s)
+	b.Unlock()
+}
@@ -0,0 +1,36 @@
+package tokay
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvents(t *testing.T) {
+	engine := New(&Config{Logger: &testLogger{}})
+	var calls []string
+	engine.On("user.created", func(event string, payload interface{}) {
+		calls = append(calls, "1:"+payload.(string))
+	})
+	engine.On("user.created", func(event string, payload interface{}) {
+		panic("boom")
+	})
+	engine.On("user.created", func(event string, payload interface{}) {
+		calls = append(calls, "3:"+payload.(string))
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var async string
+	engine.OnAsync("user.created", func(event string, payload interface{}) {
+		async = event
+		wg.Done()
+	})
+
+	engine.Emit("user.created", "bob")
+	engine.Emit("user.deleted", "bob")
+	wg.Wait()
+	assert.Equal(t, []string{"1:bob", "3:bob"}, calls)
+	assert.Equal(t, "user.created", async)
+}
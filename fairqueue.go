@@ -0,0 +1,187 @@
+package tokay
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HeavyMetaKey is the route metadata key of the routes marked by Route.Heavy.
+const HeavyMetaKey = "heavy"
+
+// Heavy marks the route as an expensive one (reports, exports) which requests are
+// scheduled by the FairQueue middleware.
+func (r *Route) Heavy() *Route {
+	return r.SetMeta(HeavyMetaKey, true)
+}
+
+// FairQueueConfig is a struct for specifying FairQueue middleware options.
+type FairQueueConfig struct {
+	// Concurrency is the maximum number of concurrently handled requests. Default is 1.
+	Concurrency int
+	// MaxQueue is the maximum number of waiting requests of a single client. Requests above
+	// the limit are rejected with 429 Too Many Requests. Default is 10.
+	MaxQueue int
+	// Timeout is the maximum waiting time in the queue. Requests waiting longer are rejected
+	// with 503 Service Unavailable. Default is 30 seconds.
+	Timeout time.Duration
+	// Key returns the client key. By default it is the X-API-Key header value or the client IP.
+	Key func(c *Context) string
+	// Weight returns the client weight: the number of requests started in turn for the client
+	// when several clients are waiting. Default weight is 1.
+	Weight func(key string) int
+	// AllRoutes enables queuing for all routes, not only for the ones marked by Route.Heavy.
+	AllRoutes bool
+}
+
+type (
+	// fairQueue schedules waiting requests between clients with the weighted round-robin.
+	fairQueue struct {
+		sync.Mutex
+		config  FairQueueConfig
+		running int
+		clients map[string]*fairClient
+		ring    []*fairClient // clients with waiting requests in round-robin order
+		next    int           // ring index of the client served next
+	}
+
+	fairClient struct {
+		key     string
+		weight  int
+		credit  int // the number of requests the client may start in the current turn
+		waiting []chan struct{}
+	}
+)
+
+// FairQueue returns a middleware which limits concurrency of the heavy routes (see Route.Heavy)
+// and shares it fairly between clients, so one client cannot monopolize the workers.
+//
+//	engine.Use(tokay.FairQueue(tokay.FairQueueConfig{Concurrency: 4}))
+//	engine.GET("/reports/<id>", reportHandler).Heavy()
+func FairQueue(config FairQueueConfig) Handler {
+	if config.Concurrency <= 0 {
+		config.Concurrency = 1
+	}
+	if config.MaxQueue <= 0 {
+		config.MaxQueue = 10
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.Key == nil {
+		config.Key = func(c *Context) string {
+			if key := c.GetHeader("X-API-Key"); key != "" {
+				return key
+			}
+			return c.ClientIP()
+		}
+	}
+	if config.Weight == nil {
+		config.Weight = func(string) int { return 1 }
+	}
+	q := &fairQueue{config: config, clients: make(map[string]*fairClient)}
+
+	return func(c *Context) {
+		if !config.AllRoutes && (c.route == nil || c.route.Meta(HeavyMetaKey) != true) {
+			return
+		}
+		if code := q.acquire(config.Key(c)); code != 0 {
+			c.AbortWithError(code, nil)
+			return
+		}
+		defer q.release()
+		c.Next()
+	}
+}
+
+// acquire waits for the turn of the client. It returns non-zero HTTP status code on failure.
+func (q *fairQueue) acquire(key string) int {
+	q.Lock()
+	if q.running < q.config.Concurrency && len(q.ring) == 0 {
+		q.running++
+		q.Unlock()
+		return 0
+	}
+	client := q.clients[key]
+	if client == nil {
+		weight := q.config.Weight(key)
+		if weight < 1 {
+			weight = 1
+		}
+		client = &fairClient{key: key, weight: weight}
+	}
+	if len(client.waiting) >= q.config.MaxQueue {
+		q.Unlock()
+		return http.StatusTooManyRequests
+	}
+	ready := make(chan struct{})
+	if len(client.waiting) == 0 {
+		q.clients[key] = client
+		q.ring = append(q.ring, client)
+	}
+	client.waiting = append(client.waiting, ready)
+	q.Unlock()
+
+	timer := time.NewTimer(q.config.Timeout)
+	defer timer.Stop()
+	select {
+	case <-ready:
+		return 0
+	case <-timer.C:
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	for i, ch := range client.waiting {
+		if ch == ready {
+			client.waiting = append(client.waiting[:i], client.waiting[i+1:]...)
+			if len(client.waiting) == 0 {
+				q.remove(client)
+			}
+			return http.StatusServiceUnavailable
+		}
+	}
+	// the turn came together with the timeout
+	return 0
+}
+
+// release finishes the request and starts the next waiting ones.
+func (q *fairQueue) release() {
+	q.Lock()
+	q.running--
+	for q.running < q.config.Concurrency && len(q.ring) != 0 {
+		if q.next >= len(q.ring) {
+			q.next = 0
+		}
+		client := q.ring[q.next]
+		if client.credit == 0 {
+			client.credit = client.weight
+		}
+		close(client.waiting[0])
+		client.waiting = client.waiting[1:]
+		client.credit--
+		q.running++
+
+		if len(client.waiting) == 0 {
+			client.credit = 0
+			q.remove(client)
+		} else if client.credit == 0 {
+			q.next++
+		}
+	}
+	q.Unlock()
+}
+
+// remove deletes the client without waiting requests from the ring.
+func (q *fairQueue) remove(client *fairClient) {
+	delete(q.clients, client.key)
+	for i, c := range q.ring {
+		if c == client {
+			q.ring = append(q.ring[:i], q.ring[i+1:]...)
+			if i < q.next {
+				q.next--
+			}
+			return
+		}
+	}
+}
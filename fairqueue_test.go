@@ -0,0 +1,51 @@
+package tokay
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairQueue(t *testing.T) {
+	q := &fairQueue{
+		config: FairQueueConfig{
+			Concurrency: 1,
+			MaxQueue:    3,
+			Timeout:     time.Second,
+			Weight: func(key string) int {
+				if key == "b" {
+					return 2
+				}
+				return 1
+			},
+		},
+		clients: make(map[string]*fairClient),
+	}
+	assert.Equal(t, 0, q.acquire("a"))
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "a", "a", "b", "b", "b", "c"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			if q.acquire(key) == 0 {
+				mu.Lock()
+				order = append(order, key)
+				mu.Unlock()
+				q.release()
+			}
+		}(key)
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 429, q.acquire("a"))
+
+	q.release()
+	wg.Wait()
+	assert.Equal(t, []string{"a", "b", "b", "c", "a", "b", "a"}, order)
+	assert.Equal(t, 0, q.running)
+	assert.Len(t, q.clients, 0)
+}
@@ -0,0 +1,191 @@
+package tokay
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/night-codes/go-json"
+	"github.com/night-codes/govalidator"
+)
+
+// FormStateCookie is the cookie keeping the form state between the POST request and the redirected
+// GET request (see Context.SaveFormState).
+const FormStateCookie = "tokay_form"
+
+// maxFormStateCookie is the maximum size of the form state cookie value, the submitted values
+// are dropped from the larger states.
+const maxFormStateCookie = 3800
+
+// FormState is the submitted form values with the field errors for re-rendering the form after
+// the failed validation. The methods are safe to call on the nil state, so the templates can use
+// {{.Form.Value "email"}} and {{.Form.Error "email"}} for the first render.
+type FormState struct {
+	// Values are the submitted values by the form field names.
+	Values map[string][]string `json:"v,omitempty"`
+	// Errors are the error messages by the form field names, the empty name is the form error.
+	Errors map[string]string `json:"e,omitempty"`
+}
+
+// FormState returns the values submitted to the form with the field errors of err returned by Bind
+// (the validation errors are assigned to the fields by the "form" tags of obj, other errors to the whole
+// form). The fields of obj which are not submitted are filled with obj values. The fields with the names
+// containing "pass" (like "password") are never re-populated.
+//
+//	var form SignUpForm
+//	if err := c.Bind(&form); err != nil {
+//		c.SaveFormState(c.FormState(&form, err))
+//		c.Redirect(303, "/signup")
+//		return
+//	}
+func (c *Context) FormState(obj interface{}, err error) *FormState {
+	state := &FormState{Values: make(map[string][]string), Errors: make(map[string]string)}
+	if c.Method() == "GET" {
+		c.QueryArgs().VisitAll(state.addValue)
+	} else if form, ferr := c.MultipartForm(); ferr == nil {
+		for name, values := range form.Value {
+			for _, value := range values {
+				state.addValue([]byte(name), []byte(value))
+			}
+		}
+	} else {
+		c.PostArgs().VisitAll(state.addValue)
+	}
+
+	names := map[string]string{}
+	if v := reflect.Indirect(reflect.ValueOf(obj)); v.Kind() == reflect.Struct {
+		state.addFields(v, names)
+	}
+	state.addErrors(err, names)
+	return state
+}
+
+// addValue adds the submitted value of the form field.
+func (s *FormState) addValue(name, value []byte) {
+	if key := string(name); !strings.Contains(strings.ToLower(key), "pass") {
+		s.Values[key] = append(s.Values[key], string(value))
+	}
+}
+
+// addFields adds the values of the struct fields which are not submitted and collects the form names
+// of the struct fields by the field names.
+func (s *FormState) addFields(v reflect.Value, names map[string]string) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Tag.Get("form")
+		if name == "" {
+			if value.Kind() == reflect.Struct {
+				s.addFields(value, names)
+				continue
+			}
+			name = field.Name
+		}
+		names[field.Name] = name
+		if _, ok := s.Values[name]; ok || strings.Contains(strings.ToLower(name), "pass") || isEmptyValue(value) {
+			continue
+		}
+		switch value.Kind() {
+		case reflect.Slice, reflect.Array:
+			for j := 0; j < value.Len(); j++ {
+				s.Values[name] = append(s.Values[name], fmt.Sprint(value.Index(j).Interface()))
+			}
+		case reflect.Map, reflect.Struct, reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan:
+		default:
+			s.Values[name] = []string{fmt.Sprint(value.Interface())}
+		}
+	}
+}
+
+// addErrors adds the validation errors to the fields and the other errors to the whole form.
+func (s *FormState) addErrors(err error, names map[string]string) {
+	if err == nil {
+		return
+	}
+	var errs govalidator.Errors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			s.addErrors(e, names)
+		}
+		return
+	}
+	var fieldErr govalidator.Error
+	if errors.As(err, &fieldErr) {
+		name, ok := names[fieldErr.Name]
+		if !ok {
+			name = fieldErr.Name
+		}
+		if _, exists := s.Errors[name]; !exists {
+			s.Errors[name] = fieldErr.Err.Error()
+		}
+		return
+	}
+	if _, exists := s.Errors[""]; !exists {
+		s.Errors[""] = err.Error()
+	}
+}
+
+// Value returns the first submitted value of the form field.
+func (s *FormState) Value(name string) string {
+	if s == nil || len(s.Values[name]) == 0 {
+		return ""
+	}
+	return s.Values[name][0]
+}
+
+// Checked reports whether the value of the checkbox or the option is submitted for the form field.
+func (s *FormState) Checked(name, value string) bool {
+	return s != nil && inStrings(value, s.Values[name])
+}
+
+// Error returns the error message of the form field, the empty name returns the form error.
+func (s *FormState) Error(name string) string {
+	if s == nil {
+		return ""
+	}
+	return s.Errors[name]
+}
+
+// HasErrors reports whether the state has any errors.
+func (s *FormState) HasErrors() bool {
+	return s != nil && len(s.Errors) != 0
+}
+
+// SaveFormState keeps the form state in the FormStateCookie until the next request of the client reads it
+// with LoadFormState, for the POST-redirect-GET flows. The submitted values are dropped if the state
+// doesn't fit the cookie.
+func (c *Context) SaveFormState(state *FormState) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return
+	}
+	if base64.RawURLEncoding.EncodedLen(len(data)) > maxFormStateCookie {
+		data, _ = json.Marshal(&FormState{Errors: state.Errors})
+	}
+	c.SetCookie(FormStateCookie, base64.RawURLEncoding.EncodeToString(data), "/", "", c.IsTLS(), true, time.Now().Add(10*time.Minute))
+}
+
+// LoadFormState returns the form state saved by SaveFormState and removes the cookie.
+// Nil is returned if there is no saved state.
+func (c *Context) LoadFormState() *FormState {
+	value := c.Cookie(FormStateCookie)
+	if value == "" {
+		return nil
+	}
+	c.RemoveCookie(FormStateCookie)
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+	state := &FormState{}
+	if json.Unmarshal(data, state) != nil {
+		return nil
+	}
+	return state
+}
@@ -0,0 +1,68 @@
+package tokay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type signUpForm struct {
+	Email    string   `form:"email" valid:"email,required"`
+	Name     string   `form:"name" valid:"required"`
+	Password string   `form:"password" valid:"required"`
+	Country  string   `form:"country"`
+	Tags     []string `form:"tag"`
+}
+
+func TestFormState(t *testing.T) {
+	engine := New()
+	var state *FormState
+	engine.POST("/signup", func(c *Context) {
+		form := signUpForm{Country: "UA"}
+		err := c.Bind(&form)
+		state = c.FormState(&form, err)
+		c.SaveFormState(state)
+		c.Redirect(303, "/signup")
+	})
+	var loaded *FormState
+	engine.GET("/signup", func(c *Context) {
+		loaded = c.LoadFormState()
+	})
+
+	ctx := serveTestRequest(engine, "/signup", withMethod("POST"),
+		withBody("application/x-www-form-urlencoded", "email=bob&password=secret&tag=a&tag=b"))
+	assert.Equal(t, 303, ctx.Response.StatusCode())
+
+	assert.Equal(t, "bob", state.Value("email"))
+	assert.Equal(t, "", state.Value("password"))
+	assert.Equal(t, "UA", state.Value("country"))
+	assert.True(t, state.Checked("tag", "b"))
+	assert.False(t, state.Checked("tag", "c"))
+	assert.True(t, state.HasErrors())
+	assert.Equal(t, "bob does not validate as email", state.Error("email"))
+	assert.Equal(t, "non zero value required", state.Error("name"))
+	assert.Equal(t, "", state.Error("country"))
+
+	cookie := fasthttp.AcquireCookie()
+	cookie.SetKey(FormStateCookie)
+	assert.True(t, ctx.Response.Header.Cookie(cookie))
+	assert.True(t, cookie.HTTPOnly())
+
+	ctx2 := serveTestRequest(engine, "/signup", withHeader("Cookie", string(cookie.Key())+"="+string(cookie.Value())))
+	assert.Equal(t, state, loaded)
+	assert.Contains(t, string(ctx2.Response.Header.Peek("Set-Cookie")), FormStateCookie+"=;")
+
+	serveTestRequest(engine, "/signup")
+	assert.Nil(t, loaded)
+	assert.Equal(t, "", loaded.Value("email"))
+	assert.False(t, loaded.HasErrors())
+}
+
+func TestFormStateFormError(t *testing.T) {
+	c := newTestContext("/", withMethod("POST"), withBody("application/x-www-form-urlencoded", "name=bob"))
+	state := c.FormState(nil, errors.New("session expired"))
+	assert.Equal(t, "bob", state.Value("name"))
+	assert.Equal(t, "session expired", state.Error(""))
+}
@@ -0,0 +1,127 @@
+package tokay
+
+import (
+	"net"
+	"strings"
+)
+
+// TrustProxies sets the IP addresses and CIDR ranges (like "10.0.0.0/8") of the reverse proxies whose
+// X-Forwarded-Proto, X-Forwarded-Host and X-Forwarded-Prefix headers are honored by Context.Scheme,
+// Context.BaseURL, Context.URL and the redirects. The headers of the other clients are ignored.
+// An error is returned for a malformed entry.
+//
+//	engine.TrustProxies("127.0.0.1", "10.0.0.0/8")
+func (engine *Engine) TrustProxies(proxies ...string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, ipNet)
+	}
+	engine.trustedProxies = nets
+	return nil
+}
+
+// isTrustedProxy reports whether the request is sent by a trusted proxy (see TrustProxies).
+func (c *Context) isTrustedProxy() bool {
+	if len(c.engine.trustedProxies) == 0 {
+		return false
+	}
+	ip := c.RemoteIP()
+	for _, ipNet := range c.engine.trustedProxies {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwarded returns the first value of the X-Forwarded-* header sent by a trusted proxy.
+func (c *Context) forwarded(name string) string {
+	value := c.GetHeader(name)
+	if value == "" || !c.isTrustedProxy() {
+		return ""
+	}
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// Scheme returns the request scheme, "http" or "https", as seen by the client:
+// the X-Forwarded-Proto header of a trusted proxy is honored (see Engine.TrustProxies).
+func (c *Context) Scheme() string {
+	if proto := strings.ToLower(c.forwarded("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+		return proto
+	}
+	if c.IsTLS() {
+		return "https"
+	}
+	return "http"
+}
+
+// publicHost returns the host requested by the client honoring the X-Forwarded-Host header of a trusted proxy.
+func (c *Context) publicHost() string {
+	if host := c.forwarded("X-Forwarded-Host"); host != "" && !strings.ContainsAny(host, "/\\@ ") {
+		return host
+	}
+	return c.Host()
+}
+
+// forwardedPrefix returns the path prefix stripped by a trusted proxy (the X-Forwarded-Prefix header).
+func (c *Context) forwardedPrefix() string {
+	prefix := strings.TrimRight(c.forwarded("X-Forwarded-Prefix"), "/")
+	if prefix == "" || prefix[0] != '/' || strings.HasPrefix(prefix, "//") || strings.Contains(prefix, "://") {
+		return ""
+	}
+	return prefix
+}
+
+// BaseURL returns the URL of the application root as seen by the client, like "https://example.com/app",
+// including the scheme, the host and the path prefix of the trusted proxy (see Engine.TrustProxies)
+// and the engine base path (see Engine.BasePath).
+func (c *Context) BaseURL() string {
+	return c.Scheme() + "://" + c.publicHost() + c.forwardedPrefix() + c.engine.basePath
+}
+
+// AbsoluteURL returns the absolute URL of the named route (see Context.URL) as seen by the client,
+// for the links in emails, feeds and the Location headers. The empty string is returned if the
+// route cannot be found.
+//
+//	c.AbsoluteURL("user", "id", user.ID) // "https://example.com/app/users/1"
+func (c *Context) AbsoluteURL(route string, pairs ...interface{}) string {
+	if u := c.URL(route, pairs...); u != "" {
+		return c.Scheme() + "://" + c.publicHost() + u
+	}
+	return ""
+}
+
+// redirectLocation makes the absolute path of the redirect absolute URL with the scheme and the host
+// of the trusted proxy, as the default URL would point to the upstream server address.
+func (c *Context) redirectLocation(uri string) string {
+	if uri == "" || uri[0] != '/' || strings.HasPrefix(uri, "//") {
+		return uri
+	}
+	if c.forwarded("X-Forwarded-Proto") == "" && c.forwarded("X-Forwarded-Host") == "" {
+		return uri
+	}
+	return c.Scheme() + "://" + c.publicHost() + uri
+}
+
+// appURL returns the URL path of the application path as seen by the client,
+// with the proxy path prefix and the engine base path.
+func (c *Context) appURL(path string) string {
+	if path == "" || path[0] != '/' || strings.HasPrefix(path, "//") {
+		return path
+	}
+	return c.forwardedPrefix() + c.engine.basePath + path
+}
@@ -0,0 +1,49 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineTrustProxies(t *testing.T) {
+	engine := New()
+	assert.Nil(t, engine.TrustProxies("127.0.0.1", "10.0.0.0/8", "::1"))
+	assert.NotNil(t, engine.TrustProxies("10.0.0.0/99"))
+	assert.NotNil(t, engine.TrustProxies("proxy"))
+	assert.Nil(t, engine.TrustProxies("127.0.0.1", "10.0.0.0/8", "::1"))
+
+	engine.GET("/users/<id>", func(c *Context) {
+		c.String(200, c.Scheme()+" "+c.BaseURL()+" "+c.URL("user", "id", 1)+" "+c.AbsoluteURL("user", "id", 1))
+	}).Name("user")
+	engine.GET("/login", func(c *Context) {
+		c.Redirect(302, "/users/1")
+	})
+	engine.GET("/list/", func(c *Context) {})
+
+	upstream := withHeader("Host", "upstream:8080")
+	proto := withHeader("X-Forwarded-Proto", "https, http")
+	host := withHeader("X-Forwarded-Host", "example.com")
+	prefix := withHeader("X-Forwarded-Prefix", "/app/")
+	ctx := serveTestRequest(engine, "/users/1", withRemoteIP("10.1.2.3"), upstream, proto, host, prefix)
+	assert.Equal(t, "https https://example.com/app /app/users/1 https://example.com/app/users/1", string(ctx.Response.Body()))
+
+	ctx = serveTestRequest(engine, "/users/1", withRemoteIP("192.168.1.1"), upstream, proto, host, prefix)
+	assert.Equal(t, "http http://upstream:8080 /users/1 http://upstream:8080/users/1", string(ctx.Response.Body()))
+
+	ctx = serveTestRequest(engine, "/login", withRemoteIP("10.1.2.3"), upstream, proto, host, prefix)
+	assert.Equal(t, 302, ctx.Response.StatusCode())
+	assert.Equal(t, "https://example.com/users/1", string(ctx.Response.Header.Peek("Location")))
+
+	ctx = serveTestRequest(engine, "/list", withRemoteIP("10.1.2.3"), upstream, proto, host, prefix)
+	assert.Equal(t, 301, ctx.Response.StatusCode())
+	assert.Equal(t, "https://example.com/app/list/", string(ctx.Response.Header.Peek("Location")))
+
+	ctx = serveTestRequest(engine, "/users/1", withRemoteIP("10.1.2.3"), upstream,
+		withHeader("X-Forwarded-Host", "evil.com/x"), withHeader("X-Forwarded-Prefix", "//evil.com"))
+	assert.Equal(t, "http http://upstream:8080 /users/1 http://upstream:8080/users/1", string(ctx.Response.Body()))
+
+	engine.BasePath("/api")
+	ctx = serveTestRequest(engine, "/api/users/1", withRemoteIP("::1"), upstream, proto, host, prefix)
+	assert.Equal(t, "https https://example.com/app/api /app/api/users/1 https://example.com/app/api/users/1", string(ctx.Response.Body()))
+}
@@ -3,6 +3,8 @@ module github.com/night-codes/tokay
 go 1.16
 
 require (
+	github.com/BurntSushi/toml v1.2.1
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/klauspost/compress v1.15.14 // indirect
 	github.com/night-codes/go-json v0.9.15
 	github.com/night-codes/govalidator v1.0.4
@@ -10,4 +12,6 @@ require (
 	github.com/night-codes/tokay-websocket v1.0.0
 	github.com/stretchr/testify v1.7.0
 	github.com/valyala/fasthttp v1.44.0
+	golang.org/x/text v0.3.7
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,231 @@
+package tokay
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// ForwardedElement is a single proxy element of the RFC 7239 Forwarded header.
+type ForwardedElement struct {
+	For   string // the client (or the previous proxy) address, like "192.0.2.60" or "[2001:db8::1]:4711"
+	By    string // the proxy interface address
+	Host  string // the original Host header value
+	Proto string // the original protocol ("http" or "https")
+}
+
+// acceptRange is a value of the Accept-like headers with its quality.
+type acceptRange struct {
+	value string
+	q     float64
+}
+
+// Accepts returns the best of the given content types according to the Accept header,
+// or an empty string if none of them is acceptable. The first type is returned if the
+// header is empty.
+//
+//	switch c.Accepts("application/json", "text/html") {
+//	case "application/json":
+//		c.JSON(200, data)
+//	case "text/html":
+//		c.HTML(200, "page", data)
+//	default:
+//		c.AbortWithStatus(406)
+//	}
+func (c *Context) Accepts(types ...string) string {
+	return negotiate(c.GetHeader("Accept"), types, matchMediaType)
+}
+
+// AcceptsEncodings returns the best of the given content encodings according to the
+// Accept-Encoding header, or an empty string if none of them is acceptable.
+func (c *Context) AcceptsEncodings(encodings ...string) string {
+	return negotiate(c.GetHeader("Accept-Encoding"), encodings, matchEncoding)
+}
+
+// AcceptsLanguages returns the best of the given languages according to the
+// Accept-Language header, or an empty string if none of them is acceptable.
+// Language range "en" matches "en-US" language.
+func (c *Context) AcceptsLanguages(languages ...string) string {
+	return negotiate(c.GetHeader("Accept-Language"), languages, matchLanguage)
+}
+
+// BearerToken returns the token of the "Authorization: Bearer <token>" header.
+func (c *Context) BearerToken() (string, bool) {
+	auth := c.GetHeader("Authorization")
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		if token := strings.TrimSpace(auth[7:]); token != "" {
+			return token, true
+		}
+	}
+	return "", false
+}
+
+// BasicAuthCredentials returns the user name and password of the Basic HTTP Authorization header.
+func (c *Context) BasicAuthCredentials() (user, pass string, ok bool) {
+	auth := c.GetHeader("Authorization")
+	if len(auth) <= 6 || !strings.EqualFold(auth[:6], "Basic ") {
+		return
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(auth[6:]))
+	if err != nil {
+		return
+	}
+	credentials := string(decoded)
+	i := strings.IndexByte(credentials, ':')
+	if i < 0 {
+		return
+	}
+	return credentials[:i], credentials[i+1:], true
+}
+
+// Forwarded returns the parsed RFC 7239 Forwarded header elements, the first element describes
+// the client request to the first proxy. Nil is returned if the header is missing.
+func (c *Context) Forwarded() []ForwardedElement {
+	return parseForwarded(c.GetHeader("Forwarded"))
+}
+
+// parseForwarded parses the Forwarded header value like
+// `for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8::1]:4711"`.
+func parseForwarded(header string) (elements []ForwardedElement) {
+	var element ForwardedElement
+	empty := true
+	for i := 0; i < len(header); {
+		// parameter name
+		j := i
+		for j < len(header) && header[j] != '=' && header[j] != ';' && header[j] != ',' {
+			j++
+		}
+		name := strings.ToLower(strings.TrimSpace(header[i:j]))
+		var value string
+		if j < len(header) && header[j] == '=' {
+			j++
+			for j < len(header) && header[j] == ' ' {
+				j++
+			}
+			if j < len(header) && header[j] == '"' {
+				// quoted string with escapes
+				var b strings.Builder
+				for j++; j < len(header) && header[j] != '"'; j++ {
+					if header[j] == '\\' && j+1 < len(header) {
+						j++
+					}
+					b.WriteByte(header[j])
+				}
+				value = b.String()
+				j++
+			}
+			k := j
+			for j < len(header) && header[j] != ';' && header[j] != ',' {
+				j++
+			}
+			if value == "" {
+				value = strings.TrimSpace(header[k:j])
+			}
+		}
+		switch name {
+		case "for":
+			element.For, empty = value, false
+		case "by":
+			element.By, empty = value, false
+		case "host":
+			element.Host, empty = value, false
+		case "proto":
+			element.Proto, empty = strings.ToLower(value), false
+		}
+		if j >= len(header) || header[j] == ',' {
+			if !empty {
+				elements = append(elements, element)
+			}
+			element, empty = ForwardedElement{}, true
+		}
+		i = j + 1
+	}
+	return
+}
+
+// parseAccept parses the Accept-like header value into the ranges with qualities.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		value := strings.ToLower(strings.TrimSpace(params[0]))
+		if value == "" {
+			continue
+		}
+		r := acceptRange{value: value, q: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if len(param) > 2 && (param[0] == 'q' || param[0] == 'Q') && param[1] == '=' {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					r.q = q
+				}
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// negotiate returns the offer with the highest quality according to the header.
+// match returns the specificity of the range matching the offer, or -1.
+// The quality of the offer is taken from the most specific matching range.
+func negotiate(header string, offers []string, match func(r, offer string) int) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(header) == "" {
+		return offers[0]
+	}
+	ranges := parseAccept(header)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		lower := strings.ToLower(offer)
+		q, specificity := -1.0, -1
+		for _, r := range ranges {
+			if s := match(r.value, lower); s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+func matchMediaType(r, offer string) int {
+	if i := strings.IndexByte(offer, ';'); i >= 0 {
+		offer = strings.TrimSpace(offer[:i])
+	}
+	switch {
+	case r == offer:
+		return 2
+	case r == "*/*":
+		return 0
+	case strings.HasSuffix(r, "/*") && strings.HasPrefix(offer, r[:len(r)-1]):
+		return 1
+	}
+	return -1
+}
+
+func matchEncoding(r, offer string) int {
+	switch {
+	case r == offer:
+		return 1
+	case r == "*":
+		return 0
+	}
+	return -1
+}
+
+func matchLanguage(r, offer string) int {
+	switch {
+	case r == offer:
+		return 2
+	case r == "*":
+		return 0
+	case strings.HasPrefix(offer, r) && offer[len(r)] == '-':
+		return 1
+	}
+	return -1
+}
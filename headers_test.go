@@ -0,0 +1,62 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextAccepts(t *testing.T) {
+	tests := []struct {
+		header   string
+		offers   []string
+		expected string
+	}{
+		{"", []string{"application/json", "text/html"}, "application/json"},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", []string{"application/json", "text/html"}, "text/html"},
+		{"application/json;q=0.5, text/*", []string{"application/json", "text/plain"}, "text/plain"},
+		{"text/*;q=0.5, text/plain;q=0", []string{"text/plain", "text/html"}, "text/html"},
+		{"image/png", []string{"application/json"}, ""},
+		{"*/*", []string{"application/json", "text/html"}, "application/json"},
+	}
+	for _, test := range tests {
+		c := newTestContext("/", withHeader("Accept", test.header))
+		assert.Equal(t, test.expected, c.Accepts(test.offers...), test.header)
+	}
+
+	c := newTestContext("/", withHeader("Accept-Encoding", "gzip;q=0.8, br, *;q=0.1"))
+	assert.Equal(t, "br", c.AcceptsEncodings("gzip", "br"))
+	assert.Equal(t, "deflate", c.AcceptsEncodings("deflate"))
+
+	c = newTestContext("/", withHeader("Accept-Language", "fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7"))
+	assert.Equal(t, "fr", c.AcceptsLanguages("en", "fr"))
+	assert.Equal(t, "en-US", c.AcceptsLanguages("en-US", "de"))
+	assert.Equal(t, "", c.AcceptsLanguages("ru"))
+}
+
+func TestContextAuthorization(t *testing.T) {
+	token, ok := newTestContext("/", withHeader("Authorization", "Bearer abc.def")).BearerToken()
+	assert.Equal(t, "abc.def", token)
+	assert.True(t, ok)
+	_, ok = newTestContext("/", withHeader("Authorization", "Basic Zm9vOmJhcg==")).BearerToken()
+	assert.False(t, ok)
+
+	user, pass, ok := newTestContext("/", withHeader("Authorization", "Basic Zm9vOmJhcjpiYXo=")).BasicAuthCredentials()
+	assert.Equal(t, "foo", user)
+	assert.Equal(t, "bar:baz", pass)
+	assert.True(t, ok)
+	_, _, ok = newTestContext("/", withHeader("Authorization", "Basic !!!")).BasicAuthCredentials()
+	assert.False(t, ok)
+	_, _, ok = newTestContext("/").BasicAuthCredentials()
+	assert.False(t, ok)
+}
+
+func TestContextForwarded(t *testing.T) {
+	c := newTestContext("/", withHeader("Forwarded", `for=192.0.2.60;proto=HTTPS;by=203.0.113.43, For="[2001:db8:cafe::17]:4711";host="example.com", ,for=unknown`))
+	assert.Equal(t, []ForwardedElement{
+		{For: "192.0.2.60", By: "203.0.113.43", Proto: "https"},
+		{For: "[2001:db8:cafe::17]:4711", Host: "example.com"},
+		{For: "unknown"},
+	}, c.Forwarded())
+	assert.Nil(t, newTestContext("/").Forwarded())
+}
@@ -0,0 +1,52 @@
+package tokay
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AllowedHosts restricts the Host header values the engine responds to. Requests with other
+// hosts are rejected before routing with 421 Misdirected Request (400 Bad Request when the Host
+// header is missing). A "*.example.com" entry allows any subdomain of example.com.
+// The port in the Host header is ignored. An empty list disables the check.
+//
+//	engine.AllowedHosts([]string{"example.com", "*.example.com"})
+func (engine *Engine) AllowedHosts(hosts []string) {
+	allowed := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		allowed = append(allowed, strings.ToLower(host))
+	}
+	engine.allowedHosts = allowed
+}
+
+// isAllowedHost checks the host (with optional port) against the AllowedHosts list.
+func (engine *Engine) isAllowedHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+	for _, allowed := range engine.allowedHosts {
+		if allowed == host {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectHostHandler responds to requests with the Host header not listed in AllowedHosts.
+// It is the router answer, so the OnAbort hooks are not called.
+func rejectHostHandler(c *Context) {
+	code := http.StatusMisdirectedRequest
+	if len(c.Request.Header.Host()) == 0 {
+		code = http.StatusBadRequest
+	}
+	c.Error(http.StatusText(code), code)
+	c.skipHandlers()
+}
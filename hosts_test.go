@@ -0,0 +1,38 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllowedHosts(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) {
+		c.String(200, "ok")
+	})
+	engine.AllowedHosts([]string{"Example.com", "*.example.org"})
+	aborted := 0
+	engine.OnAbort(func(c *Context) {
+		aborted++
+	})
+
+	tests := []struct {
+		host string
+		code int
+	}{
+		{"example.com", 200},
+		{"EXAMPLE.com:8080", 200},
+		{"www.example.org", 200},
+		{"a.b.example.org", 200},
+		{"example.org", 421},
+		{"evil.com", 421},
+		{"example.com.evil.com", 421},
+		{"", 400},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, "/", withHeader("Host", test.host))
+		assert.Equal(t, test.code, ctx.Response.StatusCode(), test.host)
+	}
+	assert.Equal(t, 0, aborted)
+}
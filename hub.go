@@ -0,0 +1,208 @@
+package tokay
+
+import (
+	"bufio"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	websocket "github.com/night-codes/tokay-websocket"
+)
+
+// Hub broadcasts messages by topics to the WebSocket and event stream (SSE) clients.
+// With a shared Broker (see NewRedisBroker) the messages reach the clients of all server instances.
+type Hub struct {
+	// OnMessage handles the messages received from the WebSocket clients (see Websocket).
+	OnMessage  func(c *Context, message []byte)
+	broker     Broker
+	middleware []func(c *WSContext, next func())
+}
+
+// WSContext is the WebSocket message passed to the Hub message middlewares (see Hub.Use).
+type WSContext struct {
+	// Context is the context of the upgraded request.
+	*Context
+	// MessageType is the WebSocket message type (websocket.TextMessage or websocket.BinaryMessage).
+	MessageType int
+	// Message is the received message, the middlewares may replace it.
+	Message []byte
+	write   func(messageType int, message []byte) error
+}
+
+// Reply sends the text message to the client of the message.
+func (c *WSContext) Reply(message []byte) error {
+	return c.write(websocket.TextMessage, message)
+}
+
+// hubMessage is a message queued for the event stream client.
+type hubMessage struct {
+	topic   string
+	message []byte
+}
+
+// hubWriteTimeout is the timeout of writing a message to the WebSocket client.
+const hubWriteTimeout = 10 * time.Second
+
+// NewHub creates a new Hub. Without the broker the messages are delivered within the current process only.
+//
+//	hub := tokay.NewHub(broker)
+//	engine.GET("/ws", func(c *tokay.Context) {
+//		hub.Websocket(c, "chat")
+//	})
+//	engine.GET("/events", func(c *tokay.Context) {
+//		hub.EventStream(c, "chat")
+//	})
+//	engine.POST("/chat", func(c *tokay.Context) {
+//		hub.Broadcast("chat", c.PostBody())
+//	})
+func NewHub(broker ...Broker) *Hub {
+	if len(broker) != 0 && broker[0] != nil {
+		return &Hub{broker: broker[0]}
+	}
+	return &Hub{broker: NewLocalBroker()}
+}
+
+// Broadcast sends the message to the topic subscribers.
+func (h *Hub) Broadcast(topic string, message []byte) error {
+	return h.broker.Publish(topic, message)
+}
+
+// Subscribe registers the handler of the topic messages.
+func (h *Hub) Subscribe(topic string, handler func(message []byte)) (unsubscribe func(), err error) {
+	return h.broker.Subscribe(topic, handler)
+}
+
+// Use appends the middlewares of the messages received from the WebSocket clients. Like the HTTP middlewares
+// they are called in the order of registration before the OnMessage handler, and each of them calls next
+// to pass the message further or drops it otherwise. Use must be called before the clients connect.
+//
+//	hub.Use(func(c *tokay.WSContext, next func()) {
+//		if !limiter.Allow(c.ClientIP()) {
+//			c.Reply([]byte(`{"error":"rate limited"}`))
+//			return
+//		}
+//		next()
+//	})
+func (h *Hub) Use(middleware ...func(c *WSContext, next func())) {
+	h.middleware = append(h.middleware, middleware...)
+}
+
+// handleMessage passes the message through the middlewares to the OnMessage handler.
+func (h *Hub) handleMessage(c *WSContext, i int) {
+	if i < len(h.middleware) {
+		h.middleware[i](c, func() {
+			h.handleMessage(c, i+1)
+		})
+	} else if h.OnMessage != nil {
+		h.OnMessage(c.Context, c.Message)
+	}
+}
+
+// subscribe registers the handler of the topics messages and returns the function unsubscribing them all.
+func (h *Hub) subscribe(topics []string, handler func(topic string, message []byte)) (func(), error) {
+	var unsubscribes []func()
+	unsubscribe := func() {
+		for _, fn := range unsubscribes {
+			fn()
+		}
+	}
+	for _, topic := range topics {
+		topic := topic
+		fn, err := h.broker.Subscribe(topic, func(message []byte) {
+			handler(topic, message)
+		})
+		if err != nil {
+			unsubscribe()
+			return nil, err
+		}
+		unsubscribes = append(unsubscribes, fn)
+	}
+	return unsubscribe, nil
+}
+
+// Websocket upgrades the connection to the WebSocket protocol and sends the messages of the topics
+// to the client as text messages until it disconnects. The client messages are passed through the middlewares
+// (see Use) to the OnMessage handler.
+func (h *Hub) Websocket(c *Context, topics ...string) error {
+	return c.Websocket(func() {
+		conn := c.WSConn
+		var mu sync.Mutex
+		write := func(messageType int, message []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			conn.SetWriteDeadline(time.Now().Add(hubWriteTimeout))
+			return conn.WriteMessage(messageType, message)
+		}
+		unsubscribe, err := h.subscribe(topics, func(topic string, message []byte) {
+			write(websocket.TextMessage, message)
+		})
+		if err != nil {
+			conn.Close()
+			return
+		}
+		defer unsubscribe()
+		for {
+			messageType, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			h.handleMessage(&WSContext{Context: c, MessageType: messageType, Message: message, write: write}, 0)
+		}
+	})
+}
+
+// EventStream streams the messages of the topics to the client as server-sent events (the event name is the topic)
+// until the client disconnects or the server shuts down. The messages are dropped while the slow client
+// has 64 undelivered ones.
+func (h *Hub) EventStream(c *Context, topics ...string) {
+	c.SetContentType("text/event-stream")
+	c.Response.Header.Set("Cache-Control", "no-cache")
+	engine := c.engine
+	c.SetBodyStreamWriter(func(w *bufio.Writer) {
+		messages := make(chan hubMessage, 64)
+		unsubscribe, err := h.subscribe(topics, func(topic string, message []byte) {
+			select {
+			case messages <- hubMessage{topic, message}:
+			default:
+			}
+		})
+		if err != nil {
+			return
+		}
+		defer unsubscribe()
+		// the response headers are sent with the first body bytes
+		w.WriteString(": connected\n\n")
+		if w.Flush() != nil {
+			return
+		}
+
+		// the idle connection is checked with comments every 15 seconds
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		idle := 0
+		for {
+			select {
+			case m := <-messages:
+				idle = 0
+				w.WriteString("event: " + m.topic + "\n")
+				for _, line := range strings.Split(string(m.message), "\n") {
+					w.WriteString("data: " + line + "\n")
+				}
+				w.WriteString("\n")
+			case <-ticker.C:
+				if atomic.LoadInt32(&engine.draining) != 0 {
+					return
+				}
+				if idle++; idle < 15 {
+					continue
+				}
+				idle = 0
+				w.WriteString(": ping\n\n")
+			}
+			if w.Flush() != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,112 @@
+package tokay
+
+import (
+	"bufio"
+	"fmt"
+	"testing"
+	"time"
+
+	websocket "github.com/night-codes/tokay-websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+	received := make(chan string, 10)
+	hub.OnMessage = func(c *Context, message []byte) {
+		received <- string(message)
+	}
+	engine := New(&Config{Logger: &testLogger{}})
+	engine.GET("/ws", func(c *Context) {
+		hub.Websocket(c, "chat")
+	})
+	engine.GET("/events", func(c *Context) {
+		hub.EventStream(c, "chat", "news")
+	})
+	conn, err := runTestServer(engine)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer engine.Close()
+	defer conn.Close()
+	addr := engine.Addr().String()
+
+	ws, _, err := (&websocket.Dialer{}).Dial("ws://"+addr+"/ws", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer ws.Close()
+	fmt.Fprint(conn, "GET /events HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	events := bufio.NewReader(conn)
+	for line := ""; line != "\r\n"; line, _ = events.ReadString('\n') {
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Nil(t, ws.WriteMessage(websocket.TextMessage, []byte("from client")))
+	assert.Equal(t, "from client", receive(t, received))
+
+	assert.Nil(t, hub.Broadcast("chat", []byte("hello")))
+	_, message, err := ws.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "hello", string(message))
+
+	assert.Nil(t, hub.Broadcast("news", []byte("line1\nline2")))
+	var stream string
+	for len(stream) < 200 {
+		chunk, err := events.ReadString('\n')
+		if err != nil {
+			break
+		}
+		stream += chunk
+		if chunk == "data: line2\n" {
+			break
+		}
+	}
+	assert.Contains(t, stream, "event: chat\ndata: hello\n\n")
+	assert.Contains(t, stream, "event: news\ndata: line1\ndata: line2\n")
+}
+
+func TestHubUse(t *testing.T) {
+	hub := NewHub()
+	received := make(chan string, 10)
+	hub.OnMessage = func(c *Context, message []byte) {
+		received <- string(message)
+	}
+	hub.Use(func(c *WSContext, next func()) {
+		c.Message = append([]byte("user:"), c.Message...)
+		next()
+		received <- "logged"
+	}, func(c *WSContext, next func()) {
+		if string(c.Message) == "user:spam" {
+			c.Reply([]byte("dropped"))
+			return
+		}
+		next()
+	})
+	engine := New(&Config{Logger: &testLogger{}})
+	engine.GET("/ws", func(c *Context) {
+		hub.Websocket(c)
+	})
+	conn, err := runTestServer(engine)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer engine.Close()
+	defer conn.Close()
+
+	ws, _, err := (&websocket.Dialer{}).Dial("ws://"+engine.Addr().String()+"/ws", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer ws.Close()
+
+	assert.Nil(t, ws.WriteMessage(websocket.TextMessage, []byte("hi")))
+	assert.Equal(t, "user:hi", receive(t, received))
+	assert.Equal(t, "logged", receive(t, received))
+
+	assert.Nil(t, ws.WriteMessage(websocket.TextMessage, []byte("spam")))
+	_, message, err := ws.ReadMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "dropped", string(message))
+	assert.Equal(t, "logged", receive(t, received))
+}
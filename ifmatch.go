@@ -0,0 +1,102 @@
+package tokay
+
+import (
+	"net/http"
+	"strings"
+)
+
+// IfMatchConfig is a struct for specifying IfMatch middleware options.
+type IfMatchConfig struct {
+	// ETag returns the current entity tag of the requested resource, quoted or not
+	// ("abc", `"abc"` or `W/"abc"`). Empty tag means the resource does not exist.
+	ETag func(c *Context) (string, error)
+	// Methods are the HTTP methods requiring the If-Match header. Default is PUT, PATCH and DELETE.
+	Methods []string
+	// Optional allows requests without the If-Match header instead of answering 428 Precondition Required.
+	Optional bool
+}
+
+// IfMatch returns a middleware implementing optimistic locking of REST resources (RFC 7232):
+// the modifying requests must have the If-Match header matching the current resource ETag.
+// Requests without the header are rejected with 428 Precondition Required, requests with
+// outdated tags are rejected with 412 Precondition Failed.
+//
+//	users := engine.Group("/users", tokay.IfMatch(tokay.IfMatchConfig{
+//		ETag: func(c *tokay.Context) (string, error) {
+//			user, err := db.FindUser(c.Param("id"))
+//			if err != nil || user == nil {
+//				return "", err
+//			}
+//			return user.Version, nil
+//		},
+//	}))
+func IfMatch(config IfMatchConfig) Handler {
+	if config.ETag == nil {
+		panic("IfMatch: ETag resolver is required")
+	}
+	methods := config.Methods
+	if len(methods) == 0 {
+		methods = []string{"PUT", "PATCH", "DELETE"}
+	}
+
+	return func(c *Context) {
+		if !inStrings(c.Method(), methods) {
+			return
+		}
+		header := c.GetHeader("If-Match")
+		if header == "" {
+			if !config.Optional {
+				c.AbortWithError(http.StatusPreconditionRequired, nil)
+			}
+			return
+		}
+		etag, err := config.ETag(c)
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if !ETagMatch(header, etag) {
+			c.AbortWithError(http.StatusPreconditionFailed, nil)
+		}
+	}
+}
+
+// ETagMatch reports whether the If-Match header value matches the entity tag using the strong
+// comparison: weak tags never match. "*" matches any existing resource (non-empty tag).
+func ETagMatch(header, etag string) bool {
+	etag = quoteETag(etag)
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// SetETag sets the ETag response header, quoting the tag if necessary.
+func (c *Context) SetETag(etag string) {
+	c.Response.Header.Set("ETag", quoteETag(etag))
+}
+
+// quoteETag returns the entity tag in the quoted form (`"abc"` or `W/"abc"`).
+func quoteETag(etag string) string {
+	quoted := strings.TrimPrefix(etag, "W/")
+	if etag == "" || len(quoted) > 1 && quoted[0] == '"' && quoted[len(quoted)-1] == '"' {
+		return etag
+	}
+	return `"` + etag + `"`
+}
+
+// inStrings reports whether the list contains the string.
+func inStrings(s string, list []string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package tokay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestETagMatch(t *testing.T) {
+	assert.True(t, ETagMatch(`"v1"`, "v1"))
+	assert.True(t, ETagMatch(`"v0", "v1"`, `"v1"`))
+	assert.True(t, ETagMatch(`*`, "v1"))
+	assert.False(t, ETagMatch(`*`, ""))
+	assert.False(t, ETagMatch(`"v2"`, "v1"))
+	assert.False(t, ETagMatch(`W/"v1"`, `W/"v1"`))
+	assert.False(t, ETagMatch(`W/"v1"`, "v1"))
+}
+
+func TestIfMatch(t *testing.T) {
+	versions := map[string]string{"1": "v1"}
+	engine := New()
+	engine.Use(IfMatch(IfMatchConfig{
+		ETag: func(c *Context) (string, error) {
+			if c.Param("id") == "err" {
+				return "", errors.New("db error")
+			}
+			return versions[c.Param("id")], nil
+		},
+	}))
+	engine.To("GET,PUT,DELETE", "/users/<id>", func(c *Context) {
+		c.SetETag(versions[c.Param("id")])
+		c.String(200, "OK")
+	})
+
+	tests := []struct {
+		method, path, ifMatch string
+		status                int
+	}{
+		{"GET", "/users/1", "", 200},
+		{"PUT", "/users/1", "", 428},
+		{"PUT", "/users/1", `"v1"`, 200},
+		{"PUT", "/users/1", `"v0"`, 412},
+		{"DELETE", "/users/1", "*", 200},
+		{"DELETE", "/users/2", "*", 412},
+		{"PUT", "/users/err", `"v1"`, 500},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, test.path, withMethod(test.method), withHeader("If-Match", test.ifMatch))
+		assert.Equal(t, test.status, ctx.Response.StatusCode(), test.method+" "+test.path+" "+test.ifMatch)
+	}
+
+	ctx := serveTestRequest(engine, "/users/1")
+	assert.Equal(t, `"v1"`, string(ctx.Response.Header.Peek("ETag")))
+}
@@ -0,0 +1,48 @@
+package tokay
+
+import "sync"
+
+// internedNames keeps the interned route parameter names (see internName).
+var internedNames = struct {
+	sync.Mutex
+	names map[string]string
+}{names: make(map[string]string)}
+
+// internMethod returns the constant string of the standard HTTP method, so the method
+// is not allocated per request. Other methods are copied.
+func internMethod(method []byte) string {
+	switch string(method) {
+	case "GET":
+		return "GET"
+	case "HEAD":
+		return "HEAD"
+	case "POST":
+		return "POST"
+	case "PUT":
+		return "PUT"
+	case "PATCH":
+		return "PATCH"
+	case "DELETE":
+		return "DELETE"
+	case "OPTIONS":
+		return "OPTIONS"
+	case "CONNECT":
+		return "CONNECT"
+	case "TRACE":
+		return "TRACE"
+	}
+	return string(method)
+}
+
+// internName returns the shared copy of the route parameter name, so the parameter names of all
+// the routes do not keep the route paths they are sliced from.
+func internName(name string) string {
+	internedNames.Lock()
+	defer internedNames.Unlock()
+	if interned, ok := internedNames.names[name]; ok {
+		return interned
+	}
+	name = string(append([]byte(nil), name...))
+	internedNames.names[name] = name
+	return name
+}
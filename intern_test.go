@@ -0,0 +1,40 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInternMethod(t *testing.T) {
+	for _, method := range append(Methods, "PROPFIND") {
+		assert.Equal(t, method, internMethod([]byte(method)))
+	}
+	method := []byte("DELETE")
+	assert.Equal(t, 0.0, testing.AllocsPerRun(100, func() {
+		internMethod(method)
+	}))
+}
+
+func TestInternName(t *testing.T) {
+	path := "/users/<id>"
+	name := internName(path[8:10])
+	assert.Equal(t, "id", name)
+	assert.Equal(t, name, internName("id"))
+}
+
+func TestRequestAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	engine := New()
+	engine.GET("/users/<id>/posts/<post>", func(c *Context) {
+		c.Param("post")
+		c.Method()
+	})
+	ctx := serveTestRequest(engine, "/users/1/posts/2")
+	allocs := testing.AllocsPerRun(100, func() {
+		engine.HandleRequest(ctx)
+	})
+	assert.True(t, allocs <= 1, "allocations per request: %v", allocs)
+}
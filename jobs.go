@@ -0,0 +1,158 @@
+package tokay
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Job states.
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+type (
+	// JobStatus is the status of a long-running operation (see Context.AcceptedWithStatusURL).
+	JobStatus struct {
+		ID    string `json:"id"`
+		State string `json:"state"`
+		// Progress is the optional completion percentage.
+		Progress int `json:"progress,omitempty"`
+		// Result is the operation result returned by the status route.
+		Result interface{} `json:"result,omitempty"`
+		// ResultURL is the location of the created resource. The status route redirects the clients
+		// to it with 303 See Other when the job is succeeded.
+		ResultURL string    `json:"result_url,omitempty"`
+		Error     string    `json:"error,omitempty"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// JobStore keeps the statuses of the jobs. Get returns nil status for unknown jobs.
+	JobStore interface {
+		Get(id string) (*JobStatus, error)
+		Set(status *JobStatus) error
+	}
+
+	// memoryJobStore keeps the job statuses in memory.
+	memoryJobStore struct {
+		sync.Mutex
+		ttl  time.Duration
+		jobs map[string]*JobStatus
+	}
+
+	// jobStatusRoute is the job status route registered by JobStatus.
+	jobStatusRoute struct {
+		route *Route
+		store JobStore
+	}
+)
+
+// Done reports whether the job is finished.
+func (s *JobStatus) Done() bool {
+	return s.State == JobSucceeded || s.State == JobFailed
+}
+
+// NewMemoryJobStore creates the JobStore keeping the statuses in memory. The finished jobs are
+// removed after the ttl (default is 1 hour).
+func NewMemoryJobStore(ttl ...time.Duration) JobStore {
+	s := &memoryJobStore{ttl: time.Hour, jobs: make(map[string]*JobStatus)}
+	if len(ttl) != 0 && ttl[0] > 0 {
+		s.ttl = ttl[0]
+	}
+	return s
+}
+
+// Get implements JobStore.
+func (s *memoryJobStore) Get(id string) (*JobStatus, error) {
+	s.Lock()
+	defer s.Unlock()
+	if status, ok := s.jobs[id]; ok {
+		copy := *status
+		return &copy, nil
+	}
+	return nil, nil
+}
+
+// Set implements JobStore.
+func (s *memoryJobStore) Set(status *JobStatus) error {
+	copy := *status
+	s.Lock()
+	defer s.Unlock()
+	expired := time.Now().Add(-s.ttl)
+	for id, job := range s.jobs {
+		if job.Done() && job.UpdatedAt.Before(expired) {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobs[status.ID] = &copy
+	return nil
+}
+
+// JobStatus registers the GET route returning the job statuses from the store at path + "/<id>".
+// The succeeded jobs with ResultURL are redirected to the result with 303 See Other.
+//
+//	store := tokay.NewMemoryJobStore()
+//	engine.JobStatus("/jobs", store)
+//	engine.POST("/reports", func(c *tokay.Context) {
+//		id := newID()
+//		go buildReport(id, store)
+//		c.AcceptedWithStatusURL(id)
+//	})
+func (engine *Engine) JobStatus(path string, store JobStore) *Route {
+	route := engine.GET(path+"/<id>", func(c *Context) {
+		status, err := store.Get(c.Param("id"))
+		if err != nil {
+			c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		if status == nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		if status.State == JobSucceeded && status.ResultURL != "" {
+			c.Header("Location", status.ResultURL)
+			c.JSON(http.StatusSeeOther, status)
+			return
+		}
+		if !status.Done() {
+			c.Header("Retry-After", "1")
+		}
+		c.JSON(http.StatusOK, status)
+	})
+	engine.jobs = &jobStatusRoute{route: route, store: store}
+	return route
+}
+
+// AcceptedWithStatusURL responds with 202 Accepted and the Location header of the job status
+// (see Engine.JobStatus) for the long-running operation. The job is stored as pending unless
+// the store already has its status.
+func (c *Context) AcceptedWithStatusURL(jobID string) {
+	jobs := c.engine.jobs
+	if jobs == nil {
+		c.AbortWithError(http.StatusInternalServerError, errors.New("job status route is not registered"))
+		return
+	}
+	status, err := jobs.store.Get(jobID)
+	if err == nil && status == nil {
+		now := time.Now()
+		status = &JobStatus{ID: jobID, State: JobPending, CreatedAt: now, UpdatedAt: now}
+		err = jobs.store.Set(status)
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	location := jobs.route.URL("id", jobID)
+	c.Header("Location", location)
+	c.Header("Retry-After", "1")
+	c.JSON(http.StatusAccepted, map[string]string{
+		"id":         jobID,
+		"state":      status.State,
+		"status_url": location,
+	})
+}
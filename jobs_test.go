@@ -0,0 +1,63 @@
+package tokay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobStatus(t *testing.T) {
+	accepted := func(c *Context) {
+		c.AcceptedWithStatusURL("r1")
+	}
+	ctx := newTestRequest("/reports", withMethod("POST"))
+
+	// the job status route is not registered
+	engine := New()
+	engine.POST("/reports", accepted)
+	engine.HandleRequest(ctx)
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+
+	engine = New()
+	store := NewMemoryJobStore()
+	engine.JobStatus("/jobs", store)
+	engine.POST("/reports", accepted)
+	ctx.Response.Reset()
+	engine.HandleRequest(ctx)
+	assert.Equal(t, 202, ctx.Response.StatusCode())
+	assert.Equal(t, "/jobs/r1", string(ctx.Response.Header.Peek("Location")))
+	assert.JSONEq(t, `{"id":"r1","state":"pending","status_url":"/jobs/r1"}`, string(ctx.Response.Body()))
+
+	ctx = serveTestRequest(engine, "/jobs/r1")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek("Retry-After")))
+	assert.Contains(t, string(ctx.Response.Body()), `"state":"pending"`)
+
+	status, _ := store.Get("r1")
+	status.State, status.ResultURL = JobSucceeded, "/reports/r1"
+	assert.Nil(t, store.Set(status))
+	ctx = serveTestRequest(engine, "/jobs/r1")
+	assert.Equal(t, 303, ctx.Response.StatusCode())
+	assert.Equal(t, "/reports/r1", string(ctx.Response.Header.Peek("Location")))
+
+	ctx = serveTestRequest(engine, "/jobs/unknown")
+	assert.Equal(t, 404, ctx.Response.StatusCode())
+}
+
+func TestMemoryJobStore(t *testing.T) {
+	store := NewMemoryJobStore(time.Minute)
+	old := time.Now().Add(-time.Hour)
+	assert.Nil(t, store.Set(&JobStatus{ID: "done", State: JobFailed, UpdatedAt: old}))
+	assert.Nil(t, store.Set(&JobStatus{ID: "running", State: JobRunning, UpdatedAt: old}))
+	assert.Nil(t, store.Set(&JobStatus{ID: "new", State: JobPending, UpdatedAt: time.Now()}))
+
+	status, err := store.Get("done")
+	assert.Nil(t, err)
+	assert.Nil(t, status)
+	status, _ = store.Get("running")
+	assert.Equal(t, JobRunning, status.State)
+	status.State = JobSucceeded
+	status, _ = store.Get("running")
+	assert.Equal(t, JobRunning, status.State)
+}
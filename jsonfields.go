@@ -0,0 +1,294 @@
+package tokay
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/night-codes/go-json"
+)
+
+// fieldMask is the parsed fields selector: the selected JSON fields by names with their own selectors.
+// The nil selector selects the whole value.
+type fieldMask map[string]fieldMask
+
+// jsonField is the JSON field of a struct type.
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// jsonFieldsCache keeps the []jsonField of the struct types.
+var jsonFieldsCache sync.Map
+
+var errFieldsSelector = errors.New("invalid fields selector")
+
+// JSONFields writes the JSON response with the fields of obj selected by the fields selector only,
+// for the partial responses. The selector is the comma-separated list of the JSON field names (see the
+// "json" struct tags), the nested fields are selected in parentheses or with the dotted names. The
+// selector applies to every element of the slices, the maps with the string keys are filtered by the keys.
+// The empty selector writes the whole obj, the malformed one gets the 400 error.
+//
+//	// GET /users/1?fields=id,name,posts(title,tags),company.name
+//	c.JSONFields(200, user, c.Query("fields"))
+func (c *Context) JSONFields(statusCode int, obj interface{}, fields string) {
+	if strings.TrimSpace(fields) == "" {
+		c.JSON(statusCode, obj)
+		return
+	}
+	mask, err := parseFieldMask(fields)
+	if err != nil {
+		c.Error(err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.JSON(statusCode, filterFields(reflect.ValueOf(obj), mask))
+}
+
+// parseFieldMask parses the fields selector like "id,name,posts(title,tags),company.name".
+func parseFieldMask(fields string) (fieldMask, error) {
+	mask, rest, err := parseFieldList(fields, 0)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, errFieldsSelector
+	}
+	return mask, nil
+}
+
+// parseFieldList parses the comma-separated fields until the closing parenthesis or the end of s,
+// returning the rest of s starting with the parenthesis.
+func parseFieldList(s string, depth int) (fieldMask, string, error) {
+	if depth > 32 {
+		return nil, "", errFieldsSelector
+	}
+	mask := fieldMask{}
+	for {
+		i := strings.IndexAny(s, ",()")
+		if i < 0 {
+			i = len(s)
+		}
+		name := strings.TrimSpace(s[:i])
+		s = s[i:]
+		var sub fieldMask
+		if s != "" && s[0] == '(' {
+			var err error
+			if sub, s, err = parseFieldList(s[1:], depth+1); err != nil {
+				return nil, "", err
+			}
+			if s == "" || s[0] != ')' || len(sub) == 0 {
+				return nil, "", errFieldsSelector
+			}
+			s = strings.TrimLeft(s[1:], " ")
+		}
+		if name == "" {
+			return nil, "", errFieldsSelector
+		}
+		path := strings.Split(name, ".")
+		for j := len(path) - 1; j >= 0; j-- {
+			if path[j] == "" {
+				return nil, "", errFieldsSelector
+			}
+			if j > 0 {
+				sub = fieldMask{path[j]: sub}
+			}
+		}
+		mask.merge(path[0], sub)
+		if s == "" || s[0] == ')' {
+			return mask, s, nil
+		}
+		if s[0] != ',' {
+			return nil, "", errFieldsSelector
+		}
+		s = s[1:]
+	}
+}
+
+// merge adds the field selector to the mask, the whole field selection wins.
+func (mask fieldMask) merge(name string, sub fieldMask) {
+	existing, ok := mask[name]
+	switch {
+	case !ok:
+		mask[name] = sub
+	case existing == nil || sub == nil:
+		mask[name] = nil
+	default:
+		for n, s := range sub {
+			existing.merge(n, s)
+		}
+	}
+}
+
+// orderedObject is the JSON object keeping the order of the struct fields.
+type orderedObject []orderedField
+
+type orderedField struct {
+	name  string
+	value interface{}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+var marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// filterFields returns the value with the fields selected by the mask.
+func filterFields(v reflect.Value, mask fieldMask) interface{} {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil
+		}
+		if mask != nil && v.Type().Implements(marshalerType) {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	if mask == nil || v.Type().Implements(marshalerType) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		obj := orderedObject{}
+		for _, f := range cachedJSONFields(v.Type()) {
+			sub, ok := mask[f.name]
+			if !ok {
+				continue
+			}
+			fv, ok := fieldByIndex(v, f.index)
+			if !ok || f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			obj = append(obj, orderedField{f.name, filterFields(fv, sub)})
+		}
+		return obj
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		if v.IsNil() {
+			return nil
+		}
+		obj := make(map[string]interface{}, len(mask))
+		for name, sub := range mask {
+			if fv := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())); fv.IsValid() {
+				obj[name] = filterFields(fv, sub)
+			}
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = filterFields(v.Index(i), mask)
+		}
+		return list
+	}
+	return v.Interface()
+}
+
+// cachedJSONFields returns the JSON fields of the struct type, including the fields of the embedded structs.
+func cachedJSONFields(t reflect.Type) []jsonField {
+	if fields, ok := jsonFieldsCache.Load(t); ok {
+		return fields.([]jsonField)
+	}
+	fields := structJSONFields(t, nil, 0)
+	jsonFieldsCache.Store(t, fields)
+	return fields
+}
+
+func structJSONFields(t reflect.Type, index []int, depth int) []jsonField {
+	var fields []jsonField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if j := strings.IndexByte(tag, ','); j >= 0 {
+			name, opts = tag[:j], tag[j:]
+		}
+		fieldIndex := append(append([]int(nil), index...), i)
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && depth < 8 {
+				fields = append(fields, structJSONFields(ft, fieldIndex, depth+1)...)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			// unexported
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, jsonField{name: name, index: fieldIndex, omitEmpty: strings.Contains(opts, ",omitempty")})
+	}
+	return fields
+}
+
+// fieldByIndex returns the nested field, false if an embedded struct pointer is nil.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
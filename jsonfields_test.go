@@ -0,0 +1,91 @@
+package tokay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldsAudit struct {
+	Created time.Time `json:"created"`
+	Editor  string    `json:"editor,omitempty"`
+}
+
+type fieldsPost struct {
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+	Views int      `json:"views"`
+}
+
+type fieldsUser struct {
+	*fieldsAudit
+	ID      int          `json:"id"`
+	Name    string       `json:"name"`
+	Email   string       `json:"email,omitempty"`
+	Secret  string       `json:"-"`
+	Posts   []fieldsPost `json:"posts"`
+	Company *struct{ Name, City string }
+	Meta    map[string]interface{} `json:"meta"`
+}
+
+func TestParseFieldMask(t *testing.T) {
+	mask, err := parseFieldMask("id, name,posts(title,tags),company.name,company(city),meta.a.b")
+	assert.Nil(t, err)
+	assert.Equal(t, fieldMask{
+		"id":      nil,
+		"name":    nil,
+		"posts":   fieldMask{"title": nil, "tags": nil},
+		"company": fieldMask{"name": nil, "city": nil},
+		"meta":    fieldMask{"a": fieldMask{"b": nil}},
+	}, mask)
+
+	mask, err = parseFieldMask("posts(title),posts")
+	assert.Nil(t, err)
+	assert.Equal(t, fieldMask{"posts": nil}, mask)
+
+	for _, fields := range []string{"id,", "a(b", "a)b", "a()", "a(b)c", ",a", "a..b"} {
+		_, err = parseFieldMask(fields)
+		assert.NotNil(t, err, fields)
+	}
+}
+
+func TestContextJSONFields(t *testing.T) {
+	user := fieldsUser{
+		fieldsAudit: &fieldsAudit{Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		ID:          1,
+		Name:        "John",
+		Secret:      "s",
+		Posts:       []fieldsPost{{"First", []string{"go"}, 10}, {"Second", nil, 20}},
+		Company:     &struct{ Name, City string }{"ACME", "Kyiv"},
+		Meta:        map[string]interface{}{"a": map[string]int{"b": 1, "c": 2}, "d": true},
+	}
+	engine := New()
+	engine.GET("/user", func(c *Context) {
+		c.JSONFields(200, &user, c.Query("fields"))
+	})
+
+	tests := []struct {
+		fields string
+		status int
+		body   string
+	}{
+		{"name,id", 200, `{"id":1,"name":"John"}`},
+		{"email,editor,created,Secret", 200, `{"created":"2024-01-02T03:04:05Z"}`},
+		{"posts(title,views)", 200, `{"posts":[{"title":"First","views":10},{"title":"Second","views":20}]}`},
+		{"posts.tags", 200, `{"posts":[{"tags":["go"]},{"tags":null}]}`},
+		{"Company.City", 200, `{"Company":{"City":"Kyiv"}}`},
+		{"meta(a.c,x)", 200, `{"meta":{"a":{"c":2}}}`},
+		{"id(", 400, "invalid fields selector"},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, "/user?fields="+test.fields)
+		assert.Equal(t, test.status, ctx.Response.StatusCode(), test.fields)
+		assert.Equal(t, test.body, string(ctx.Response.Body()), test.fields)
+	}
+
+	ctx := serveTestRequest(engine, "/user")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Contains(t, string(ctx.Response.Body()), `"name":"John"`)
+	assert.NotContains(t, string(ctx.Response.Body()), "Secret")
+}
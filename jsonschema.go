@@ -0,0 +1,548 @@
+package tokay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/night-codes/go-json"
+	"github.com/night-codes/govalidator"
+)
+
+type (
+	// JSONSchema is the compiled JSON Schema document (draft 2020-12). The validation vocabulary is supported
+	// except the unevaluated* and $dynamicRef keywords; $ref must point within the document ("#/$defs/name").
+	JSONSchema struct {
+		root    interface{}
+		regexps map[string]*regexp.Regexp
+	}
+
+	// SchemaError is the JSON Schema violation at the JSON pointer of the instance, like "/items/0/name".
+	SchemaError struct {
+		Pointer string `json:"pointer"`
+		Message string `json:"message"`
+	}
+
+	// schemaErrors is the response body of the invalid requests and responses.
+	schemaErrors struct {
+		Errors []SchemaError `json:"errors"`
+	}
+)
+
+// Error implements error.
+func (e SchemaError) Error() string {
+	return e.Pointer + ": " + e.Message
+}
+
+// CompileJSONSchema parses the JSON Schema document.
+func CompileJSONSchema(schema []byte) (*JSONSchema, error) {
+	s := &JSONSchema{regexps: make(map[string]*regexp.Regexp)}
+	if err := json.Unmarshal(schema, &s.root); err != nil {
+		return nil, err
+	}
+	if err := s.compileRegexps(s.root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// compileRegexps compiles the pattern and patternProperties regular expressions of the schema.
+func (s *JSONSchema) compileRegexps(schema interface{}) error {
+	switch schema := schema.(type) {
+	case map[string]interface{}:
+		var patterns []string
+		if pattern, ok := schema["pattern"].(string); ok {
+			patterns = append(patterns, pattern)
+		}
+		if properties, ok := schema["patternProperties"].(map[string]interface{}); ok {
+			for pattern := range properties {
+				patterns = append(patterns, pattern)
+			}
+		}
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return err
+			}
+			s.regexps[pattern] = re
+		}
+		for _, value := range schema {
+			if err := s.compileRegexps(value); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, value := range schema {
+			if err := s.compileRegexps(value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Validate validates the JSON document and returns the violations.
+func (s *JSONSchema) Validate(data []byte) []SchemaError {
+	var instance interface{}
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return []SchemaError{{Pointer: "", Message: "invalid JSON: " + err.Error()}}
+	}
+	return s.ValidateValue(instance)
+}
+
+// ValidateValue validates the decoded JSON value (map[string]interface{}, []interface{}, float64 etc.).
+func (s *JSONSchema) ValidateValue(instance interface{}) []SchemaError {
+	v := &schemaValidator{schema: s}
+	v.validate(s.root, instance, "", 0)
+	return v.errors
+}
+
+// maxSchemaDepth limits the $ref recursion.
+const maxSchemaDepth = 64
+
+// schemaValidator collects the violations.
+type schemaValidator struct {
+	schema *JSONSchema
+	errors []SchemaError
+}
+
+func (v *schemaValidator) fail(pointer, format string, args ...interface{}) {
+	v.errors = append(v.errors, SchemaError{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid reports whether the instance is valid against the subschema without collecting the violations.
+func (v *schemaValidator) valid(schema, instance interface{}, depth int) bool {
+	sub := &schemaValidator{schema: v.schema}
+	sub.validate(schema, instance, "", depth)
+	return len(sub.errors) == 0
+}
+
+func (v *schemaValidator) validate(schema, instance interface{}, pointer string, depth int) {
+	if depth > maxSchemaDepth {
+		v.fail(pointer, "schema is too deep")
+		return
+	}
+	switch schema := schema.(type) {
+	case bool:
+		if !schema {
+			v.fail(pointer, "value is not allowed")
+		}
+		return
+	case map[string]interface{}:
+		v.validateObjectSchema(schema, instance, pointer, depth)
+	}
+}
+
+func (v *schemaValidator) validateObjectSchema(schema map[string]interface{}, instance interface{}, pointer string, depth int) {
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := v.schema.resolve(ref)
+		if err != nil {
+			v.fail(pointer, "%v", err)
+			return
+		}
+		v.validate(target, instance, pointer, depth+1)
+	}
+
+	if t, ok := schema["type"]; ok && !matchesType(t, instance) {
+		v.fail(pointer, "must be %s", typeNames(t))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, value := range enum {
+			if reflect.DeepEqual(value, instance) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(pointer, "must be one of the enumerated values")
+		}
+	}
+	if value, ok := schema["const"]; ok && !reflect.DeepEqual(value, instance) {
+		v.fail(pointer, "must be equal to the constant")
+	}
+
+	v.validateCombinators(schema, instance, pointer, depth)
+
+	switch instance := instance.(type) {
+	case string:
+		v.validateString(schema, instance, pointer)
+	case float64:
+		v.validateNumber(schema, instance, pointer)
+	case []interface{}:
+		v.validateArray(schema, instance, pointer, depth)
+	case map[string]interface{}:
+		v.validateObject(schema, instance, pointer, depth)
+	}
+}
+
+func (v *schemaValidator) validateCombinators(schema map[string]interface{}, instance interface{}, pointer string, depth int) {
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			v.validate(sub, instance, pointer, depth+1)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if v.valid(sub, instance, depth+1) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			v.fail(pointer, "must match at least one schema of anyOf")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			if v.valid(sub, instance, depth+1) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			v.fail(pointer, "must match exactly one schema of oneOf, matches %d", matched)
+		}
+	}
+	if not, ok := schema["not"]; ok && v.valid(not, instance, depth+1) {
+		v.fail(pointer, "must not match the schema of not")
+	}
+	if cond, ok := schema["if"]; ok {
+		if v.valid(cond, instance, depth+1) {
+			if then, ok := schema["then"]; ok {
+				v.validate(then, instance, pointer, depth+1)
+			}
+		} else if els, ok := schema["else"]; ok {
+			v.validate(els, instance, pointer, depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) validateString(schema map[string]interface{}, s, pointer string) {
+	length := float64(utf8.RuneCountInString(s))
+	if min, ok := schema["minLength"].(float64); ok && length < min {
+		v.fail(pointer, "must be at least %v characters long", min)
+	}
+	if max, ok := schema["maxLength"].(float64); ok && length > max {
+		v.fail(pointer, "must be at most %v characters long", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok && !v.schema.regexps[pattern].MatchString(s) {
+		v.fail(pointer, "must match the pattern %q", pattern)
+	}
+	if format, ok := schema["format"].(string); ok && !validFormat(format, s) {
+		v.fail(pointer, "must be a valid %s", format)
+	}
+}
+
+func (v *schemaValidator) validateNumber(schema map[string]interface{}, n float64, pointer string) {
+	if min, ok := schema["minimum"].(float64); ok && n < min {
+		v.fail(pointer, "must be greater than or equal to %v", min)
+	}
+	if max, ok := schema["maximum"].(float64); ok && n > max {
+		v.fail(pointer, "must be less than or equal to %v", max)
+	}
+	if min, ok := schema["exclusiveMinimum"].(float64); ok && n <= min {
+		v.fail(pointer, "must be greater than %v", min)
+	}
+	if max, ok := schema["exclusiveMaximum"].(float64); ok && n >= max {
+		v.fail(pointer, "must be less than %v", max)
+	}
+	if divisor, ok := schema["multipleOf"].(float64); ok && divisor > 0 {
+		if q := n / divisor; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.fail(pointer, "must be a multiple of %v", divisor)
+		}
+	}
+}
+
+func (v *schemaValidator) validateArray(schema map[string]interface{}, items []interface{}, pointer string, depth int) {
+	count := float64(len(items))
+	if min, ok := schema["minItems"].(float64); ok && count < min {
+		v.fail(pointer, "must have at least %v items", min)
+	}
+	if max, ok := schema["maxItems"].(float64); ok && count > max {
+		v.fail(pointer, "must have at most %v items", max)
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+	uniqueness:
+		for i := range items {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(items[i], items[j]) {
+					v.fail(pointer, "items %d and %d must be unique", j, i)
+					break uniqueness
+				}
+			}
+		}
+	}
+
+	prefix, _ := schema["prefixItems"].([]interface{})
+	for i, item := range items {
+		itemPointer := pointer + "/" + strconv.Itoa(i)
+		if i < len(prefix) {
+			v.validate(prefix[i], item, itemPointer, depth+1)
+		} else if itemSchema, ok := schema["items"]; ok {
+			v.validate(itemSchema, item, itemPointer, depth+1)
+		}
+	}
+
+	if contains, ok := schema["contains"]; ok {
+		matched := 0
+		for _, item := range items {
+			if v.valid(contains, item, depth+1) {
+				matched++
+			}
+		}
+		min := 1.0
+		if value, ok := schema["minContains"].(float64); ok {
+			min = value
+		}
+		if float64(matched) < min {
+			v.fail(pointer, "must contain at least %v matching items", min)
+		}
+		if max, ok := schema["maxContains"].(float64); ok && float64(matched) > max {
+			v.fail(pointer, "must contain at most %v matching items", max)
+		}
+	}
+}
+
+func (v *schemaValidator) validateObject(schema map[string]interface{}, object map[string]interface{}, pointer string, depth int) {
+	count := float64(len(object))
+	if min, ok := schema["minProperties"].(float64); ok && count < min {
+		v.fail(pointer, "must have at least %v properties", min)
+	}
+	if max, ok := schema["maxProperties"].(float64); ok && count > max {
+		v.fail(pointer, "must have at most %v properties", max)
+	}
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if name, ok := name.(string); ok {
+				if _, ok := object[name]; !ok {
+					v.fail(pointer+"/"+escapePointer(name), "is required")
+				}
+			}
+		}
+	}
+	if dependent, ok := schema["dependentRequired"].(map[string]interface{}); ok {
+		for name, required := range dependent {
+			if _, ok := object[name]; !ok {
+				continue
+			}
+			names, _ := required.([]interface{})
+			for _, r := range names {
+				if r, ok := r.(string); ok {
+					if _, ok := object[r]; !ok {
+						v.fail(pointer+"/"+escapePointer(r), "is required when %q is present", name)
+					}
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	// the violations are reported in the stable order
+	sort.Strings(names)
+	for _, name := range names {
+		value := object[name]
+		propertyPointer := pointer + "/" + escapePointer(name)
+		if propertyNames, ok := schema["propertyNames"]; ok && !v.valid(propertyNames, name, depth+1) {
+			v.fail(propertyPointer, "property name is not allowed")
+		}
+		evaluated := false
+		if propertySchema, ok := properties[name]; ok {
+			evaluated = true
+			v.validate(propertySchema, value, propertyPointer, depth+1)
+		}
+		for pattern, patternSchema := range patternProperties {
+			if v.schema.regexps[pattern].MatchString(name) {
+				evaluated = true
+				v.validate(patternSchema, value, propertyPointer, depth+1)
+			}
+		}
+		if additional, ok := schema["additionalProperties"]; ok && !evaluated {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.fail(propertyPointer, "property is not allowed")
+			} else {
+				v.validate(additional, value, propertyPointer, depth+1)
+			}
+		}
+	}
+}
+
+// resolve returns the subschema referenced by the "#/json/pointer" reference.
+func (s *JSONSchema) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, errors.New("unsupported $ref " + ref)
+	}
+	target := s.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref[1:], "/"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch node := target.(type) {
+		case map[string]interface{}:
+			target = node[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, errors.New("invalid $ref " + ref)
+			}
+			target = node[i]
+		default:
+			target = nil
+		}
+		if target == nil {
+			return nil, errors.New("invalid $ref " + ref)
+		}
+	}
+	return target, nil
+}
+
+// matchesType reports whether the instance has the JSON type (or one of the types).
+func matchesType(t interface{}, instance interface{}) bool {
+	switch t := t.(type) {
+	case string:
+		return jsonType(t, instance)
+	case []interface{}:
+		for _, name := range t {
+			if name, ok := name.(string); ok && jsonType(name, instance) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func jsonType(name string, instance interface{}) bool {
+	switch name {
+	case "null":
+		return instance == nil
+	case "boolean":
+		_, ok := instance.(bool)
+		return ok
+	case "string":
+		_, ok := instance.(string)
+		return ok
+	case "number":
+		_, ok := instance.(float64)
+		return ok
+	case "integer":
+		n, ok := instance.(float64)
+		return ok && n == math.Trunc(n)
+	case "array":
+		_, ok := instance.([]interface{})
+		return ok
+	case "object":
+		_, ok := instance.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+// typeNames returns the type keyword value for the messages: "string" or "string or null".
+func typeNames(t interface{}) string {
+	if types, ok := t.([]interface{}); ok {
+		names := make([]string, len(types))
+		for i, name := range types {
+			names[i] = fmt.Sprint(name)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// validFormat checks the string formats; the unknown formats are valid.
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", s)
+		return err == nil
+	case "email":
+		return govalidator.IsEmail(s)
+	case "uri":
+		return govalidator.IsRequestURL(s)
+	case "uuid":
+		return govalidator.IsUUID(s)
+	case "ipv4":
+		return govalidator.IsIPv4(s)
+	case "ipv6":
+		return govalidator.IsIPv6(s)
+	case "hostname":
+		return govalidator.IsDNSName(s)
+	}
+	return true
+}
+
+// escapePointer escapes the JSON pointer token.
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// ValidateJSONSchema validates the JSON request bodies of the route against the requestSchema, the invalid
+// requests are rejected with 400 Bad Request and the violations: {"errors": [{"pointer": "/name", "message": "is required"}]}.
+// In the debug mode the successful JSON responses are validated against the responseSchema, the invalid
+// responses are replaced with 500 Internal Server Error and the violations. Nil schemas are not checked,
+// the invalid schemas panic.
+//
+//	engine.POST("/users", createUser).ValidateJSONSchema(userSchema, nil)
+func (r *Route) ValidateJSONSchema(requestSchema, responseSchema []byte) *Route {
+	var request, response *JSONSchema
+	var err error
+	if requestSchema != nil {
+		if request, err = CompileJSONSchema(requestSchema); err != nil {
+			panic("ValidateJSONSchema: invalid request schema: " + err.Error())
+		}
+	}
+	if responseSchema != nil {
+		if response, err = CompileJSONSchema(responseSchema); err != nil {
+			panic("ValidateJSONSchema: invalid response schema: " + err.Error())
+		}
+	}
+
+	r.insert(func(c *Context) {
+		if request != nil && (len(c.Request.Body()) != 0 || inStrings(c.Method(), []string{"POST", "PUT", "PATCH"})) {
+			// the body is validated converted to UTF-8 as BindJSON reads it
+			if errs := request.Validate(c.Body()); len(errs) != 0 {
+				c.JSON(http.StatusBadRequest, schemaErrors{errs})
+				c.Abort()
+				return
+			}
+		}
+		if response == nil || !c.engine.Debug {
+			return
+		}
+		c.Next()
+		status := c.Response.StatusCode()
+		if status < 200 || status >= 300 || c.Response.IsBodyStream() ||
+			!bytes.Contains(c.Response.Header.ContentType(), []byte("json")) {
+			return
+		}
+		if errs := response.Validate(c.Response.Body()); len(errs) != 0 {
+			c.Logger().Error("response does not match the schema", "errors", errs)
+			c.Response.ResetBody()
+			c.JSON(http.StatusInternalServerError, schemaErrors{errs})
+		}
+	})
+	return r
+}
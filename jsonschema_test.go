@@ -0,0 +1,159 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/night-codes/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+var userSchema = []byte(`{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "email"],
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "maxLength": 20},
+		"email": {"type": "string", "format": "email"},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "items": {"type": "string", "pattern": "^[a-z]+$"}, "uniqueItems": true, "maxItems": 3},
+		"address": {"$ref": "#/$defs/address"},
+		"contact": {"oneOf": [{"required": ["phone"]}, {"required": ["telegram"]}]}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {"city": {"type": "string"}, "zip": {"type": ["string", "null"]}}
+		}
+	}
+}`)
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := CompileJSONSchema(userSchema)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		doc    string
+		errors []SchemaError
+	}{
+		{`{"name": "Bob", "email": "bob@example.com", "age": 30, "role": "admin", "tags": ["a", "b"],
+			"address": {"city": "Kyiv", "zip": null}, "contact": {"phone": "1"}}`, nil},
+		{`{"name": "B", "email": "bob", "extra": 1}`, []SchemaError{
+			{"/email", "must be a valid email"},
+			{"/extra", "property is not allowed"},
+			{"/name", "must be at least 2 characters long"},
+		}},
+		{`{"email": "bob@example.com", "age": 1.5, "role": "root"}`, []SchemaError{
+			{"/name", "is required"},
+			{"/age", "must be integer"},
+			{"/role", "must be one of the enumerated values"},
+		}},
+		{`{"name": "Bob", "email": "bob@example.com", "age": 150, "tags": ["a", "a", "B", "c"]}`, []SchemaError{
+			{"/age", "must be less than 150"},
+			{"/tags", "must have at most 3 items"},
+			{"/tags", "items 0 and 1 must be unique"},
+			{"/tags/2", `must match the pattern "^[a-z]+$"`},
+		}},
+		{`{"name": "Bob", "email": "bob@example.com", "address": {"zip": 1}, "contact": {"phone": "1", "telegram": "@bob"}}`, []SchemaError{
+			{"/address/city", "is required"},
+			{"/address/zip", "must be string or null"},
+			{"/contact", "must match exactly one schema of oneOf, matches 2"},
+		}},
+		{`[]`, []SchemaError{{"", "must be object"}}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.errors, schema.Validate([]byte(test.doc)), test.doc)
+	}
+
+	errs := schema.Validate([]byte(`{"name":`))
+	assert.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "invalid JSON")
+
+	_, err = CompileJSONSchema([]byte(`{"pattern": "("}`))
+	assert.NotNil(t, err)
+}
+
+func TestJSONSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		schema, doc string
+		valid       bool
+	}{
+		{`{"prefixItems": [{"type": "string"}], "items": {"type": "number"}}`, `["a", 1, 2]`, true},
+		{`{"prefixItems": [{"type": "string"}], "items": false}`, `["a", 1]`, false},
+		{`{"contains": {"const": 1}, "maxContains": 1}`, `[1, 2, 1]`, false},
+		{`{"multipleOf": 0.1}`, `0.3`, true},
+		{`{"not": {"type": "string"}}`, `"a"`, false},
+		{`{"anyOf": [{"type": "string"}, {"minimum": 5}]}`, `3`, false},
+		{`{"if": {"properties": {"kind": {"const": "a"}}}, "then": {"required": ["x"]}}`, `{"kind": "a"}`, false},
+		{`{"if": {"properties": {"kind": {"const": "a"}}}, "then": {"required": ["x"]}}`, `{"kind": "b"}`, true},
+		{`{"dependentRequired": {"card": ["cvv"]}}`, `{"card": "1"}`, false},
+		{`{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}`, `{"x-a": "1"}`, true},
+		{`{"propertyNames": {"maxLength": 2}}`, `{"abc": 1}`, false},
+		{`{"format": "date-time"}`, `"2024-01-02T15:04:05Z"`, true},
+		{`{"format": "uuid"}`, `"nope"`, false},
+		{`{"$ref": "#/$defs/missing"}`, `1`, false},
+		{`true`, `1`, true},
+		{`false`, `1`, false},
+	}
+	for _, test := range tests {
+		schema, err := CompileJSONSchema([]byte(test.schema))
+		assert.Nil(t, err)
+		assert.Equal(t, test.valid, len(schema.Validate([]byte(test.doc))) == 0, test.schema+" "+test.doc)
+	}
+}
+
+func TestRouteValidateJSONSchema(t *testing.T) {
+	engine := New()
+	engine.SetLogger(&testLogger{})
+	calls := 0
+	engine.POST("/users", func(c *Context) {
+		calls++
+		if c.Query("broken") != "" {
+			c.JSON(201, map[string]interface{}{"name": 1})
+			return
+		}
+		c.JSON(201, map[string]interface{}{"name": "Bob", "email": "bob@example.com"})
+	}).ValidateJSONSchema(userSchema, userSchema)
+
+	post := func(uri, body string) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, uri, withMethod("POST"), withBody("", body))
+	}
+
+	ctx := post("/users", `{"name": "Bob"}`)
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+	assert.JSONEq(t, `{"errors": [{"pointer": "/email", "message": "is required"}]}`, string(ctx.Response.Body()))
+	assert.Equal(t, 0, calls)
+
+	ctx = post("/users", "")
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+
+	ctx = post("/users?broken=1", `{"name": "Bob", "email": "bob@example.com"}`)
+	assert.Equal(t, 201, ctx.Response.StatusCode())
+
+	engine.Debug = true
+	ctx = post("/users?broken=1", `{"name": "Bob", "email": "bob@example.com"}`)
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+	var body schemaErrors
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &body))
+	assert.Equal(t, []SchemaError{{"/email", "is required"}, {"/name", "must be string"}}, body.Errors)
+
+	ctx = post("/users", `{"name": "Bob", "email": "bob@example.com"}`)
+	assert.Equal(t, 201, ctx.Response.StatusCode())
+	assert.Equal(t, 3, calls)
+
+	// the body is validated converted from the request charset
+	utf16 := []byte{}
+	for _, b := range []byte(`{"name": "Bob", "email": "bob@example.com"}`) {
+		utf16 = append(utf16, b, 0)
+	}
+	ctx = serveTestRequest(engine, "/users", withMethod("POST"), withBody("application/json; charset=utf-16le", string(utf16)))
+	assert.Equal(t, 201, ctx.Response.StatusCode())
+	assert.Equal(t, 4, calls)
+
+	assert.Panics(t, func() {
+		engine.GET("/bad", func(c *Context) {}).ValidateJSONSchema([]byte(`{`), nil)
+	})
+}
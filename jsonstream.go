@@ -0,0 +1,101 @@
+package tokay
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/night-codes/go-json"
+)
+
+// JSONArrayWriter writes the JSON array response incrementally (see Context.JSONStream).
+type JSONArrayWriter struct {
+	sync.Mutex
+	pw     *io.PipeWriter
+	w      *bufio.Writer
+	count  int
+	closed bool
+}
+
+// errJSONStreamClosed is returned by JSONArrayWriter.Write after Close.
+var errJSONStreamClosed = errors.New("JSON stream is closed")
+
+// JSONStream starts the JSON array response with the chunked transfer encoding. The items are
+// encoded one by one, so large results don't have to be loaded in memory.
+//
+// The response body is sent after the handler returns, so the items must be written from
+// a separate goroutine which must not use the Context. Close finishes the array; Write returns
+// an error if the client has gone away.
+//
+//	w := c.JSONStream(200)
+//	go func() {
+//		defer w.Close()
+//		for rows.Next() {
+//			var row Row
+//			rows.Scan(&row.ID, &row.Name)
+//			if err := w.Write(row); err != nil {
+//				return
+//			}
+//		}
+//	}()
+func (c *Context) JSONStream(statusCode int) *JSONArrayWriter {
+	pr, pw := io.Pipe()
+	c.SetStatusCode(statusCode)
+	c.SetContentType("application/json; charset=utf-8")
+	c.SetBodyStream(pr, -1)
+	return &JSONArrayWriter{pw: pw, w: bufio.NewWriter(pw)}
+}
+
+// Write encodes the item and appends it to the array.
+func (w *JSONArrayWriter) Write(item interface{}) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return errJSONStreamClosed
+	}
+	sep := byte(',')
+	if w.count == 0 {
+		sep = '['
+	}
+	w.w.WriteByte(sep)
+	_, err = w.w.Write(data)
+	w.count++
+	return err
+}
+
+// Count returns the number of written items.
+func (w *JSONArrayWriter) Count() int {
+	w.Lock()
+	defer w.Unlock()
+	return w.count
+}
+
+// Close finishes the JSON array and the response.
+func (w *JSONArrayWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.count == 0 {
+		w.w.WriteByte('[')
+	}
+	w.w.WriteByte(']')
+	err := w.w.Flush()
+	w.pw.Close()
+	return err
+}
+
+// CloseWithError aborts the response, the client receives an incomplete JSON array.
+func (w *JSONArrayWriter) CloseWithError(err error) error {
+	w.Lock()
+	defer w.Unlock()
+	w.closed = true
+	return w.pw.CloseWithError(err)
+}
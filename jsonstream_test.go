@@ -0,0 +1,43 @@
+package tokay
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContextJSONStream(t *testing.T) {
+	engine := New()
+	engine.GET("/items", func(c *Context) {
+		w := c.JSONStream(200)
+		n := c.QueryInt("n")
+		go func() {
+			defer w.Close()
+			for i := 0; i < n; i++ {
+				if err := w.Write(map[string]int{"id": i}); err != nil {
+					return
+				}
+			}
+		}()
+	})
+
+	for n, expected := range map[int]string{
+		0: `[]`,
+		3: `[{"id":0},{"id":1},{"id":2}]`,
+	} {
+		ctx := serveTestRequest(engine, "/items?n="+string(rune('0'+n)))
+
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		assert.Nil(t, ctx.Response.Write(w))
+		w.Flush()
+		var resp fasthttp.Response
+		assert.Nil(t, resp.Read(bufio.NewReader(&buf)))
+		assert.Equal(t, "chunked", string(resp.Header.Peek("Transfer-Encoding")))
+		assert.Equal(t, "application/json; charset=utf-8", string(resp.Header.ContentType()))
+		assert.Equal(t, expected, string(resp.Body()))
+	}
+}
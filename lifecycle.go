@@ -0,0 +1,109 @@
+package tokay
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MultiError is a list of errors returned by the lifecycle hooks.
+type MultiError []error
+
+// Error returns the error messages separated by "; ".
+func (e MultiError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// err returns nil for the empty list, the single error or the list itself.
+func (e MultiError) err() error {
+	switch len(e) {
+	case 0:
+		return nil
+	case 1:
+		return e[0]
+	}
+	return e
+}
+
+// OnStart registers the function called by Run, RunTLS, RunUnix, Serve and Start before the server
+// starts listening. Functions are called in the registration order. If any of them fails, the server
+// is not started and the error is returned by the Run method.
+//
+//	engine.OnStart(func() error {
+//		return db.Ping()
+//	})
+func (engine *Engine) OnStart(fn func() error) {
+	engine.onStart = append(engine.onStart, fn)
+}
+
+// OnStop registers the function called by Shutdown after the server is stopped.
+// Functions are called in the registration order, all errors are returned as MultiError.
+//
+//	engine.OnStop(func(ctx context.Context) error {
+//		return db.Close()
+//	})
+func (engine *Engine) OnStop(fn func(ctx context.Context) error) {
+	engine.lifecycleMu.Lock()
+	engine.onStop = append(engine.onStop, fn)
+	engine.lifecycleMu.Unlock()
+}
+
+// Shutdown gracefully stops the server (see Close) and calls the OnStop functions.
+// The active WebSocket connections receive the close frames (1001 Going Away) and are given
+// Config.WebsocketShutdownTimeout to close before they are dropped.
+// The ctx limits the time of waiting for the open connections.
+func (engine *Engine) Shutdown(ctx context.Context) error {
+	var errs MultiError
+	engine.websockets.closeAll(ctx, engine.wsShutdownTimeout)
+	closed := make(chan error, 1)
+	go func() {
+		closed <- engine.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			errs = append(errs, err)
+		}
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+
+	engine.lifecycleMu.Lock()
+	onStop := engine.onStop
+	engine.lifecycleMu.Unlock()
+	for _, fn := range onStop {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.err()
+}
+
+// start calls the OnStart functions.
+func (engine *Engine) start() error {
+	for _, fn := range engine.onStart {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitStarted waits until the server listener is created. It returns false if the server
+// is stopped (the done channel is closed) or the ctx is done first.
+func (engine *Engine) waitStarted(ctx context.Context, done <-chan struct{}) bool {
+	for engine.Addr() == nil {
+		select {
+		case <-done:
+			return false
+		case <-ctx.Done():
+			return false
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return true
+}
@@ -0,0 +1,271 @@
+package tokay
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestLifecycleHooks(t *testing.T) {
+	engine := New()
+	var calls []string
+	engine.OnStart(func() error {
+		calls = append(calls, "start1")
+		return nil
+	})
+	engine.OnStart(func() error {
+		calls = append(calls, "start2")
+		return errors.New("db is down")
+	})
+	engine.OnStart(func() error {
+		calls = append(calls, "start3")
+		return nil
+	})
+	assert.EqualError(t, engine.Run("127.0.0.1:0", ""), "db is down")
+	assert.Equal(t, []string{"start1", "start2"}, calls)
+
+	calls = nil
+	engine.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop1")
+		return errors.New("cache error")
+	})
+	engine.OnStop(func(ctx context.Context) error {
+		calls = append(calls, "stop2")
+		return nil
+	})
+	err := engine.Shutdown(context.Background())
+	assert.Equal(t, []string{"stop1", "stop2"}, calls)
+	assert.EqualError(t, err, "server is not runned; cache error")
+	assert.Len(t, err.(MultiError), 2)
+}
+
+// runTestServer runs the engine on a free port and returns the connection to it.
+func runTestServer(engine *Engine) (conn net.Conn, err error) {
+	go engine.Run("127.0.0.1:0", "")
+	for i := 0; i < 50; i++ {
+		if addr := engine.Addr(); addr != nil {
+			return net.Dial("tcp4", addr.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil, errors.New("server is not started")
+}
+
+func TestCloseDrainsKeepAliveConnections(t *testing.T) {
+	engine := New(&Config{MaxGracefulWaitTime: 5 * time.Second})
+	engine.GET("/", func(c *Context) {
+		c.String(200, "OK")
+	})
+	conn, err := runTestServer(engine)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer conn.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	var resp fasthttp.Response
+	assert.Nil(t, resp.Read(bufio.NewReader(conn)))
+	assert.False(t, resp.ConnectionClose())
+
+	start := time.Now()
+	assert.Nil(t, engine.Close())
+	assert.True(t, time.Since(start) < time.Second)
+
+	ctx := serveTestRequest(engine, "/")
+	assert.True(t, ctx.Response.ConnectionClose())
+}
+
+func TestGracefulShutdownProgress(t *testing.T) {
+	inner, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(t, err)
+	var reports []uint64
+	ln := &GracefulListener{
+		ln:          inner,
+		maxWaitTime: 3 * time.Second,
+		done:        make(chan struct{}),
+		progress: func(openConns uint64, remaining time.Duration) {
+			assert.True(t, remaining <= 3*time.Second)
+			reports = append(reports, openConns)
+		},
+		connsCount: 2,
+	}
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		ln.closeConn()
+		time.Sleep(time.Second)
+		ln.closeConn()
+	}()
+	assert.Nil(t, ln.Close())
+	assert.Equal(t, []uint64{2, 1}, reports)
+
+	engine := New(&Config{GracefulTimeout: time.Minute, MaxGracefulWaitTime: time.Second})
+	assert.Equal(t, time.Minute, engine.maxGracefulWaitTime)
+}
+
+func TestConnStateCallback(t *testing.T) {
+	states := make(chan ConnState, 10)
+	engine := New(&Config{ConnState: func(conn net.Conn, state ConnState) {
+		states <- state
+	}})
+	engine.GET("/", func(c *Context) {
+		c.String(200, "OK")
+	})
+	conn, err := runTestServer(engine)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer engine.Close()
+	fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	var resp fasthttp.Response
+	assert.Nil(t, resp.Read(bufio.NewReader(conn)))
+	conn.Close()
+
+	var received []ConnState
+	for len(received) < 4 {
+		select {
+		case state := <-states:
+			received = append(received, state)
+		case <-time.After(time.Second):
+			t.Fatalf("states received: %v", received)
+		}
+	}
+	assert.Equal(t, []ConnState{StateNew, StateActive, StateIdle, StateClosed}, received)
+}
+
+func TestCloseAllListeners(t *testing.T) {
+	var states int32
+	engine := New(&Config{MaxGracefulWaitTime: 5 * time.Second, ConnState: func(conn net.Conn, state ConnState) {
+		atomic.AddInt32(&states, 1)
+	}})
+	engine.GET("/", func(c *Context) {
+		c.String(200, "OK")
+	})
+	stopped := make(chan error, 2)
+	var listeners []*GracefulListener
+	for n := 1; n <= 2; n++ {
+		go func() {
+			stopped <- engine.Run("127.0.0.1:0", "")
+		}()
+		for i := 0; i < 50 && len(listeners) < n; i++ {
+			time.Sleep(10 * time.Millisecond)
+			listeners = engine.runningListeners()
+		}
+	}
+	if !assert.Len(t, listeners, 2) {
+		return
+	}
+	for _, ln := range listeners {
+		conn, err := net.Dial("tcp4", ln.Addr().String())
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+		var resp fasthttp.Response
+		assert.Nil(t, resp.Read(bufio.NewReader(conn)))
+	}
+	// the idle connections are tracked by their own listeners
+	idle := func() (counts []int) {
+		for _, ln := range listeners {
+			ln.idleMu.Lock()
+			counts = append(counts, len(ln.idle))
+			ln.idleMu.Unlock()
+		}
+		return
+	}
+	for i := 0; i < 100 && fmt.Sprint(idle()) != "[1 1]"; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, []int{1, 1}, idle())
+	assert.True(t, atomic.LoadInt32(&states) >= 6)
+
+	start := time.Now()
+	assert.Nil(t, engine.Close())
+	assert.True(t, time.Since(start) < time.Second)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatal("the server is not stopped")
+		}
+	}
+	assert.Len(t, engine.runningListeners(), 0)
+}
+
+func TestServerHeader(t *testing.T) {
+	for header, expected := range map[string]string{"": "", "api": "api"} {
+		engine := New(&Config{ServerHeader: header, HideBanner: true})
+		engine.GET("/", func(c *Context) {
+			c.String(200, "OK")
+		})
+		conn, err := runTestServer(engine)
+		if !assert.Nil(t, err) {
+			return
+		}
+		fmt.Fprint(conn, "GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")
+		var resp fasthttp.Response
+		assert.Nil(t, resp.Read(bufio.NewReader(conn)))
+		assert.Equal(t, expected, string(resp.Header.Peek("Server")))
+		conn.Close()
+		engine.Close()
+	}
+}
+
+// eventLogger records the logged messages.
+type eventLogger struct {
+	testLogger
+	sync.Mutex
+	events []string
+}
+
+func (l *eventLogger) log(msg string, keyvals []interface{}) {
+	l.Lock()
+	l.events = append(l.events, strings.TrimSpace(fmt.Sprintln(append([]interface{}{msg}, keyvals...)...)))
+	l.Unlock()
+}
+
+func (l *eventLogger) Debug(msg string, keyvals ...interface{}) { l.log(msg, keyvals) }
+func (l *eventLogger) Info(msg string, keyvals ...interface{})  { l.log(msg, keyvals) }
+func (l *eventLogger) Warn(msg string, keyvals ...interface{})  { l.log(msg, keyvals) }
+func (l *eventLogger) Error(msg string, keyvals ...interface{}) { l.log(msg, keyvals) }
+
+func TestLifecycleEvents(t *testing.T) {
+	logger := &eventLogger{}
+	engine := New(&Config{Logger: logger})
+	assert.Nil(t, engine.Addr())
+	assert.Equal(t, 0, engine.Port())
+	done := make(chan error)
+	go func() {
+		done <- engine.Run("127.0.0.1:0", "started at %s")
+	}()
+	var addr *net.TCPAddr
+	for i := 0; i < 50 && addr == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+		addr, _ = engine.Addr().(*net.TCPAddr)
+	}
+	if !assert.NotNil(t, addr) {
+		return
+	}
+	assert.NotZero(t, addr.Port)
+	assert.Equal(t, addr.Port, engine.Port())
+	assert.Nil(t, engine.Close())
+	assert.Nil(t, <-done)
+
+	logger.Lock()
+	defer logger.Unlock()
+	assert.Equal(t, []string{
+		"server starting network tcp addr 127.0.0.1:0",
+		fmt.Sprintf("started at %s network tcp addr %s port %d", addr, addr, addr.Port),
+		fmt.Sprintf("server stopping addr %s", addr),
+		"server stopped",
+	}, logger.events)
+}
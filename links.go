@@ -0,0 +1,103 @@
+package tokay
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/night-codes/go-json"
+)
+
+// Link is the hypermedia link of a REST resource, emitted in the Link header
+// and the "_links" JSON object (see Context.JSONWithLinks).
+type Link struct {
+	Rel   string `json:"-"`
+	Href  string `json:"href"`
+	Title string `json:"title,omitempty"`
+}
+
+// RouteLink returns the link with the relation to the URL of the named route (see Context.URL),
+// so the links stay correct when the route paths change.
+//
+//	c.RouteLink("self", "user", "id", user.ID)
+func (c *Context) RouteLink(rel, route string, pairs ...interface{}) Link {
+	return Link{Rel: rel, Href: c.URL(route, pairs...)}
+}
+
+// SetLinks adds the links to the Link response header, like `</users?page=3>; rel="next"`.
+// The links with the empty Href are skipped.
+func (c *Context) SetLinks(links ...Link) {
+	values := make([]string, 0, len(links))
+	for _, l := range links {
+		if l.Href == "" {
+			continue
+		}
+		value := "<" + l.Href + ">; rel=" + strconv.Quote(l.Rel)
+		if l.Title != "" {
+			value += "; title=" + strconv.Quote(l.Title)
+		}
+		values = append(values, value)
+	}
+	if len(values) != 0 {
+		c.Response.Header.Add("Link", strings.Join(values, ", "))
+	}
+}
+
+// JSONWithLinks writes the JSON response with the links in the "_links" object (like HAL) and the Link
+// header. The links are added to the obj JSON object, other values are wrapped as {"data": obj, "_links": ...}.
+// Several links with the same relation are written as an array.
+//
+//	c.JSONWithLinks(200, users,
+//		c.RouteLink("self", "users"),
+//		tokay.Link{Rel: "next", Href: c.URL("users") + "?page=2"},
+//	)
+func (c *Context) JSONWithLinks(statusCode int, obj interface{}, links ...Link) {
+	c.SetLinks(links...)
+	c.JSON(statusCode, linkedObject{obj, links})
+}
+
+// linkedObject is the JSON value with the "_links" object.
+type linkedObject struct {
+	value interface{}
+	links []Link
+}
+
+// MarshalJSON implements json.Marshaler.
+func (o linkedObject) MarshalJSON() ([]byte, error) {
+	links := orderedObject{}
+	index := make(map[string]int)
+	for _, l := range o.links {
+		if l.Href == "" {
+			continue
+		}
+		i, ok := index[l.Rel]
+		if !ok {
+			index[l.Rel] = len(links)
+			links = append(links, orderedField{l.Rel, l})
+		} else if list, isList := links[i].value.([]Link); isList {
+			links[i].value = append(list, l)
+		} else {
+			links[i].value = []Link{links[i].value.(Link), l}
+		}
+	}
+	linksJSON, err := links.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	value, err := json.Marshal(o.value)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(value); len(trimmed) > 1 && trimmed[0] == '{' {
+		var buf bytes.Buffer
+		buf.Grow(len(trimmed) + len(linksJSON) + 10)
+		buf.WriteString(`{"_links":`)
+		buf.Write(linksJSON)
+		if body := bytes.TrimSpace(trimmed[1:]); body[0] != '}' {
+			buf.WriteByte(',')
+		}
+		buf.Write(trimmed[1:])
+		return buf.Bytes(), nil
+	}
+	return (orderedObject{{"data", json.RawMessage(value)}, {"_links", json.RawMessage(linksJSON)}}).MarshalJSON()
+}
@@ -0,0 +1,41 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextJSONWithLinks(t *testing.T) {
+	engine := New()
+	engine.GET("/users/<id>", func(c *Context) {}).Name("user")
+	engine.GET("/users", func(c *Context) {
+		c.JSONWithLinks(200, []int{1, 2},
+			c.RouteLink("self", "users"),
+			Link{Rel: "next", Href: c.URL("users") + "?page=2", Title: "Next page"},
+			c.RouteLink("item", "user", "id", 1),
+			c.RouteLink("item", "user", "id", 2),
+			c.RouteLink("missing", "unknown"),
+		)
+	}).Name("users")
+	engine.GET("/user", func(c *Context) {
+		c.JSONWithLinks(200, map[string]int{"id": 1}, c.RouteLink("self", "user", "id", 1))
+	})
+	engine.GET("/empty", func(c *Context) {
+		c.JSONWithLinks(200, struct{}{}, c.RouteLink("up", "users"))
+	})
+
+	ctx := serveTestRequest(engine, "/users")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, `{"data":[1,2],"_links":{"self":{"href":"/users"},"next":{"href":"/users?page=2","title":"Next page"},`+
+		`"item":[{"href":"/users/1"},{"href":"/users/2"}]}}`, string(ctx.Response.Body()))
+	assert.Equal(t, `</users>; rel="self", </users?page=2>; rel="next"; title="Next page", `+
+		`</users/1>; rel="item", </users/2>; rel="item"`, string(ctx.Response.Header.Peek("Link")))
+
+	ctx = serveTestRequest(engine, "/user")
+	assert.Equal(t, `{"_links":{"self":{"href":"/users/1"}},"id":1}`, string(ctx.Response.Body()))
+	assert.Equal(t, `</users/1>; rel="self"`, string(ctx.Response.Header.Peek("Link")))
+
+	ctx = serveTestRequest(engine, "/empty")
+	assert.Equal(t, `{"_links":{"up":{"href":"/users"}}}`, string(ctx.Response.Body()))
+}
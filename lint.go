@@ -0,0 +1,154 @@
+package tokay
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// Route warning kinds.
+const (
+	// WarningRegex is reported for the parameter regular expressions which are slow or likely wrong.
+	WarningRegex = "regex"
+	// WarningShadowed is reported for the routes never matched because of the routes registered before them.
+	WarningShadowed = "shadowed"
+)
+
+// maxRegexRepeat is the maximal repetition count of the parameter regular expressions not reported.
+const maxRegexRepeat = 100
+
+// Warning is a route registration problem (see Engine.Lint).
+type Warning struct {
+	Method  string
+	Path    string
+	Kind    string
+	Message string
+}
+
+// String returns the warning as "GET /path: message".
+func (w Warning) String() string {
+	return w.Method + " " + w.Path + ": " + w.Message
+}
+
+var (
+	// paramToken matches the route parameter tokens.
+	paramToken = regexp.MustCompile(`<[^>]*>`)
+	// lintSamples are the parameter values checking the route shadowing.
+	lintSamples = []string{"0", "tokay-lint", "~"}
+)
+
+// Lint checks all the registered routes: the parameter regular expressions with nested or large
+// repetitions or consuming the rest of the path, and the routes shadowed by the broader routes
+// registered before them (the route added first takes precedence). The warnings are logged
+// on the registration as well, Lint is meant for the CI checks.
+//
+//	func TestRoutes(t *testing.T) {
+//		for _, w := range newEngine().Lint() {
+//			t.Error(w)
+//		}
+//	}
+func (engine *Engine) Lint() []Warning {
+	var warnings []Warning
+	for _, route := range engine.routeList {
+		for _, method := range route.methods {
+			warnings = append(warnings, engine.lintRoute(method, route)...)
+		}
+	}
+	return warnings
+}
+
+// lintRoute returns the warnings of the route registered with the method.
+func (engine *Engine) lintRoute(method string, route *Route) []Warning {
+	var warnings []Warning
+	warn := func(kind, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Method: method, Path: route.path, Kind: kind, Message: fmt.Sprintf(format, args...)})
+	}
+
+	regexTokens := false
+	for _, loc := range paramToken.FindAllStringIndex(route.path, -1) {
+		token := route.path[loc[0]+1 : loc[1]-1]
+		i := strings.IndexByte(token, ':')
+		if i < 0 {
+			continue
+		}
+		regexTokens = true
+		pattern := token[i+1:]
+		if paramTypes[pattern] != nil {
+			continue
+		}
+		re, err := syntax.Parse(pattern, syntax.Perl)
+		if err != nil {
+			continue
+		}
+		if nestedRepeat(re, false) {
+			warn(WarningRegex, "parameter regexp %q has nested repetitions", pattern)
+		}
+		if largeRepeat(re) {
+			warn(WarningRegex, "parameter regexp %q has repetition counts over %d", pattern, maxRegexRepeat)
+		}
+		if loc[1] < len(route.path) && regexp.MustCompile("^(?:"+pattern+")$").MatchString("a/b/c") {
+			warn(WarningRegex, "parameter regexp %q may consume the rest of the path, so %q never matches", pattern, route.path[loc[1]:])
+		}
+	}
+
+	if !regexTokens {
+		var shadowing *Route
+		for _, sample := range lintSamples {
+			rh, _ := engine.find(method, paramToken.ReplaceAllString(route.path, sample), make([]string, engine.maxParams))
+			if rh == nil || rh.route.path == route.path || shadowing != nil && rh.route != shadowing {
+				shadowing = nil
+				break
+			}
+			shadowing = rh.route
+		}
+		if shadowing != nil {
+			warn(WarningShadowed, "route is shadowed by %s registered before", shadowing.path)
+		}
+	}
+	return warnings
+}
+
+// logRouteWarnings logs the warnings of the route registered with the method.
+func (engine *Engine) logRouteWarnings(method string, route *Route) {
+	for _, w := range engine.lintRoute(method, route) {
+		engine.logger.Warn("route warning", "method", w.Method, "path", w.Path, "kind", w.Kind, "message", w.Message)
+	}
+}
+
+// nestedRepeat reports whether the regexp has a repetition inside another one, like "(a+)+".
+func nestedRepeat(re *syntax.Regexp, inRepeat bool) bool {
+	switch re.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		if inRepeat {
+			return true
+		}
+		inRepeat = true
+	case syntax.OpRepeat:
+		if re.Max == -1 || re.Max > 1 {
+			if inRepeat {
+				return true
+			}
+			inRepeat = true
+		}
+	}
+	for _, sub := range re.Sub {
+		if nestedRepeat(sub, inRepeat) {
+			return true
+		}
+	}
+	return false
+}
+
+// largeRepeat reports whether the regexp has a repetition count over maxRegexRepeat, like "a{1,1000}".
+func largeRepeat(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpRepeat && (re.Min > maxRegexRepeat || re.Max > maxRegexRepeat) {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if largeRepeat(sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineLint(t *testing.T) {
+	logger := &eventLogger{}
+	engine := New()
+	engine.SetLogger(logger)
+	h := func(c *Context) {}
+	engine.GET("/users/<id>", h)
+	engine.GET("/users/me", h)
+	engine.GET("/users/<id:\\d+>/posts", h)
+	engine.GET("/users/<name>/posts", h)
+	engine.GET("/pages/<slug:(\\w+-?)+>", h)
+	engine.GET("/codes/<code:[a-z]{2,200}>", h)
+	engine.GET("/files/<path:.+>/raw", h)
+	engine.GET("/files/*", h)
+	engine.GET("/files/readme", h)
+	engine.POST("/users/me", h)
+
+	warnings := engine.Lint()
+	assert.Equal(t, []Warning{
+		{"GET", "/users/me", WarningShadowed, "route is shadowed by /users/<id> registered before"},
+		{"GET", "/pages/<slug:(\\w+-?)+>", WarningRegex, `parameter regexp "(\\w+-?)+" has nested repetitions`},
+		{"GET", "/codes/<code:[a-z]{2,200}>", WarningRegex, `parameter regexp "[a-z]{2,200}" has repetition counts over 100`},
+		{"GET", "/files/<path:.+>/raw", WarningRegex, `parameter regexp ".+" may consume the rest of the path, so "/raw" never matches`},
+		{"GET", "/files/readme", WarningShadowed, "route is shadowed by /files/<:.*> registered before"},
+	}, warnings)
+	assert.Equal(t, "GET /users/me: route is shadowed by /users/<id> registered before", warnings[0].String())
+	assert.Len(t, logger.events, 5)
+	assert.Contains(t, logger.events[0], "route warning method GET path /users/me kind shadowed")
+}
@@ -3,8 +3,12 @@ package tokay
 import (
 	"fmt"
 	"net"
+	"os"
+	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/valyala/fasthttp"
 )
 
 func listenAndServe(engine *Engine, addr string) error {
@@ -14,14 +18,12 @@ func listenAndServe(engine *Engine, addr string) error {
 		return err
 	}
 	if tcpln, ok := ln.(*net.TCPListener); ok {
-		listener := NewGracefulListener(tcpKeepaliveListener{
-			TCPListener:     tcpln,
-			keepalive:       s.TCPKeepalive,
-			keepalivePeriod: s.TCPKeepalivePeriod,
-		}, engine.maxGracefulWaitTime)
-		engine.Close = listener.Close
-		return s.Serve(listener)
+		gln := engine.gracefulListener(tcpln)
+		defer engine.removeListener(gln)
+		engine.listening(ln)
+		return s.Serve(gln)
 	}
+	engine.listening(ln)
 	return s.Serve(ln)
 }
 
@@ -35,23 +37,155 @@ func listenAndServe(engine *Engine, addr string) error {
 // If the certFile or keyFile has not been provided to the server structure,
 // the function will use the previously added TLS configuration.
 //
+// Certificate files are watched for changes when engine TLSReloadInterval is set.
+//
 // Accepted connections are configured to enable TCP keep-alives.
 func listenAndServeTLS(engine *Engine, addr, certFile, keyFile string) error {
 	s := engine.Server
+	reloadable, err := engine.tlsConfig(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	if reloadable {
+		if engine.certs != nil && engine.tlsReloadInterval > 0 {
+			stop := make(chan struct{})
+			defer close(stop)
+			go engine.certs.watch(engine.tlsReloadInterval, stop, engine.logger)
+		}
+		// certificates are provided by TLSConfig.GetCertificate
+		certFile, keyFile = "", ""
+	}
+
 	ln, err := net.Listen("tcp4", addr)
 	if err != nil {
 		return err
 	}
 	if tcpln, ok := ln.(*net.TCPListener); ok {
-		listener := NewGracefulListener(tcpKeepaliveListener{
+		gln := engine.gracefulListener(tcpln)
+		defer engine.removeListener(gln)
+		engine.listening(ln)
+		return s.ServeTLS(gln, certFile, keyFile)
+	}
+	engine.listening(ln)
+	return s.ServeTLS(ln, certFile, keyFile)
+}
+
+// listenAndServeUnix serves HTTP requests from the given unix socket file, replacing
+// the existing one, with the given access mode.
+func listenAndServeUnix(engine *Engine, addr string, mode os.FileMode) error {
+	if err := os.Remove(addr); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unexpected error when trying to remove unix socket file %q: %w", addr, err)
+	}
+	ln, err := net.Listen("unix", addr)
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(addr, mode); err != nil {
+		return fmt.Errorf("cannot chmod %#o for %q: %w", mode, addr, err)
+	}
+	engine.listening(ln)
+	return engine.Server.Serve(ln)
+}
+
+// ConnState represents the state of a client connection (see Config.ConnState).
+type ConnState = fasthttp.ConnState
+
+// Connection states passed to Config.ConnState.
+const (
+	// StateNew is a new connection that is expected to send a request immediately.
+	StateNew = fasthttp.StateNew
+	// StateActive is a connection that has read the first byte of a request.
+	StateActive = fasthttp.StateActive
+	// StateIdle is a keep-alive connection waiting for the next request.
+	StateIdle = fasthttp.StateIdle
+	// StateHijacked is a hijacked connection (for example, WebSocket). This is a terminal state.
+	StateHijacked = fasthttp.StateHijacked
+	// StateClosed is a closed connection. This is a terminal state.
+	StateClosed = fasthttp.StateClosed
+)
+
+// gracefulListener wraps the TCP listener into the GracefulListener used by engine.Close.
+// On Close the engine starts draining: responses get the "Connection: close" header and
+// idle keep-alive connections are closed, so they don't hold the shutdown until maxGracefulWaitTime.
+func (engine *Engine) gracefulListener(tcpln *net.TCPListener) *GracefulListener {
+	s := engine.Server
+	listener := &GracefulListener{
+		ln: tcpKeepaliveListener{
 			TCPListener:     tcpln,
 			keepalive:       s.TCPKeepalive,
 			keepalivePeriod: s.TCPKeepalivePeriod,
-		}, engine.maxGracefulWaitTime)
-		engine.Close = listener.Close
-		return s.ServeTLS(listener, certFile, keyFile)
+		},
+		maxWaitTime: engine.maxGracefulWaitTime,
+		done:        make(chan struct{}),
+		idle:        make(map[net.Conn]struct{}),
+		progress:    engine.shutdownProgress,
 	}
-	return s.ServeTLS(ln, certFile, keyFile)
+	engine.listenersMu.Lock()
+	if engine.listeners == nil {
+		engine.listeners = make(map[*GracefulListener]struct{})
+	}
+	if len(engine.listeners) == 0 {
+		// the engine closed before is run again
+		atomic.StoreInt32(&engine.draining, 0)
+	}
+	engine.listeners[listener] = struct{}{}
+	engine.listenersMu.Unlock()
+
+	engine.trackConns.Do(func() {
+		// the connection events go to the listener accepted the connection, then to the user hook
+		hook := s.ConnState
+		s.ConnState = func(c net.Conn, state fasthttp.ConnState) {
+			if gc := gracefulConnOf(c); gc != nil {
+				gc.ln.connState(c, state)
+			}
+			if hook != nil {
+				hook(c, state)
+			}
+		}
+		engine.Close = engine.closeListeners
+	})
+	return listener
+}
+
+// removeListener forgets the listener of the stopped server.
+func (engine *Engine) removeListener(ln *GracefulListener) {
+	engine.listenersMu.Lock()
+	delete(engine.listeners, ln)
+	engine.listenersMu.Unlock()
+}
+
+// runningListeners returns the listeners of the running servers.
+func (engine *Engine) runningListeners() []*GracefulListener {
+	engine.listenersMu.Lock()
+	defer engine.listenersMu.Unlock()
+	listeners := make([]*GracefulListener, 0, len(engine.listeners))
+	for ln := range engine.listeners {
+		listeners = append(listeners, ln)
+	}
+	return listeners
+}
+
+// closeListeners gracefully closes the listeners of all the running servers.
+func (engine *Engine) closeListeners() error {
+	atomic.StoreInt32(&engine.draining, 1)
+	var errs MultiError
+	for _, ln := range engine.runningListeners() {
+		engine.logger.Info("server stopping", "addr", ln.Addr().String())
+		if err := ln.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.err()
+}
+
+// gracefulConnOf returns the connection accepted by the engine listener under the TLS connection, or nil.
+func gracefulConnOf(conn net.Conn) *gracefulConn {
+	if nc, ok := conn.(interface{ NetConn() net.Conn }); ok {
+		// the TLS connection
+		conn = nc.NetConn()
+	}
+	gc, _ := conn.(*gracefulConn)
+	return gc
 }
 
 // tcpKeepAliveListener sets TCP keep-alive timeouts on accepted
@@ -91,13 +225,21 @@ type GracefulListener struct {
 	maxWaitTime time.Duration
 
 	// this channel is closed during graceful shutdown on zero open connections.
-	done chan struct{}
+	done     chan struct{}
+	doneOnce sync.Once
 
 	// the number of open connections
 	connsCount uint64
 
 	// becomes non-zero when graceful shutdown starts
 	shutdown uint64
+
+	// progress is called every second while waiting for the open connections
+	progress func(openConns uint64, remaining time.Duration)
+
+	// idle keep-alive connections (tracked when the listener is created by the engine)
+	idleMu sync.Mutex
+	idle   map[net.Conn]struct{}
 }
 
 // NewGracefulListener wraps the given listener into 'graceful shutdown' listener.
@@ -141,30 +283,87 @@ func (ln *GracefulListener) Close() (err error) {
 
 func (ln *GracefulListener) waitForZeroConns() error {
 	atomic.AddUint64(&ln.shutdown, 1)
+	ln.closeIdleConns()
 
 	if atomic.LoadUint64(&ln.connsCount) == 0 {
-		close(ln.done)
+		ln.closeDone()
 		return nil
 	}
 
-	select {
-	case <-ln.done:
-		return nil
-	case <-time.After(ln.maxWaitTime):
-		return fmt.Errorf("cannot complete graceful shutdown in %s", ln.maxWaitTime)
+	deadline := time.Now().Add(ln.maxWaitTime)
+	timeout := time.NewTimer(ln.maxWaitTime)
+	defer timeout.Stop()
+	var tick <-chan time.Time
+	if ln.progress != nil {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		tick = ticker.C
+		ln.progress(atomic.LoadUint64(&ln.connsCount), ln.maxWaitTime)
 	}
+	for {
+		select {
+		case <-ln.done:
+			return nil
+		case <-tick:
+			ln.progress(atomic.LoadUint64(&ln.connsCount), time.Until(deadline))
+		case <-timeout.C:
+			return fmt.Errorf("cannot complete graceful shutdown in %s", ln.maxWaitTime)
+		}
+	}
+}
+
+// connState tracks the idle connections, the connections becoming idle during the shutdown are closed.
+func (ln *GracefulListener) connState(c net.Conn, state fasthttp.ConnState) {
+	ln.idleMu.Lock()
+	defer ln.idleMu.Unlock()
+	if state != fasthttp.StateNew && state != fasthttp.StateIdle {
+		delete(ln.idle, c)
+		return
+	}
+	if atomic.LoadUint64(&ln.shutdown) != 0 {
+		c.Close()
+		return
+	}
+	if ln.idle != nil {
+		ln.idle[c] = struct{}{}
+	}
+}
+
+// closeIdleConns closes the connections waiting for the next request.
+func (ln *GracefulListener) closeIdleConns() {
+	ln.idleMu.Lock()
+	for c := range ln.idle {
+		c.Close()
+		delete(ln.idle, c)
+	}
+	ln.idleMu.Unlock()
 }
 
 func (ln *GracefulListener) closeConn() {
 	connsCount := atomic.AddUint64(&ln.connsCount, ^uint64(0))
 	if atomic.LoadUint64(&ln.shutdown) != 0 && connsCount == 0 {
-		close(ln.done)
+		ln.closeDone()
 	}
 }
 
+// closeDone closes the done channel once: the last connection may be closed concurrently with the shutdown start.
+func (ln *GracefulListener) closeDone() {
+	ln.doneOnce.Do(func() {
+		close(ln.done)
+	})
+}
+
 type gracefulConn struct {
 	net.Conn
 	ln *GracefulListener
+	// write is the response being written (see Engine.OnWriteError)
+	write responseWrite
+}
+
+func (c *gracefulConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.write.written(n, err)
+	return n, err
 }
 
 func (c *gracefulConn) Close() error {
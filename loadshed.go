@@ -0,0 +1,155 @@
+package tokay
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PriorityMetaKey is the route metadata key of the route priority (see Route.Priority).
+const PriorityMetaKey = "priority"
+
+// Priority sets the route priority used by LoadShed middleware. Routes with lower
+// priority are rejected first under overload. Default priority is 0.
+//
+//	engine.GET("/health", healthHandler).Priority(10)
+//	engine.GET("/reports", reportsHandler).Priority(-1)
+func (r *Route) Priority(priority int) *Route {
+	return r.SetMeta(PriorityMetaKey, priority)
+}
+
+// LoadShedConfig is a struct for specifying LoadShed middleware options.
+// Zero thresholds are disabled.
+type LoadShedConfig struct {
+	// MaxLatency is the maximum delay of the runtime scheduler (measured by the sampling ticker drift).
+	MaxLatency time.Duration
+	// MaxGoroutines is the maximum number of goroutines.
+	MaxGoroutines int
+	// MaxHeapBytes is the maximum size of allocated heap objects.
+	MaxHeapBytes uint64
+	// Interval is the metrics sampling interval. Default is 250ms.
+	Interval time.Duration
+	// ProtectedPriority is the lowest priority of routes which are never rejected. Default (nil) is 1,
+	// so only the routes with priority below 1 are rejected.
+	//
+	//	protected := 0 // reject only the routes with negative priorities
+	//	tokay.LoadShed(tokay.LoadShedConfig{MaxGoroutines: 20000, ProtectedPriority: &protected})
+	ProtectedPriority *int
+}
+
+// loadShedder keeps the current shedding level: requests to routes with priority below
+// the level are rejected.
+type loadShedder struct {
+	config    LoadShedConfig
+	protected int // the resolved ProtectedPriority
+	level     int64
+	once      sync.Once
+	stop      chan struct{} // stops the monitor, closed by the engine Shutdown
+	stopOnce  sync.Once
+}
+
+// heapObjectsMetric is the runtime metric of the allocated heap objects size,
+// read without stopping the world unlike runtime.ReadMemStats.
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// LoadShed returns an adaptive load-shedding middleware. It samples the server metrics and, while
+// any of the thresholds is crossed, rejects requests with 503 Service Unavailable starting from the
+// lowest-priority routes (see Route.Priority): every overloaded sample raises the shedding level by one
+// priority step (up to ProtectedPriority), every normal sample lowers it back. The sampling starts
+// with the first request and stops on Engine.Shutdown.
+//
+//	engine.Use(tokay.LoadShed(tokay.LoadShedConfig{MaxLatency: 50 * time.Millisecond, MaxGoroutines: 20000}))
+func LoadShed(config LoadShedConfig) Handler {
+	if config.Interval <= 0 {
+		config.Interval = 250 * time.Millisecond
+	}
+	s := &loadShedder{config: config, protected: 1, stop: make(chan struct{})}
+	if config.ProtectedPriority != nil {
+		s.protected = *config.ProtectedPriority
+	}
+	return func(c *Context) {
+		s.once.Do(func() {
+			lowest := lowestPriority(c.engine)
+			atomic.StoreInt64(&s.level, int64(lowest))
+			go s.monitor(lowest)
+			c.engine.OnStop(func(ctx context.Context) error {
+				s.stopOnce.Do(func() { close(s.stop) })
+				return nil
+			})
+		})
+		if routePriority(c.route) < int(atomic.LoadInt64(&s.level)) {
+			// set after AbortWithError, which resets the response
+			c.AbortWithError(http.StatusServiceUnavailable, nil)
+			c.Header("Retry-After", "1")
+		}
+	}
+}
+
+// routePriority returns the route priority.
+func routePriority(route *Route) int {
+	if route != nil {
+		if priority, ok := route.Meta(PriorityMetaKey).(int); ok {
+			return priority
+		}
+	}
+	return 0
+}
+
+// lowestPriority returns the lowest priority of the engine routes.
+func lowestPriority(engine *Engine) (lowest int) {
+	for _, route := range engine.routes {
+		if priority := routePriority(route); priority < lowest {
+			lowest = priority
+		}
+	}
+	return
+}
+
+// monitor samples the metrics and adjusts the shedding level between lowest and ProtectedPriority
+// until the shedder is stopped.
+func (s *loadShedder) monitor(lowest int) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	last := time.Now()
+	level := lowest
+	for {
+		var now time.Time
+		select {
+		case <-s.stop:
+			return
+		case now = <-ticker.C:
+		}
+		latency := now.Sub(last) - s.config.Interval
+		last = now
+		if s.overloaded(latency) {
+			if level < s.protected {
+				level++
+			}
+		} else if level > lowest {
+			level--
+		}
+		atomic.StoreInt64(&s.level, int64(level))
+	}
+}
+
+// overloaded checks the metrics against the thresholds.
+func (s *loadShedder) overloaded(latency time.Duration) bool {
+	if s.config.MaxLatency > 0 && latency > s.config.MaxLatency {
+		return true
+	}
+	if s.config.MaxGoroutines > 0 && runtime.NumGoroutine() > s.config.MaxGoroutines {
+		return true
+	}
+	if s.config.MaxHeapBytes > 0 {
+		sample := []metrics.Sample{{Name: heapObjectsMetric}}
+		metrics.Read(sample)
+		if sample[0].Value.Kind() == metrics.KindUint64 && sample[0].Value.Uint64() > s.config.MaxHeapBytes {
+			return true
+		}
+	}
+	return false
+}
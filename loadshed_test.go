@@ -0,0 +1,89 @@
+package tokay
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteMeta(t *testing.T) {
+	engine := New()
+	route := engine.GET("/", func(c *Context) {})
+	assert.Nil(t, route.Meta("auth"))
+	assert.Equal(t, route, route.SetMeta("auth", "admin"))
+	assert.Equal(t, "admin", route.Meta("auth"))
+	route.SetMeta("auth", nil)
+	assert.Nil(t, route.Meta("auth"))
+
+	assert.Equal(t, 0, routePriority(route))
+	route.Priority(-2)
+	assert.Equal(t, -2, route.Meta(PriorityMetaKey))
+	assert.Equal(t, -2, routePriority(route))
+	assert.Equal(t, 0, routePriority(nil))
+	assert.Equal(t, -2, lowestPriority(engine))
+}
+
+func TestLoadShed(t *testing.T) {
+	engine := New()
+	// every sample is overloaded with the single allowed goroutine
+	engine.Use(LoadShed(LoadShedConfig{MaxGoroutines: 1, Interval: time.Millisecond}))
+	engine.GET("/reports", func(c *Context) {}).Priority(-1)
+	engine.GET("/users", func(c *Context) {})
+	engine.GET("/health", func(c *Context) {}).Priority(10)
+
+	// the level starts at the lowest priority, nothing is rejected
+	assert.Equal(t, 200, serveTestRequest(engine, "/reports").Response.StatusCode())
+
+	// the level rises to ProtectedPriority (1): the routes with priorities -1 and 0 are rejected
+	deadline := time.Now().Add(2 * time.Second)
+	for serveTestRequest(engine, "/users").Response.StatusCode() != 503 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	ctx := serveTestRequest(engine, "/reports")
+	assert.Equal(t, 503, ctx.Response.StatusCode())
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek("Retry-After")))
+	assert.Equal(t, 503, serveTestRequest(engine, "/users").Response.StatusCode())
+	assert.Equal(t, 200, serveTestRequest(engine, "/health").Response.StatusCode())
+
+	// the monitor is stopped by Shutdown, the repeated Shutdown doesn't panic
+	engine.Shutdown(context.Background())
+	engine.Shutdown(context.Background())
+}
+
+func TestLoadShedProtectedPriority(t *testing.T) {
+	engine := New()
+	protected := 0
+	engine.Use(LoadShed(LoadShedConfig{MaxGoroutines: 1, Interval: time.Millisecond, ProtectedPriority: &protected}))
+	engine.GET("/reports", func(c *Context) {}).Priority(-1)
+	engine.GET("/users", func(c *Context) {})
+	defer engine.Shutdown(context.Background())
+
+	// the level rises to 0 only: the default priority routes are protected
+	deadline := time.Now().Add(2 * time.Second)
+	for serveTestRequest(engine, "/reports").Response.StatusCode() != 503 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, 503, serveTestRequest(engine, "/reports").Response.StatusCode())
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 200, serveTestRequest(engine, "/users").Response.StatusCode())
+}
+
+func TestLoadShedMonitorStop(t *testing.T) {
+	s := &loadShedder{config: LoadShedConfig{MaxHeapBytes: 1, Interval: time.Millisecond}, protected: 1, stop: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		s.monitor(0)
+		close(done)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&s.level))
+	close(s.stop)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the monitor is not stopped")
+	}
+}
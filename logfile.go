@@ -0,0 +1,289 @@
+package tokay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// RotatingFileConfig is a struct for specifying RotatingFile options.
+	RotatingFileConfig struct {
+		// Filename is the log file path. The directories are created if needed.
+		Filename string
+		// MaxSize is the file size in bytes which triggers the rotation. Default is 100 MB, -1 disables it.
+		MaxSize int64
+		// Interval is the rotation period, like 24 * time.Hour (the rotations are aligned to the UTC midnight).
+		// Zero disables the time-based rotation.
+		Interval time.Duration
+		// MaxBackups is the number of the rotated files kept. Zero keeps all of them.
+		MaxBackups int
+		// Compress gzips the rotated files.
+		Compress bool
+	}
+
+	// RotatingFile is the log file writer rotating the file by size and time.
+	// The rotated files are renamed to "name-<time>.ext" (see NewRotatingFile).
+	RotatingFile struct {
+		config   RotatingFileConfig
+		mu       sync.Mutex
+		file     *os.File
+		size     int64
+		rotateAt time.Time
+		// background compresses and removes the rotated files
+		background sync.WaitGroup
+		cleanup    sync.Mutex
+	}
+
+	// textLogger is the FieldLogger writing "time LEVEL msg key=value ..." lines to the writer.
+	textLogger struct {
+		engine  *Engine
+		w       io.Writer
+		keyvals []interface{}
+	}
+)
+
+// backupTimeFormat is the time format of the rotated file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// NewRotatingFile opens the log file for appending. Register its Reopen method with Engine.OnReload to support
+// the external log rotation (SIGHUP triggers the reload, see RunWithSignals).
+//
+//	file, err := tokay.NewRotatingFile(tokay.RotatingFileConfig{
+//		Filename:   "/var/log/app/access.log",
+//		Interval:   24 * time.Hour,
+//		MaxBackups: 14,
+//		Compress:   true,
+//	})
+//	engine.OnReload(file.Reopen)
+//	engine.Use(tokay.AccessLog(file))
+func NewRotatingFile(config RotatingFileConfig) (*RotatingFile, error) {
+	if config.MaxSize == 0 {
+		config.MaxSize = 100 << 20
+	}
+	f := &RotatingFile{config: config}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write implements io.Writer. The file is rotated before the write exceeding the MaxSize.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	if f.size > 0 && (f.config.MaxSize > 0 && f.size+int64(len(p)) > f.config.MaxSize ||
+		f.config.Interval > 0 && !time.Now().Before(f.rotateAt)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate renames the current file to the backup name and opens the new one.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// Reopen closes and opens the file again, for example after it was moved by logrotate.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	return f.open()
+}
+
+// Close closes the file and waits for the rotated files compression.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+	f.background.Wait()
+	return err
+}
+
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.config.Filename), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(f.config.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	if f.config.Interval > 0 {
+		f.rotateAt = time.Now().UTC().Truncate(f.config.Interval).Add(f.config.Interval)
+	}
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		f.file.Close()
+		f.file = nil
+	}
+	name := f.config.Filename
+	ext := filepath.Ext(name)
+	backup := name[:len(name)-len(ext)] + "-" + time.Now().UTC().Format(backupTimeFormat) + ext
+	if err := os.Rename(name, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		f.cleanup.Lock()
+		defer f.cleanup.Unlock()
+		if f.config.Compress {
+			if err := compressFile(backup); err != nil {
+				errorlog.Println("cannot compress log file:", err)
+			}
+		}
+		f.removeBackups()
+	}()
+	return f.open()
+}
+
+// removeBackups removes the oldest rotated files exceeding MaxBackups.
+func (f *RotatingFile) removeBackups() {
+	if f.config.MaxBackups <= 0 {
+		return
+	}
+	name := f.config.Filename
+	ext := filepath.Ext(name)
+	backups, _ := filepath.Glob(name[:len(name)-len(ext)] + "-[0-9]*" + ext + "*")
+	// the names with times sort in the rotation order
+	sort.Strings(backups)
+	for i := 0; i < len(backups)-f.config.MaxBackups; i++ {
+		os.Remove(backups[i])
+	}
+}
+
+// compressFile gzips the file to name.gz and removes it.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	return os.Remove(name)
+}
+
+// LogToFile replaces the engine structured logger with the one writing to the rotating file. The file is
+// reopened on Reload and closed on Shutdown. The returned file can be shared with AccessLog.
+//
+//	file, err := engine.LogToFile(tokay.RotatingFileConfig{Filename: "logs/app.log", Compress: true})
+//	engine.Use(tokay.AccessLog(file))
+func (engine *Engine) LogToFile(config RotatingFileConfig) (*RotatingFile, error) {
+	file, err := NewRotatingFile(config)
+	if err != nil {
+		return nil, err
+	}
+	engine.logger = &textLogger{engine: engine, w: file}
+	engine.OnReload(file.Reopen)
+	engine.OnStop(func(ctx context.Context) error {
+		return file.Close()
+	})
+	return file, nil
+}
+
+func (l *textLogger) Debug(msg string, keyvals ...interface{}) {
+	if l.engine != nil && l.engine.Debug {
+		l.write("DEBUG", msg, keyvals)
+	}
+}
+
+func (l *textLogger) Info(msg string, keyvals ...interface{}) {
+	l.write("INFO", msg, keyvals)
+}
+
+func (l *textLogger) Warn(msg string, keyvals ...interface{}) {
+	l.write("WARN", msg, keyvals)
+}
+
+func (l *textLogger) Error(msg string, keyvals ...interface{}) {
+	l.write("ERROR", msg, keyvals)
+}
+
+func (l *textLogger) With(keyvals ...interface{}) FieldLogger {
+	return &textLogger{
+		engine:  l.engine,
+		w:       l.w,
+		keyvals: append(l.keyvals[:len(l.keyvals):len(l.keyvals)], keyvals...),
+	}
+}
+
+// write writes the entry with a single Write call, so the concurrent entries are not mixed.
+func (l *textLogger) write(level, msg string, keyvals []interface{}) {
+	var buf bytes.Buffer
+	buf.WriteString(time.Now().Format(time.RFC3339))
+	buf.WriteString(" " + level + " " + msg)
+	appendKeyvals(&buf, l.keyvals)
+	appendKeyvals(&buf, keyvals)
+	buf.WriteByte('\n')
+	l.w.Write(buf.Bytes())
+}
+
+// AccessLog returns a middleware writing the access log lines to the writer (see NewRotatingFile):
+//
+//	2024-01-02T15:04:05Z GET /users/1 status=200 bytes=512 duration=1.2ms client_ip=10.0.0.1 request_id=42
+func AccessLog(w io.Writer) Handler {
+	return func(c *Context) {
+		start := time.Now()
+		c.Next()
+		var buf bytes.Buffer
+		buf.WriteString(start.Format(time.RFC3339))
+		buf.WriteString(" " + c.Method() + " " + c.RequestURI())
+		appendKeyvals(&buf, []interface{}{
+			"status", c.Response.StatusCode(),
+			"bytes", len(c.Response.Body()),
+			"duration", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"request_id", c.RequestID(),
+		})
+		buf.WriteByte('\n')
+		w.Write(buf.Bytes())
+	}
+}
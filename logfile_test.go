@@ -0,0 +1,84 @@
+package tokay
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "logs", "app.log")
+
+	file, err := NewRotatingFile(RotatingFileConfig{Filename: name, MaxSize: 10, MaxBackups: 2, Compress: true})
+	assert.Nil(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err = file.Write([]byte(line))
+		assert.Nil(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+	assert.Nil(t, file.Close())
+
+	content, _ := ioutil.ReadFile(name)
+	assert.Equal(t, "fourth\n", string(content))
+	backups, _ := filepath.Glob(filepath.Join(dir, "logs", "app-*.log.gz"))
+	if assert.Len(t, backups, 2) {
+		zr, err := gzip.NewReader(mustOpen(t, backups[1]))
+		assert.Nil(t, err)
+		content, _ = ioutil.ReadAll(zr)
+		assert.Equal(t, "third\n", string(content))
+	}
+
+	// the file moved by logrotate is created again on reopen
+	file, err = NewRotatingFile(RotatingFileConfig{Filename: name})
+	assert.Nil(t, err)
+	assert.Nil(t, os.Rename(name, name+".1"))
+	assert.Nil(t, file.Reopen())
+	file.Write([]byte("reopened\n"))
+	file.Close()
+	content, _ = ioutil.ReadFile(name)
+	assert.Equal(t, "reopened\n", string(content))
+}
+
+func mustOpen(t *testing.T, name string) *os.File {
+	f, err := os.Open(name)
+	assert.Nil(t, err)
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func TestLogToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "app.log")
+
+	engine := New()
+	file, err := engine.LogToFile(RotatingFileConfig{Filename: name})
+	assert.Nil(t, err)
+	engine.Use(AccessLog(file))
+	engine.GET("/users/<id>", func(c *Context) {
+		c.Logger().Info("user loaded", "id", c.Param("id"))
+		c.String(200, "bob")
+	})
+	serveTestRequest(engine, "/users/1?full=1")
+	assert.Nil(t, engine.Reload())
+	file.Close()
+
+	content, _ := ioutil.ReadFile(name)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], " INFO user loaded request_id=")
+		assert.Contains(t, lines[0], "route=/users/<id>")
+		assert.Contains(t, lines[0], "id=1")
+		assert.Contains(t, lines[1], " GET /users/1?full=1 status=200 bytes=3 duration=")
+	}
+}
@@ -1,9 +1,13 @@
 package tokay
 
 import (
+	"bytes"
+	"fmt"
 	"io/ioutil"
 	lg "log"
 	"os"
+	"strconv"
+	"strings"
 )
 
 var (
@@ -14,3 +18,107 @@ var (
 	errorlog = lg.New(os.Stderr, "[ERROR] ", lg.Ldate|lg.Ltime|lg.Lshortfile)
 	log      = info
 )
+
+// RequestIDHeader is the request header with the request ID (see Context.RequestID).
+const RequestIDHeader = "X-Request-ID"
+
+// FieldLogger is the pluggable structured logger interface.
+// The keyvals are alternating keys and values: logger.Info("user created", "id", 1, "name", "bob").
+type FieldLogger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+	// With returns a logger which adds the keyvals to every entry.
+	With(keyvals ...interface{}) FieldLogger
+}
+
+// stdLogger is the default FieldLogger writing "msg key=value ..." lines to the package loggers.
+type stdLogger struct {
+	engine  *Engine
+	keyvals []interface{}
+}
+
+func (l *stdLogger) Debug(msg string, keyvals ...interface{}) {
+	if l.engine != nil && l.engine.Debug {
+		debug.Output(2, l.format(msg, keyvals))
+	}
+}
+
+func (l *stdLogger) Info(msg string, keyvals ...interface{}) {
+	info.Output(2, l.format(msg, keyvals))
+}
+
+func (l *stdLogger) Warn(msg string, keyvals ...interface{}) {
+	warning.Output(2, l.format(msg, keyvals))
+}
+
+func (l *stdLogger) Error(msg string, keyvals ...interface{}) {
+	errorlog.Output(2, l.format(msg, keyvals))
+}
+
+func (l *stdLogger) With(keyvals ...interface{}) FieldLogger {
+	return &stdLogger{
+		engine:  l.engine,
+		keyvals: append(l.keyvals[:len(l.keyvals):len(l.keyvals)], keyvals...),
+	}
+}
+
+func (l *stdLogger) format(msg string, keyvals []interface{}) string {
+	var buf bytes.Buffer
+	buf.WriteString(msg)
+	appendKeyvals(&buf, l.keyvals)
+	appendKeyvals(&buf, keyvals)
+	return buf.String()
+}
+
+// appendKeyvals writes " key=value" pairs into the buffer. Values with spaces are quoted.
+func appendKeyvals(buf *bytes.Buffer, keyvals []interface{}) {
+	for i := 0; i < len(keyvals); i += 2 {
+		var value interface{} = "(MISSING)"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		s := fmt.Sprint(value)
+		if strings.ContainsAny(s, " \t\n\"=") {
+			s = strconv.Quote(s)
+		}
+		fmt.Fprintf(buf, " %v=%s", keyvals[i], s)
+	}
+}
+
+// SetLogger replaces the engine structured logger.
+func (engine *Engine) SetLogger(logger FieldLogger) {
+	engine.logger = logger
+}
+
+// Logger returns the engine structured logger.
+func (engine *Engine) Logger() FieldLogger {
+	return engine.logger
+}
+
+// RequestID returns the request ID from the X-Request-ID request header or the
+// server-unique request number if the header is missing.
+func (c *Context) RequestID() string {
+	if id := c.Request.Header.Peek(RequestIDHeader); len(id) != 0 {
+		return string(id)
+	}
+	return strconv.FormatUint(c.ID(), 10)
+}
+
+// Logger returns the engine structured logger with the request fields: request ID,
+// route, client IP and authenticated user (AuthUserKey data item, when set).
+func (c *Context) Logger() FieldLogger {
+	if c.logger == nil {
+		keyvals := []interface{}{"request_id", c.RequestID()}
+		if c.route != nil {
+			keyvals = append(keyvals, "route", c.route.path)
+		}
+		keyvals = append(keyvals, "client_ip", c.ClientIP())
+		if user, ok := c.GetEx(AuthUserKey); ok {
+			keyvals = append(keyvals, "user", user)
+		}
+		c.logger = c.engine.logger.With(keyvals...)
+	}
+	return c.logger
+}
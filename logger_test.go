@@ -0,0 +1,41 @@
+package tokay
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger struct {
+	keyvals []interface{}
+}
+
+func (l *testLogger) Debug(msg string, keyvals ...interface{}) {}
+func (l *testLogger) Info(msg string, keyvals ...interface{})  {}
+func (l *testLogger) Warn(msg string, keyvals ...interface{})  {}
+func (l *testLogger) Error(msg string, keyvals ...interface{}) {}
+func (l *testLogger) With(keyvals ...interface{}) FieldLogger {
+	return &testLogger{keyvals: append(l.keyvals, keyvals...)}
+}
+
+func TestAppendKeyvals(t *testing.T) {
+	var buf bytes.Buffer
+	appendKeyvals(&buf, []interface{}{"id", 1, "name", "John Smith", "odd"})
+	assert.Equal(t, ` id=1 name="John Smith" odd=(MISSING)`, buf.String())
+
+	l := (&stdLogger{engine: New()}).With("a", 1).(*stdLogger)
+	assert.Equal(t, "msg a=1 b=2", l.format("msg", []interface{}{"b", 2}))
+}
+
+func TestContextLogger(t *testing.T) {
+	engine := New(&Config{Logger: &testLogger{}})
+	var keyvals []interface{}
+	engine.GET("/users/<id>", func(c *Context) {
+		c.Set(AuthUserKey, "admin")
+		keyvals = c.Logger().(*testLogger).keyvals
+	})
+
+	serveTestRequest(engine, "/users/1", withHeader(RequestIDHeader, "abc"), withHeader("X-Real-Ip", "10.0.0.1"))
+	assert.Equal(t, []interface{}{"request_id", "abc", "route", "/users/<id>", "client_ip", "10.0.0.1", "user", "admin"}, keyvals)
+}
@@ -0,0 +1,38 @@
+package tokay
+
+// MatchResult is the route matching the request method and path (see Engine.Match).
+type MatchResult struct {
+	// Route is the first registered route matching the method and path.
+	Route *Route
+	// Params are the path parameter values by names.
+	Params map[string]string
+	// Guarded are the routes registered with the same method and path pattern after Route.
+	// The route guards (see Route.When) choose between them at request time.
+	Guarded []*Route
+}
+
+// Match returns the route matching the method and path without handling a request, for example in the
+// fuzzing and property tests of the routing table. If the path matches several route patterns, the route
+// registered first wins, regardless of the patterns specificity. The route guards, the host checks
+// and the redirects are not applied. Match is safe for concurrent use after the routes are registered.
+//
+//	result, ok := engine.Match("GET", "/users/42")
+//	// result.Route.Path() == "/users/<id>", result.Params["id"] == "42"
+func (engine *Engine) Match(method, path string) (MatchResult, bool) {
+	pvalues := make([]string, engine.maxParams)
+	rh, pnames := engine.find(method, path, pvalues)
+	if rh == nil {
+		return MatchResult{}, false
+	}
+	result := MatchResult{Route: rh.route, Params: make(map[string]string, len(pnames))}
+	for i, name := range pnames {
+		// the first parameter of the same name wins, like in Context.Param
+		if _, ok := result.Params[name]; !ok {
+			result.Params[name] = pvalues[i]
+		}
+	}
+	for rh = rh.next; rh != nil; rh = rh.next {
+		result.Guarded = append(result.Guarded, rh.route)
+	}
+	return result, true
+}
@@ -0,0 +1,83 @@
+package tokay
+
+import (
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineMatch(t *testing.T) {
+	engine := New()
+	engine.SetLogger(&testLogger{})
+	users := engine.GET("/users/<id>", func(c *Context) {})
+	engine.GET("/users/admin", func(c *Context) {})
+	guarded := engine.GET("/users/<id>", func(c *Context) {}).When(func(c *Context) bool { return true })
+	files := engine.GET("/files/<dir>/*", func(c *Context) {})
+
+	result, ok := engine.Match("GET", "/users/admin")
+	assert.True(t, ok)
+	assert.Equal(t, users, result.Route)
+	assert.Equal(t, map[string]string{"id": "admin"}, result.Params)
+	assert.Equal(t, []*Route{guarded}, result.Guarded)
+
+	result, ok = engine.Match("GET", "/files/docs/a/b.txt")
+	assert.True(t, ok)
+	assert.Equal(t, files, result.Route)
+	assert.Equal(t, "docs", result.Params["dir"])
+
+	_, ok = engine.Match("POST", "/users/1")
+	assert.False(t, ok)
+	_, ok = engine.Match("GET", "/posts")
+	assert.False(t, ok)
+}
+
+// TestEngineMatchFirstRegistered checks the routing of random paths against
+// the first registered pattern matching the path.
+func TestEngineMatchFirstRegistered(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	randomPath := func(segments []string) string {
+		parts := make([]string, 1+random.Intn(3))
+		for i := range parts {
+			parts[i] = segments[random.Intn(len(segments))]
+		}
+		return "/" + strings.Join(parts, "/")
+	}
+	patternSegments := []string{"a", "b", "ab", "<x>", "<y>", "c<z>"}
+	pathSegments := []string{"a", "b", "ab", "q", "c", "cc", ""}
+	param := regexp.MustCompile(`<[^>]+>`)
+
+	for round := 0; round < 50; round++ {
+		engine := New()
+		engine.SetLogger(&testLogger{})
+		var patterns []string
+		var expressions []*regexp.Regexp
+		for i := 0; i < 8; i++ {
+			pattern := randomPath(patternSegments)
+			patterns = append(patterns, pattern)
+			// QuoteMeta does not escape "<" and ">", so the parameters are replaced after quoting
+			expressions = append(expressions, regexp.MustCompile("^"+param.ReplaceAllString(regexp.QuoteMeta(pattern), "[^/]*")+"$"))
+			engine.GET(pattern, func(c *Context) {})
+		}
+		for i := 0; i < 50; i++ {
+			path := randomPath(pathSegments)
+			expected := ""
+			for j, re := range expressions {
+				if re.MatchString(path) {
+					expected = patterns[j]
+					break
+				}
+			}
+			result, ok := engine.Match("GET", path)
+			if expected == "" {
+				assert.False(t, ok, "%v %s", patterns, path)
+				continue
+			}
+			if assert.True(t, ok, "%v %s", patterns, path) {
+				assert.Equal(t, expected, result.Route.Path(), "%v %s", patterns, path)
+			}
+		}
+	}
+}
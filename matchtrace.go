@@ -0,0 +1,153 @@
+package tokay
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// MatchDecision is the reason why a route did or did not match the request (see Context.MatchTrace).
+type MatchDecision string
+
+// The route matching decisions.
+const (
+	// MatchMatched is the route handling the request.
+	MatchMatched MatchDecision = "matched"
+	// MatchPrefixMiss means the path doesn't start with the static prefix of the route pattern.
+	MatchPrefixMiss MatchDecision = "prefix miss"
+	// MatchPatternMiss means the path doesn't fit the route pattern after its static prefix.
+	MatchPatternMiss MatchDecision = "pattern miss"
+	// MatchRegexFail means the path parameter value doesn't match the regular expression or the type of the parameter.
+	MatchRegexFail MatchDecision = "regex fail"
+	// MatchMethodMismatch means the route pattern matches the path, but the route has no request method handlers.
+	MatchMethodMismatch MatchDecision = "method mismatch"
+	// MatchGuardRejected means the route guards rejected the request (see Route.When).
+	MatchGuardRejected MatchDecision = "guard rejected"
+	// MatchShadowed means the route matches, but the route registered before it handles the request.
+	MatchShadowed MatchDecision = "shadowed"
+)
+
+// MatchStep is the matching decision about a route recorded in the TraceRouting mode.
+type MatchStep struct {
+	Route    *Route
+	Decision MatchDecision
+	// Param is the path parameter which failed the regular expression or the type (MatchRegexFail).
+	Param string
+	// Rest is the part of the path the pattern couldn't match (MatchPrefixMiss and MatchPatternMiss).
+	Rest string
+}
+
+// traceRegexps keeps the compiled regular expressions of the route parameters.
+var traceRegexps sync.Map
+
+// MatchTrace returns the matching decisions about every registered route in the registration order
+// when the engine TraceRouting mode is enabled, nil otherwise. The trace explains the 404 and 405
+// responses, for example the parameter value failing the regular expression.
+//
+//	for _, step := range c.MatchTrace() {
+//		log.Println(step.Route.Path(), step.Decision, step.Param)
+//	}
+func (c *Context) MatchTrace() []MatchStep {
+	return c.matchTrace
+}
+
+// traceMatch records the matching decisions about the routes for the request method and path
+// matched by the route (nil if no route matches).
+func (engine *Engine) traceMatch(method, path string, matched *Route) []MatchStep {
+	// the routes with the same pattern rejected by the route guards before the matched one
+	rejected := map[*Route]bool{}
+	rh, _ := engine.find(method, path, make([]string, engine.maxParams))
+	for ; rh != nil && rh.route != matched; rh = rh.next {
+		rejected[rh.route] = true
+	}
+
+	steps := make([]MatchStep, 0, len(engine.routeList))
+	for _, route := range engine.routeList {
+		step := MatchStep{Route: route}
+		step.Decision, step.Param, step.Rest = matchPattern(route.path, path)
+		if step.Decision == MatchMatched {
+			switch {
+			case !inStrings(method, route.methods):
+				step.Decision = MatchMethodMismatch
+			case rejected[route]:
+				step.Decision = MatchGuardRejected
+			case route != matched:
+				step.Decision = MatchShadowed
+			}
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// matchPattern matches the path to the route pattern token by token the same way as the routing tree.
+// It returns MatchMatched, MatchPrefixMiss, MatchPatternMiss or MatchRegexFail with the failed parameter.
+func matchPattern(pattern, path string) (decision MatchDecision, param, rest string) {
+	first := true
+	for pattern != "" {
+		p0 := strings.IndexByte(pattern, '<')
+		p1 := -1
+		if p0 >= 0 {
+			if p1 = strings.IndexByte(pattern[p0:], '>'); p1 >= 0 {
+				p1 += p0
+			}
+		}
+		if p0 != 0 || p1 < 0 {
+			// the static part
+			static := pattern
+			if p0 > 0 && p1 > 0 {
+				static = pattern[:p0]
+			}
+			if !strings.HasPrefix(path, static) {
+				if first {
+					return MatchPrefixMiss, "", path
+				}
+				return MatchPatternMiss, "", path
+			}
+			path, pattern = path[len(static):], pattern[len(static):]
+			first = false
+			continue
+		}
+
+		token := pattern[1:p1]
+		pattern = pattern[p1+1:]
+		first = false
+		name, expr := token, ""
+		if i := strings.IndexByte(token, ':'); i >= 0 {
+			name, expr = token[:i], token[i+1:]
+		}
+		switch {
+		case expr == ".*":
+			path = ""
+		case paramTypes[expr] != nil:
+			i := paramTypes[expr](path)
+			if i < 0 {
+				return MatchRegexFail, name, path
+			}
+			path = path[i:]
+		case expr != "":
+			var re *regexp.Regexp
+			if cached, ok := traceRegexps.Load(expr); ok {
+				re = cached.(*regexp.Regexp)
+			} else {
+				re = regexp.MustCompile("^" + expr)
+				traceRegexps.Store(expr, re)
+			}
+			loc := re.FindStringIndex(path)
+			if loc == nil {
+				return MatchRegexFail, name, path
+			}
+			path = path[loc[1]:]
+		default:
+			if i := strings.IndexByte(path, '/'); i >= 0 {
+				path = path[i:]
+			} else {
+				path = ""
+			}
+		}
+	}
+	if path != "" {
+		return MatchPatternMiss, "", path
+	}
+	return MatchMatched, "", ""
+}
@@ -0,0 +1,51 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchTrace(t *testing.T) {
+	engine := New()
+	engine.TraceRouting = true
+	var trace []MatchStep
+	engine.Use(func(c *Context) {
+		c.Next()
+		trace = c.MatchTrace()
+	})
+	h := func(c *Context) {}
+	engine.GET("/posts/<id>", h)
+	engine.GET("/users/<id:\\d+>/<tab:(posts|likes)>", h)
+	engine.GET("/users/<name>/profile", h)
+	engine.POST("/users/<name>", h)
+	engine.GET("/users/<name>", h).When(func(c *Context) bool { return false })
+	engine.GET("/users/<name>", h)
+	engine.GET("/users/*", h)
+
+	decisions := func() []MatchDecision {
+		list := make([]MatchDecision, len(trace))
+		for i, step := range trace {
+			list[i] = step.Decision
+		}
+		return list
+	}
+
+	serveTestRequest(engine, "/users/bob")
+	assert.Equal(t, []MatchDecision{MatchPrefixMiss, MatchRegexFail, MatchPatternMiss, MatchMethodMismatch,
+		MatchGuardRejected, MatchMatched, MatchShadowed}, decisions())
+	assert.Equal(t, "id", trace[1].Param)
+	assert.Equal(t, "bob", trace[1].Rest)
+	assert.Equal(t, "/posts/<id>", trace[0].Route.Path())
+
+	serveTestRequest(engine, "/users/1/friends")
+	assert.Equal(t, MatchRegexFail, trace[1].Decision)
+	assert.Equal(t, "tab", trace[1].Param)
+	assert.Equal(t, MatchPatternMiss, trace[2].Decision)
+	assert.Equal(t, "/friends", trace[2].Rest)
+	assert.Equal(t, MatchMatched, trace[6].Decision)
+
+	engine.TraceRouting = false
+	serveTestRequest(engine, "/users/bob")
+	assert.Nil(t, trace)
+}
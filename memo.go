@@ -0,0 +1,201 @@
+package tokay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocaleKey is the context data item with the request locale set by the i18n middlewares,
+// the memoized pages are kept per locale (see Route.Memoize).
+const LocaleKey = "locale"
+
+type (
+	// memoRule is the page memoization rule of the route (see Route.Memoize).
+	memoRule struct {
+		route *Route
+		ttl   time.Duration
+	}
+
+	// pageMemo keeps the memoized pages of the engine.
+	pageMemo struct {
+		sync.RWMutex
+		entries map[string]*memoEntry
+	}
+
+	// memoEntry is a memoized page.
+	memoEntry struct {
+		route       *Route
+		params      map[string]string
+		etag        string
+		contentType []byte
+		body        []byte
+		expires     time.Time
+	}
+)
+
+// Memoize keeps the rendered HTML pages of the GET and HEAD route in memory for the ttl, per host,
+// route parameters and locale (the LocaleKey context data item or the first Accept-Language tag).
+// The query string is not the part of the key. The memoized pages get the ETag header and the
+// requests with the matching If-None-Match header get 304 Not Modified. Like with CacheFor only
+// the last route handler is skipped. Successful text/html responses without cookies are memoized
+// (see also Engine.InvalidateMemo).
+//
+//	engine.GET("/pages/<slug>", showPage).Name("page").Memoize(10 * time.Minute)
+func (r *Route) Memoize(ttl time.Duration) *Route {
+	rule := &memoRule{route: r, ttl: ttl}
+	r.insert(rule.handle, "GET", "HEAD")
+	return r
+}
+
+// handle serves the memoized page or memoizes the page rendered by the next handler.
+func (rule *memoRule) handle(c *Context) {
+	memo := c.engine.memo
+	locale := requestLocale(c)
+	if _, ok := c.Get(LocaleKey).(string); !ok {
+		c.Response.Header.Add("Vary", "Accept-Language")
+	}
+	key := rule.key(c, locale)
+	if entry := memo.get(key); entry != nil {
+		c.SetETag(entry.etag)
+		if etagNoneMatch(c.GetHeader("If-None-Match"), entry.etag) {
+			c.SetStatusCode(http.StatusNotModified)
+		} else {
+			c.SetStatusCode(http.StatusOK)
+			c.Response.Header.SetContentTypeBytes(entry.contentType)
+			c.Response.SetBody(entry.body)
+		}
+		c.skipHandlers()
+		return
+	}
+
+	c.Next()
+	contentType := c.Response.Header.ContentType()
+	if c.Response.StatusCode() != http.StatusOK || c.Response.IsBodyStream() ||
+		len(c.Response.Header.Peek("Set-Cookie")) != 0 || !strings.HasPrefix(string(contentType), "text/html") {
+		return
+	}
+	sum := sha256.Sum256(c.Response.Body())
+	entry := &memoEntry{
+		route:       rule.route,
+		params:      make(map[string]string, len(c.pnames)),
+		etag:        `"` + hex.EncodeToString(sum[:12]) + `"`,
+		contentType: append([]byte(nil), contentType...),
+		body:        append([]byte(nil), c.Response.Body()...),
+		expires:     time.Now().Add(rule.ttl),
+	}
+	for i, name := range c.pnames {
+		if _, ok := entry.params[name]; !ok {
+			entry.params[name] = c.pvalues[i]
+		}
+	}
+	memo.set(key, entry)
+	c.SetETag(entry.etag)
+	if etagNoneMatch(c.GetHeader("If-None-Match"), entry.etag) {
+		c.Response.ResetBody()
+		c.SetStatusCode(http.StatusNotModified)
+	}
+}
+
+// key returns the memoization key of the request.
+func (rule *memoRule) key(c *Context, locale string) string {
+	var b strings.Builder
+	b.WriteString(c.Host())
+	b.WriteByte('\n')
+	b.WriteString(rule.route.path)
+	for i := range c.pnames {
+		b.WriteByte('\n')
+		b.WriteString(c.pvalues[i])
+	}
+	b.WriteByte('\n')
+	b.WriteString(locale)
+	return b.String()
+}
+
+// requestLocale returns the LocaleKey context data item or the first Accept-Language tag.
+func requestLocale(c *Context) string {
+	if locale, ok := c.Get(LocaleKey).(string); ok {
+		return locale
+	}
+	locale := c.GetHeader("Accept-Language")
+	if i := strings.IndexAny(locale, ",;"); i >= 0 {
+		locale = locale[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(locale))
+}
+
+// etagNoneMatch reports whether the If-None-Match header value matches the entity tag
+// using the weak comparison.
+func etagNoneMatch(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func newPageMemo() *pageMemo {
+	return &pageMemo{entries: make(map[string]*memoEntry)}
+}
+
+// get returns the fresh memoized page or nil.
+func (memo *pageMemo) get(key string) *memoEntry {
+	memo.RLock()
+	entry := memo.entries[key]
+	memo.RUnlock()
+	if entry == nil || time.Now().After(entry.expires) {
+		return nil
+	}
+	return entry
+}
+
+// set memoizes the page. Expired pages are removed when the memo is full.
+func (memo *pageMemo) set(key string, entry *memoEntry) {
+	memo.Lock()
+	defer memo.Unlock()
+	if len(memo.entries) >= maxCacheEntries {
+		now := time.Now()
+		for k, e := range memo.entries {
+			if now.After(e.expires) {
+				delete(memo.entries, k)
+			}
+		}
+		if len(memo.entries) >= maxCacheEntries {
+			return
+		}
+	}
+	memo.entries[key] = entry
+}
+
+// InvalidateMemo removes the memoized pages (see Route.Memoize) of the route with the name or path,
+// all of them or the ones with the parameter values given as name/value pairs.
+// It returns the number of removed pages.
+//
+//	engine.InvalidateMemo("page", "slug", page.Slug)
+func (engine *Engine) InvalidateMemo(route string, pairs ...interface{}) int {
+	memo := engine.memo
+	memo.Lock()
+	defer memo.Unlock()
+	removed := 0
+	for key, entry := range memo.entries {
+		if entry.route.name != route && entry.route.path != route {
+			continue
+		}
+		matched := true
+		for i := 0; i+1 < len(pairs) && matched; i += 2 {
+			matched = entry.params[fmt.Sprint(pairs[i])] == fmt.Sprint(pairs[i+1])
+		}
+		if matched {
+			delete(memo.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
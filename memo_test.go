@@ -0,0 +1,68 @@
+package tokay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouteMemoize(t *testing.T) {
+	engine := New()
+	calls, aborted := 0, 0
+	engine.OnAbort(func(c *Context) {
+		aborted++
+	})
+	engine.Use(func(c *Context) {
+		if lang := c.Query("lang"); lang != "" {
+			c.Set(LocaleKey, lang)
+		}
+	})
+	engine.GET("/pages/<slug>", func(c *Context) {
+		calls++
+		c.SetContentType("text/html; charset=utf-8")
+		c.SetBodyString("<h1>" + c.Param("slug") + " " + requestLocale(c) + "</h1>")
+	}).Name("page").Memoize(time.Minute)
+	engine.GET("/data", func(c *Context) {
+		calls++
+		c.JSON(200, calls)
+	}).Memoize(time.Minute)
+
+	request := func(uri, ifNoneMatch string) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, uri, withHeader("If-None-Match", ifNoneMatch))
+	}
+
+	ctx := request("/pages/about?lang=en", "")
+	etag := string(ctx.Response.Header.Peek("ETag"))
+	assert.NotEqual(t, "", etag)
+	assert.Equal(t, "<h1>about en</h1>", string(ctx.Response.Body()))
+	ctx = request("/pages/about?lang=en&utm=1", "")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "<h1>about en</h1>", string(ctx.Response.Body()))
+	assert.Equal(t, "text/html; charset=utf-8", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, etag, string(ctx.Response.Header.Peek("ETag")))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, aborted)
+
+	ctx = request("/pages/about?lang=en", "W/"+etag)
+	assert.Equal(t, 304, ctx.Response.StatusCode())
+	assert.Equal(t, "", string(ctx.Response.Body()))
+
+	ctx = request("/pages/about?lang=de", "")
+	assert.Equal(t, "<h1>about de</h1>", string(ctx.Response.Body()))
+	request("/pages/contacts?lang=en", "")
+	assert.Equal(t, 3, calls)
+
+	assert.Equal(t, 2, engine.InvalidateMemo("page", "slug", "about"))
+	ctx = request("/pages/about?lang=en", etag)
+	assert.Equal(t, 304, ctx.Response.StatusCode())
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, 2, engine.InvalidateMemo("/pages/<slug>"))
+	assert.Equal(t, 0, engine.InvalidateMemo("page"))
+
+	request("/data", "")
+	ctx = request("/data", "")
+	assert.Equal(t, "6", string(ctx.Response.Body()))
+	assert.Equal(t, "", string(ctx.Response.Header.Peek("ETag")))
+}
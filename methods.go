@@ -0,0 +1,48 @@
+package tokay
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DisableMethods rejects the requests with the HTTP methods, like "TRACE" and "CONNECT", with
+// 501 Not Implemented before routing. Any skips the disabled methods and the Allow headers don't
+// list them. It should be called before the routes are registered.
+//
+//	engine.DisableMethods("TRACE", "CONNECT")
+func (engine *Engine) DisableMethods(methods ...string) {
+	for _, method := range methods {
+		method = strings.ToUpper(method)
+		if !engine.isDisabledMethod(method) {
+			engine.disabledMethods = append(engine.disabledMethods, method)
+		}
+	}
+}
+
+// isDisabledMethod reports whether the HTTP method is disabled (see DisableMethods).
+func (engine *Engine) isDisabledMethod(method string) bool {
+	return len(engine.disabledMethods) != 0 && inStrings(method, engine.disabledMethods)
+}
+
+// notImplementedHandler responds to the requests with the disabled HTTP methods.
+func notImplementedHandler(c *Context) {
+	c.AbortWithError(http.StatusNotImplemented, nil)
+}
+
+// addAutoHEAD registers the GET handlers of the route for the HEAD method too (see Engine.HandleHEAD),
+// unless the route has its own HEAD handlers.
+func (r *Route) addAutoHEAD(handlers []Handler) {
+	if _, ok := r.chains["HEAD"]; ok && !r.autoHEAD {
+		return
+	}
+	if r.group.engine.isDisabledMethod("HEAD") {
+		return
+	}
+	if r.autoHEAD {
+		r.registered["HEAD"] = registeredHandlers{group: r.group.handlers, handlers: handlers}
+		r.rebuild()
+		return
+	}
+	r.add("HEAD", handlers)
+	r.autoHEAD = true
+}
@@ -0,0 +1,77 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisableMethods(t *testing.T) {
+	engine := New()
+	engine.DisableMethods("trace", "CONNECT")
+	engine.Any("/any", func(c *Context) {
+		c.String(200, c.Method())
+	})
+	engine.TRACE("/trace", func(c *Context) {
+		c.String(200, "trace")
+	})
+
+	assert.Equal(t, []string{"DELETE", "GET", "HEAD", "OPTIONS", "PATCH", "POST", "PUT"}, engine.AllowedMethods("/any"))
+	ctx := serveTestRequest(engine, "/any", withMethod("TRACE"))
+	assert.Equal(t, 501, ctx.Response.StatusCode())
+	ctx = serveTestRequest(engine, "/trace", withMethod("TRACE"))
+	assert.Equal(t, 501, ctx.Response.StatusCode())
+	ctx = serveTestRequest(engine, "/any", withMethod("CONNECT"))
+	assert.Equal(t, 501, ctx.Response.StatusCode())
+	ctx = serveTestRequest(engine, "/any", withMethod("PUT"))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "PUT", string(ctx.Response.Body()))
+}
+
+func TestHandleOPTIONS(t *testing.T) {
+	engine := New()
+	engine.GET("/users", func(c *Context) {})
+	ctx := serveTestRequest(engine, "/users", withMethod("OPTIONS"))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "GET, OPTIONS", string(ctx.Response.Header.Peek("Allow")))
+
+	engine = New()
+	engine.HandleOPTIONS = false
+	engine.GET("/users", func(c *Context) {})
+	engine.OPTIONS("/items", func(c *Context) {
+		c.String(200, "own")
+	})
+	ctx = serveTestRequest(engine, "/users", withMethod("OPTIONS"))
+	assert.Equal(t, 405, ctx.Response.StatusCode())
+	assert.Equal(t, "GET", string(ctx.Response.Header.Peek("Allow")))
+	ctx = serveTestRequest(engine, "/items", withMethod("OPTIONS"))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "own", string(ctx.Response.Body()))
+	assert.Equal(t, []string{"OPTIONS"}, engine.AllowedMethods("/items"))
+}
+
+func TestHandleHEAD(t *testing.T) {
+	engine := New()
+	engine.GET("/users", func(c *Context) {})
+	ctx := serveTestRequest(engine, "/users", withMethod("HEAD"))
+	assert.Equal(t, 405, ctx.Response.StatusCode())
+
+	engine = New()
+	engine.HandleHEAD = true
+	engine.GET("/users", func(c *Context) {
+		c.Response.Header.Set("X-Handler", "get")
+	})
+	route := engine.GET("/items", func(c *Context) {
+		c.Response.Header.Set("X-Handler", "get")
+	})
+	route.HEAD(func(c *Context) {
+		c.Response.Header.Set("X-Handler", "head")
+	})
+	assert.Equal(t, []string{"GET", "HEAD", "OPTIONS"}, engine.AllowedMethods("/users"))
+	ctx = serveTestRequest(engine, "/users", withMethod("HEAD"))
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "get", string(ctx.Response.Header.Peek("X-Handler")))
+	ctx = serveTestRequest(engine, "/items", withMethod("HEAD"))
+	assert.Equal(t, "head", string(ctx.Response.Header.Peek("X-Handler")))
+	assert.Equal(t, []string{"GET", "HEAD"}, route.methods)
+}
@@ -0,0 +1,165 @@
+package tokay
+
+import (
+	"bytes"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsSink receives the request metrics of the engine (see Config.MetricsSink).
+// Tags are "name:value" strings, the values of the engine tags have no '|', ',', '#' and ':' characters.
+// The requests with the methods missing in Methods are tagged with the "other" method.
+type MetricsSink interface {
+	Count(name string, value int64, tags []string)
+	Timing(name string, value time.Duration, tags []string)
+}
+
+// StatsDConfig is a struct for specifying StatsD metrics sink options.
+type StatsDConfig struct {
+	// Addr is the UDP address of the StatsD agent. Default is "127.0.0.1:8125".
+	Addr string
+	// Prefix is prepended to the metric names, for example "myapp.".
+	Prefix string
+	// Tags are added to all metrics.
+	Tags []string
+	// DogStatsD enables the DogStatsD tags extension (Datadog agent). Plain StatsD metrics have no tags.
+	DogStatsD bool
+	// SampleRate is the part of sent metrics from 0 to 1. Default is 1 (all metrics are sent).
+	SampleRate float64
+	// FlushInterval is the maximum time metrics are buffered. Default is 1 second.
+	FlushInterval time.Duration
+	// MaxPacketSize is the maximum UDP packet size. Default is 1432 bytes.
+	MaxPacketSize int
+}
+
+// StatsD is the MetricsSink sending metrics to the StatsD or DogStatsD agent over UDP.
+type StatsD struct {
+	sync.Mutex
+	config StatsDConfig
+	conn   net.Conn
+	buf    bytes.Buffer
+	stop   chan struct{}
+	closed sync.Once
+}
+
+// NewStatsD creates the StatsD metrics sink. Close it to flush the buffered metrics.
+//
+//	sink, err := tokay.NewStatsD(tokay.StatsDConfig{Prefix: "myapp.", DogStatsD: true, SampleRate: 0.5})
+//	engine := tokay.New(&tokay.Config{MetricsSink: sink})
+func NewStatsD(config StatsDConfig) (*StatsD, error) {
+	if config.Addr == "" {
+		config.Addr = "127.0.0.1:8125"
+	}
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		config.SampleRate = 1
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.MaxPacketSize <= 0 {
+		config.MaxPacketSize = 1432
+	}
+	conn, err := net.Dial("udp", config.Addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &StatsD{config: config, conn: conn, stop: make(chan struct{})}
+	go s.flushLoop()
+	return s, nil
+}
+
+// Count sends the counter metric.
+func (s *StatsD) Count(name string, value int64, tags []string) {
+	s.send(name, strconv.FormatInt(value, 10), "c", tags)
+}
+
+// Timing sends the timer metric in milliseconds.
+func (s *StatsD) Timing(name string, value time.Duration, tags []string) {
+	s.send(name, strconv.FormatFloat(float64(value)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// Close flushes the buffered metrics and closes the connection. The repeated calls do nothing.
+func (s *StatsD) Close() (err error) {
+	s.closed.Do(func() {
+		close(s.stop)
+		s.Lock()
+		s.flush()
+		s.Unlock()
+		err = s.conn.Close()
+	})
+	return
+}
+
+// send formats the metric line ("name:value|type|@rate|#tags") and buffers it.
+func (s *StatsD) send(name, value, typ string, tags []string) {
+	rate := s.config.SampleRate
+	if rate < 1 && rand.Float64() >= rate {
+		return
+	}
+	line := s.config.Prefix + name + ":" + value + "|" + typ
+	if rate < 1 {
+		line += "|@" + strconv.FormatFloat(rate, 'f', -1, 64)
+	}
+	if s.config.DogStatsD && len(s.config.Tags)+len(tags) != 0 {
+		line += "|#" + strings.Join(append(append([]string(nil), s.config.Tags...), tags...), ",")
+	}
+
+	s.Lock()
+	if s.buf.Len() != 0 && s.buf.Len()+1+len(line) > s.config.MaxPacketSize {
+		s.flush()
+	}
+	if s.buf.Len() != 0 {
+		s.buf.WriteByte('\n')
+	}
+	s.buf.WriteString(line)
+	s.Unlock()
+}
+
+// flush sends the buffered metrics. The lock must be held.
+func (s *StatsD) flush() {
+	if s.buf.Len() == 0 {
+		return
+	}
+	// metrics are lost while the agent is unavailable, like with any UDP StatsD client
+	s.conn.Write(s.buf.Bytes())
+	s.buf.Reset()
+}
+
+func (s *StatsD) flushLoop() {
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.Lock()
+			s.flush()
+			s.Unlock()
+		}
+	}
+}
+
+// recordMetrics sends the metrics of the handled request to the engine metrics sink.
+func (engine *Engine) recordMetrics(c *Context, duration time.Duration) {
+	route := "notfound"
+	if c.route != nil {
+		route = tagValueReplacer.Replace(c.route.path)
+	}
+	// the clients may send any method, the unknown ones are counted together
+	method := "other"
+	if inStrings(c.Method(), Methods) {
+		method = tagValueReplacer.Replace(c.Method())
+	}
+	tags := []string{"route:" + route, "method:" + method, "status:" + strconv.Itoa(c.Response.StatusCode())}
+	engine.metrics.Count("http.requests", 1, tags)
+	engine.metrics.Timing("http.request.duration", duration, tags)
+}
+
+// tagValueReplacer replaces the characters of the StatsD line format in the tag values
+// (the route patterns may have them in the regular expressions).
+var tagValueReplacer = strings.NewReplacer("|", "_", ",", "_", "#", "_", ":", "_")
@@ -0,0 +1,74 @@
+package tokay
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatsD(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	sink, err := NewStatsD(StatsDConfig{Addr: conn.LocalAddr().String(), Prefix: "app.", Tags: []string{"env:test"}, DogStatsD: true})
+	assert.Nil(t, err)
+	engine := New(&Config{MetricsSink: sink})
+	engine.GET("/users/<id>", func(c *Context) {
+		c.String(200, "OK")
+	})
+	engine.GET("/files/<name:[a-z]{1,3}|tmp#>", func(c *Context) {
+		c.String(200, "OK")
+	})
+	serveTestRequest(engine, "/users/1")
+	serveTestRequest(engine, "/files/tmp#")
+	serveTestRequest(engine, "/users/1", withMethod("GET|#a:b"))
+	assert.Nil(t, sink.Close())
+	assert.Nil(t, sink.Close())
+
+	buf := make([]byte, 2048)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	assert.Nil(t, err)
+	lines := strings.Split(string(buf[:n]), "\n")
+	if assert.Len(t, lines, 6) {
+		assert.Equal(t, "app.http.requests:1|c|#env:test,route:/users/<id>,method:GET,status:200", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "app.http.request.duration:"), lines[1])
+		assert.True(t, strings.HasSuffix(lines[1], "|ms|#env:test,route:/users/<id>,method:GET,status:200"), lines[1])
+		assert.Equal(t, "app.http.requests:1|c|#env:test,route:/files/<name_[a-z]{1_3}_tmp_>,method:GET,status:200", lines[2])
+		assert.True(t, strings.HasPrefix(lines[4], "app.http.requests:1|c|#env:test,route:"), lines[4])
+		assert.True(t, strings.Contains(lines[4], ",method:other,status:"), lines[4])
+	}
+}
+
+func TestStatsDSampling(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	sink, err := NewStatsD(StatsDConfig{Addr: conn.LocalAddr().String(), SampleRate: 0.5, MaxPacketSize: 64})
+	assert.Nil(t, err)
+	for i := 0; i < 1000; i++ {
+		sink.Count("hits", 1, []string{"a:b"})
+	}
+	assert.Nil(t, sink.Close())
+
+	received := 0
+	buf := make([]byte, 2048)
+	for {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			break
+		}
+		assert.True(t, n <= 64)
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			assert.Equal(t, "hits:1|c|@0.5", line)
+			received++
+		}
+	}
+	assert.True(t, received > 300 && received < 700, received)
+}
@@ -0,0 +1,103 @@
+package tokay
+
+import (
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// defaultMIMETypes are the media types of the extensions missing or inconsistent in the system MIME tables.
+var defaultMIMETypes = map[string]string{
+	".apk":         "application/vnd.android.package-archive",
+	".avif":        "image/avif",
+	".cbor":        "application/cbor",
+	".css":         "text/css; charset=utf-8",
+	".heic":        "image/heic",
+	".html":        "text/html; charset=utf-8",
+	".ico":         "image/x-icon",
+	".js":          "text/javascript; charset=utf-8",
+	".json":        "application/json",
+	".jxl":         "image/jxl",
+	".md":          "text/markdown; charset=utf-8",
+	".mjs":         "text/javascript; charset=utf-8",
+	".mp4":         "video/mp4",
+	".pdf":         "application/pdf",
+	".svg":         "image/svg+xml",
+	".txt":         "text/plain; charset=utf-8",
+	".wasm":        "application/wasm",
+	".webmanifest": "application/manifest+json",
+	".webm":        "video/webm",
+	".webp":        "image/webp",
+	".woff":        "font/woff",
+	".woff2":       "font/woff2",
+	".xml":         "text/xml; charset=utf-8",
+}
+
+func newMIMETypes() map[string]string {
+	types := make(map[string]string, len(defaultMIMETypes))
+	for ext, mediaType := range defaultMIMETypes {
+		types[ext] = mediaType
+	}
+	return types
+}
+
+// normalizeExt returns the lower-case extension with the leading dot.
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext != "" && ext[0] != '.' {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// AddMIMEType registers the media type of the file extension (with or without the leading dot)
+// used by Context.File, Context.Attachment, Static and ContentTypeByExtension.
+//
+//	engine.AddMIMEType(".glb", "model/gltf-binary")
+func (engine *Engine) AddMIMEType(ext, mediaType string) {
+	engine.mimeTypes[normalizeExt(ext)] = mediaType
+}
+
+// MIMEType returns the media type of the file extension: the registered one (see AddMIMEType),
+// the one from the system MIME tables or "application/octet-stream".
+func (engine *Engine) MIMEType(ext string) string {
+	ext = normalizeExt(ext)
+	if mediaType, ok := engine.mimeTypes[ext]; ok {
+		return mediaType
+	}
+	if mediaType := mime.TypeByExtension(ext); mediaType != "" {
+		return mediaType
+	}
+	return "application/octet-stream"
+}
+
+// ContentTypeByExtension sets the response Content-Type by the file extension (see Engine.MIMEType).
+//
+//	c.ContentTypeByExtension("wasm")
+//	c.SetBody(module)
+func (c *Context) ContentTypeByExtension(ext string) {
+	c.SetContentType(c.engine.MIMEType(ext))
+}
+
+// Attachment sends the local file as the attachment downloaded with the filename
+// (the Content-Disposition header).
+//
+//	c.Attachment("/data/reports/42.pdf", "report-2024.pdf")
+func (c *Context) Attachment(filepath, filename string) {
+	c.Response.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.File(filepath)
+}
+
+// setFileContentType sets the Content-Type of the served file if its extension is registered (see AddMIMEType).
+// The error and multipart responses keep their types.
+func (c *Context) setFileContentType(name string) {
+	status := c.Response.StatusCode()
+	if status != http.StatusOK && status != http.StatusPartialContent ||
+		strings.HasPrefix(string(c.Response.Header.ContentType()), "multipart/") {
+		return
+	}
+	if mediaType, ok := c.engine.mimeTypes[normalizeExt(filepath.Ext(name))]; ok {
+		c.SetContentType(mediaType)
+	}
+}
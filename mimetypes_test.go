@@ -0,0 +1,64 @@
+package tokay
+
+import (
+	"io/ioutil"
+	lg "log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestMIMETypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"app.wasm", "photo.AVIF", "model.glb", "notes.txt"} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("data"), 0600))
+	}
+
+	engine := New()
+	engine.AddMIMEType("glb", "model/gltf-binary")
+	engine.Static("/static", dir, false)
+	engine.GET("/file/<name>", func(c *Context) {
+		c.File(filepath.Join(dir, c.Param("name")))
+	})
+	engine.GET("/download", func(c *Context) {
+		c.Attachment(filepath.Join(dir, "model.glb"), "модель 1.glb")
+	})
+	engine.GET("/ext/<ext>", func(c *Context) {
+		c.ContentTypeByExtension(c.Param("ext"))
+	})
+
+	tests := []struct {
+		uri, contentType string
+	}{
+		{"/static/app.wasm", "application/wasm"},
+		{"/static/photo.AVIF", "image/avif"},
+		{"/static/model.glb", "model/gltf-binary"},
+		{"/file/app.wasm", "application/wasm"},
+		{"/file/notes.txt", "text/plain; charset=utf-8"},
+		{"/ext/apk", "application/vnd.android.package-archive"},
+		{"/ext/.glb", "model/gltf-binary"},
+		{"/ext/unknown-ext", "application/octet-stream"},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, test.uri)
+		assert.Equal(t, test.contentType, string(ctx.Response.Header.ContentType()), test.uri)
+	}
+
+	ctx := &fasthttp.RequestCtx{}
+	req := &fasthttp.Request{}
+	req.SetRequestURI("/static/missing.wasm")
+	ctx.Init(req, nil, lg.New(ioutil.Discard, "", 0))
+	engine.HandleRequest(ctx)
+	assert.Equal(t, 404, ctx.Response.StatusCode())
+	assert.NotEqual(t, "application/wasm", string(ctx.Response.Header.ContentType()))
+
+	ctx = serveTestRequest(engine, "/download")
+	assert.Equal(t, "model/gltf-binary", string(ctx.Response.Header.ContentType()))
+	assert.Equal(t, "attachment; filename*=utf-8''%D0%BC%D0%BE%D0%B4%D0%B5%D0%BB%D1%8C%201.glb", string(ctx.Response.Header.Peek("Content-Disposition")))
+	assert.Equal(t, "data", string(ctx.Response.Body()))
+}
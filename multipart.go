@@ -0,0 +1,71 @@
+package tokay
+
+import (
+	"mime/multipart"
+	"reflect"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	fileHeaderType      = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeaderSliceType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+// BindMultipart binds the passed struct pointer with the multipart/form-data values and the uploaded files
+// named by the "form" field tags. The files are bound to the *multipart.FileHeader and []*multipart.FileHeader
+// fields. Like with FormFile, the files are deleted after returning from the handlers.
+//
+//	type Profile struct {
+//		Name   string                  `form:"name" valid:"required"`
+//		Avatar *multipart.FileHeader   `form:"avatar"`
+//		Photos []*multipart.FileHeader `form:"photos"`
+//	}
+func (c *Context) BindMultipart(obj interface{}) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return err
+	}
+	args := fasthttp.AcquireArgs()
+	defer fasthttp.ReleaseArgs(args)
+	for name, values := range form.Value {
+		for _, value := range values {
+			args.Add(name, value)
+		}
+	}
+	if err = mapArgs(obj, args); err != nil {
+		return err
+	}
+	mapFiles(reflect.ValueOf(obj).Elem(), form.File)
+	return c.validate(nil, obj)
+}
+
+// mapFiles sets the file fields of the struct value by the "form" field tags.
+func mapFiles(val reflect.Value, files map[string][]*multipart.FileHeader) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		structField := val.Field(i)
+		if !structField.CanSet() {
+			continue
+		}
+		name := typeField.Tag.Get("form")
+		if name == "" {
+			if structField.Kind() == reflect.Struct {
+				mapFiles(structField, files)
+				continue
+			}
+			name = typeField.Name
+		}
+		headers := files[name]
+		if len(headers) == 0 {
+			continue
+		}
+		switch typeField.Type {
+		case fileHeaderType:
+			structField.Set(reflect.ValueOf(headers[0]))
+		case fileHeaderSliceType:
+			structField.Set(reflect.ValueOf(headers))
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package tokay
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type multipartProfile struct {
+	Name    string                  `form:"name" valid:"required"`
+	Age     int                     `form:"age"`
+	Tags    []string                `form:"tag"`
+	Avatar  *multipart.FileHeader   `form:"avatar"`
+	Photos  []*multipart.FileHeader `form:"photos"`
+	Missing *multipart.FileHeader   `form:"missing"`
+}
+
+// withMultipartBody sets the multipart/form-data request body with the values and the files.
+func withMultipartBody(values map[string][]string, files map[string][]string) testRequestOption {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, list := range values {
+		for _, value := range list {
+			w.WriteField(name, value)
+		}
+	}
+	for name, list := range files {
+		for _, filename := range list {
+			fw, _ := w.CreateFormFile(name, filename)
+			fw.Write([]byte("content of " + filename))
+		}
+	}
+	w.Close()
+	return withBody(w.FormDataContentType(), body.String())
+}
+
+func TestContextBindMultipart(t *testing.T) {
+	engine := New()
+	var profile multipartProfile
+	var err error
+	engine.POST("/profile", func(c *Context) {
+		profile = multipartProfile{}
+		err = c.Bind(&profile)
+	})
+
+	serveTestRequest(engine, "/profile", withMethod("POST"), withMultipartBody(map[string][]string{
+		"name": {"John"},
+		"age":  {"42"},
+		"tag":  {"a", "b"},
+	}, map[string][]string{
+		"avatar": {"me.png"},
+		"photos": {"1.jpg", "2.jpg"},
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, "John", profile.Name)
+	assert.Equal(t, 42, profile.Age)
+	assert.Equal(t, []string{"a", "b"}, profile.Tags)
+	if assert.NotNil(t, profile.Avatar) {
+		assert.Equal(t, "me.png", profile.Avatar.Filename)
+	}
+	if assert.Len(t, profile.Photos, 2) {
+		assert.Equal(t, "2.jpg", profile.Photos[1].Filename)
+	}
+	assert.Nil(t, profile.Missing)
+
+	serveTestRequest(engine, "/profile", withMethod("POST"), withMultipartBody(nil, map[string][]string{"avatar": {"me.png"}}))
+	assert.NotNil(t, err)
+	assert.NotNil(t, profile.Avatar)
+}
@@ -0,0 +1,73 @@
+package tokay
+
+import (
+	"sort"
+	"strings"
+)
+
+// DataNamespace is the namespace of the context data items (see Context.Namespace).
+type DataNamespace struct {
+	c      *Context
+	prefix string
+}
+
+// Namespace returns the namespace of the context data items, so the middleware packages
+// sharing the context data do not overwrite the items of each other. The items are stored
+// with the "name." key prefix: c.Namespace("auth").Get("user") is c.Get("auth.user").
+//
+//	c.Namespace("auth").Set("user", user)
+func (c *Context) Namespace(name string) DataNamespace {
+	return DataNamespace{c: c, prefix: name + "."}
+}
+
+// Get returns the data item of the namespace, nil if it cannot be found.
+func (ns DataNamespace) Get(name string) interface{} {
+	return ns.c.items().Get(ns.prefix + name)
+}
+
+// GetEx returns the data item of the namespace and whether it exists.
+func (ns DataNamespace) GetEx(name string) (interface{}, bool) {
+	return ns.c.items().GetEx(ns.prefix + name)
+}
+
+// Set stores the data item in the namespace.
+func (ns DataNamespace) Set(name string, value interface{}) {
+	ns.c.items().Set(ns.prefix+name, value)
+}
+
+// Unset removes the data item of the namespace.
+func (ns DataNamespace) Unset(name string) {
+	ns.c.items().Delete(ns.prefix + name)
+}
+
+// Keys returns the sorted names of the namespace data items (without the namespace prefix).
+func (ns DataNamespace) Keys() []string {
+	var keys []string
+	ns.c.items().Range(func(key string, value interface{}) {
+		if strings.HasPrefix(key, ns.prefix) {
+			keys = append(keys, key[len(ns.prefix):])
+		}
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// SetAll stores all the data items of the map in the context.
+func (c *Context) SetAll(items map[string]interface{}) {
+	data := c.items()
+	data.Lock()
+	for name, value := range items {
+		data.M[name] = value
+	}
+	data.Unlock()
+}
+
+// Keys returns the sorted names of all the context data items, including the namespaced ones.
+func (c *Context) Keys() []string {
+	keys := make([]string, 0, c.items().Len())
+	c.items().Range(func(key string, value interface{}) {
+		keys = append(keys, key)
+	})
+	sort.Strings(keys)
+	return keys
+}
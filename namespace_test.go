@@ -0,0 +1,33 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextNamespace(t *testing.T) {
+	c := newTestContext("/")
+
+	auth := c.Namespace("auth")
+	auth.Set("user", "john")
+	c.Namespace("session").Set("user", "guest")
+	c.Set("user", "flat")
+	c.SetAll(map[string]interface{}{"a": 1, "b": 2})
+
+	assert.Equal(t, "john", auth.Get("user"))
+	assert.Equal(t, "john", c.Get("auth.user"))
+	assert.Equal(t, "guest", c.Namespace("session").Get("user"))
+	assert.Equal(t, "flat", c.Get("user"))
+	assert.Equal(t, 2, c.Get("b"))
+
+	_, ok := auth.GetEx("token")
+	assert.False(t, ok)
+	auth.Set("token", "t")
+	assert.Equal(t, []string{"token", "user"}, auth.Keys())
+	assert.Equal(t, []string{"a", "auth.token", "auth.user", "b", "session.user", "user"}, c.Keys())
+
+	auth.Unset("token")
+	assert.Equal(t, []string{"user"}, auth.Keys())
+	assert.Nil(t, c.Namespace("none").Keys())
+}
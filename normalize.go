@@ -0,0 +1,123 @@
+package tokay
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// EncodedPolicy defines how NormalizePath handles percent-encoded sequences which remain in the path
+// after the regular decoding (double-encoded paths like "/%252e%252e/etc/passwd").
+type EncodedPolicy int
+
+const (
+	// RejectEncoded rejects double-encoded paths with 400 Bad Request.
+	RejectEncoded EncodedPolicy = iota
+	// DecodeEncoded decodes double-encoded sequences and normalizes the result.
+	DecodeEncoded
+	// KeepEncoded leaves double-encoded sequences as is.
+	KeepEncoded
+)
+
+// NormalizeConfig is a struct for specifying NormalizePath middleware options.
+type NormalizeConfig struct {
+	// Strict rejects non-canonical paths with 400 Bad Request instead of redirecting
+	// the client to the normalized path.
+	Strict bool
+	// Encoded is the policy for double-encoded sequences. Default is RejectEncoded.
+	Encoded EncodedPolicy
+	// KeepBackslashes disables replacing of "\" with "/".
+	KeepBackslashes bool
+}
+
+// NormalizePath returns a middleware which normalizes the request path: removes null bytes,
+// handles double-encoded sequences according to the config, converts unicode to NFC form,
+// replaces backslashes and collapses dot segments and duplicate slashes. Requests with invalid
+// paths are rejected with 400 Bad Request, requests with non-canonical paths are redirected to
+// the normalized path (or rejected in the Strict mode).
+//
+//	engine.Use(tokay.NormalizePath(tokay.NormalizeConfig{Strict: true}))
+func NormalizePath(config ...NormalizeConfig) Handler {
+	var cfg NormalizeConfig
+	if len(config) != 0 {
+		cfg = config[0]
+	}
+	return func(c *Context) {
+		original := c.Path()
+		normalized, ok := normalizePath(original, cfg)
+		if !ok || (normalized != original && cfg.Strict) {
+			c.AbortWithError(http.StatusBadRequest, nil)
+			return
+		}
+		if normalized != original {
+			statusCode := http.StatusMovedPermanently
+			if c.Method() != "GET" && c.Method() != "HEAD" {
+				statusCode = http.StatusPermanentRedirect
+			}
+			uri := (&url.URL{Path: normalized}).EscapedPath()
+			if query := c.URI().QueryString(); len(query) != 0 {
+				uri += "?" + string(query)
+			}
+			c.Redirect(statusCode, c.appURL(uri))
+			c.Abort()
+		}
+	}
+}
+
+// normalizePath returns the normalized path and false if the path is invalid.
+func normalizePath(p string, cfg NormalizeConfig) (string, bool) {
+	if strings.IndexByte(p, 0) >= 0 {
+		if cfg.Strict {
+			return "", false
+		}
+		p = strings.Replace(p, "\x00", "", -1)
+	}
+
+	if cfg.Encoded != KeepEncoded && hasEncoded(p) {
+		if cfg.Encoded == RejectEncoded {
+			return "", false
+		}
+		decoded, err := url.PathUnescape(p)
+		if err != nil || hasEncoded(decoded) || strings.IndexByte(decoded, 0) >= 0 {
+			return "", false
+		}
+		p = decoded
+	}
+
+	if !utf8.ValidString(p) {
+		return "", false
+	}
+	p = norm.NFC.String(p)
+
+	if !cfg.KeepBackslashes {
+		p = strings.Replace(p, "\\", "/", -1)
+	}
+
+	if p == "" || p[0] != '/' {
+		p = "/" + p
+	}
+	trailingSlash := len(p) > 1 && p[len(p)-1] == '/'
+	p = path.Clean(p)
+	if trailingSlash && p != "/" {
+		p += "/"
+	}
+	return p, true
+}
+
+// hasEncoded reports whether the string contains a percent-encoded sequence.
+func hasEncoded(s string) bool {
+	for i := 0; i+2 < len(s); i++ {
+		if s[i] == '%' && isHex(s[i+1]) && isHex(s[i+2]) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHex(b byte) bool {
+	return '0' <= b && b <= '9' || 'a' <= b && b <= 'f' || 'A' <= b && b <= 'F'
+}
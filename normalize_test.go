@@ -0,0 +1,36 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		cfg      NormalizeConfig
+		expected string
+		ok       bool
+	}{
+		{"/users/1", NormalizeConfig{}, "/users/1", true},
+		{"/users/", NormalizeConfig{}, "/users/", true},
+		{"/a/./b/../c", NormalizeConfig{}, "/a/c", true},
+		{"//a//b", NormalizeConfig{}, "/a/b", true},
+		{"/static/..\\..\\etc", NormalizeConfig{}, "/etc", true},
+		{"/static/..\\x", NormalizeConfig{KeepBackslashes: true}, "/static/..\\x", true},
+		{"/a\x00b", NormalizeConfig{}, "/ab", true},
+		{"/a\x00b", NormalizeConfig{Strict: true}, "", false},
+		{"/%2e%2e/etc/passwd", NormalizeConfig{}, "", false},
+		{"/static/%2e%2e/%2e%2e/etc", NormalizeConfig{Encoded: DecodeEncoded}, "/etc", true},
+		{"/%252e", NormalizeConfig{Encoded: DecodeEncoded}, "", false},
+		{"/%2e%2e", NormalizeConfig{Encoded: KeepEncoded}, "/%2e%2e", true},
+		{"/cafe\u0301", NormalizeConfig{}, "/caf\u00e9", true},
+		{"/\xff", NormalizeConfig{}, "", false},
+	}
+	for _, test := range tests {
+		actual, ok := normalizePath(test.path, test.cfg)
+		assert.Equal(t, test.ok, ok, "normalizePath("+test.path+") ok =")
+		assert.Equal(t, test.expected, actual, "normalizePath("+test.path+") =")
+	}
+}
@@ -0,0 +1,197 @@
+package tokay
+
+import (
+	"html"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sync/atomic"
+)
+
+type (
+	// NotFoundResolver tries to handle a request which no route matches (see Engine.NotFoundResolvers).
+	NotFoundResolver interface {
+		// Name is the resolver name used in the metrics.
+		Name() string
+		// Resolve handles the request and returns true, or returns false to pass the request
+		// to the next resolver.
+		Resolve(c *Context) bool
+	}
+
+	// resolverFunc is NotFoundResolver implemented by a function.
+	resolverFunc struct {
+		name    string
+		resolve func(c *Context) bool
+	}
+
+	// notFoundResolver counts the requests handled by the resolver.
+	notFoundResolver struct {
+		NotFoundResolver
+		resolved uint64
+	}
+)
+
+// NewNotFoundResolver returns the NotFoundResolver with the given name calling the function.
+func NewNotFoundResolver(name string, resolve func(c *Context) bool) NotFoundResolver {
+	return &resolverFunc{name: name, resolve: resolve}
+}
+
+// Name implements NotFoundResolver.
+func (r *resolverFunc) Name() string {
+	return r.name
+}
+
+// Resolve implements NotFoundResolver.
+func (r *resolverFunc) Resolve(c *Context) bool {
+	return r.resolve(c)
+}
+
+// NotFoundResolvers sets the resolvers tried in order when no route matches the request path,
+// before the NotFound handlers. Requests to the paths with routes for other methods are not
+// resolved, so they are answered with 405 Method Not Allowed as usual.
+//
+//	redirects, _ := tokay.RedirectResolver(map[string]string{"/old": "/new"})
+//	engine.NotFoundResolvers(
+//		tokay.FileResolver("./public"),
+//		redirects,
+//		tokay.SPAResolver("./public/index.html"),
+//	)
+func (engine *Engine) NotFoundResolvers(resolvers ...NotFoundResolver) {
+	engine.notFoundResolvers = make([]*notFoundResolver, len(resolvers))
+	for i, r := range resolvers {
+		engine.notFoundResolvers[i] = &notFoundResolver{NotFoundResolver: r}
+	}
+	engine.updateNotFoundHandlers()
+}
+
+// NotFoundStats returns the number of requests handled by every NotFound resolver by the resolver names.
+func (engine *Engine) NotFoundStats() map[string]uint64 {
+	stats := make(map[string]uint64, len(engine.notFoundResolvers))
+	for _, r := range engine.notFoundResolvers {
+		stats[r.Name()] += atomic.LoadUint64(&r.resolved)
+	}
+	return stats
+}
+
+// NotFoundFor specifies the handlers invoked instead of the NotFound ones when no route matches
+// a request with the method. The handlers registered via Use and the NotFound resolvers are invoked first.
+//
+//	engine.NotFoundFor("POST", tokay.MethodNotAllowedHandler, func(c *tokay.Context) {
+//		c.JSON(404, map[string]string{"error": "unknown endpoint " + c.Path()})
+//	})
+func (engine *Engine) NotFoundFor(method string, handlers ...Handler) {
+	if engine.notFoundFor == nil {
+		engine.notFoundFor = make(map[string][]Handler)
+	}
+	engine.notFoundFor[method] = handlers
+	engine.updateNotFoundHandlers()
+}
+
+// updateNotFoundHandlers combines the engine handlers, the resolvers and the NotFound handlers.
+func (engine *Engine) updateNotFoundHandlers() {
+	handlers := engine.handlers
+	if len(engine.notFoundResolvers) != 0 {
+		handlers = combineHandlers(handlers, []Handler{engine.resolveNotFound})
+	}
+	engine.notFoundHandlers = combineHandlers(handlers, engine.notFound)
+	engine.notFoundHandlersFor = make(map[string][]Handler, len(engine.notFoundFor))
+	for method, hh := range engine.notFoundFor {
+		engine.notFoundHandlersFor[method] = combineHandlers(handlers, hh)
+	}
+}
+
+// routeErrorType returns the type of the NotFound and MethodNotAllowed errors preferred by the client:
+// "application/json" for the JSON clients (by the Accept or Content-Type header), "text/html" for
+// the browsers or "text/plain".
+func routeErrorType(c *Context) string {
+	if c.GetHeader("Accept") == "" {
+		if contentType := c.GetHeader("Content-Type"); matchMediaType("application/json", contentType) == 2 {
+			return "application/json"
+		}
+	}
+	return c.Accepts("text/plain", "application/json", "text/html")
+}
+
+// routeError writes the error with the status as JSON, HTML or plain text (see routeErrorType).
+func routeError(c *Context, status int) {
+	text := http.StatusText(status)
+	switch routeErrorType(c) {
+	case "application/json":
+		c.JSON(status, map[string]interface{}{"error": text, "status": status, "path": c.Path()})
+	case "text/html":
+		c.SetStatusCode(status)
+		c.SetContentType("text/html; charset=utf-8")
+		c.SetBodyString("<!DOCTYPE html>\n<html><head><title>" + text + "</title></head><body><h1>" +
+			text + "</h1><p>" + html.EscapeString(c.Path()) + "</p></body></html>\n")
+	default:
+		c.String(status, text)
+	}
+}
+
+// resolveNotFound tries the NotFound resolvers.
+func (engine *Engine) resolveNotFound(c *Context) {
+	if engine.findAllowedMethods(c.Path()) != nil {
+		return
+	}
+	for _, r := range engine.notFoundResolvers {
+		if r.Resolve(c) {
+			atomic.AddUint64(&r.resolved, 1)
+			if engine.metrics != nil {
+				engine.metrics.Count("http.notfound.resolved", 1, []string{"resolver:" + r.Name()})
+			}
+			c.skipHandlers()
+			return
+		}
+	}
+}
+
+// FileResolver returns the resolver serving the existing files (and directory index.html files)
+// from the root directory.
+func FileResolver(root string) NotFoundResolver {
+	return NewNotFoundResolver("file", func(c *Context) bool {
+		if c.Method() != "GET" && c.Method() != "HEAD" {
+			return false
+		}
+		name := filepath.Join(root, filepath.FromSlash(path.Clean("/"+c.Path())))
+		fi, err := os.Stat(name)
+		if err == nil && fi.IsDir() {
+			name = filepath.Join(name, "index.html")
+			fi, err = os.Stat(name)
+		}
+		if err != nil || !fi.Mode().IsRegular() {
+			return false
+		}
+		c.File(name)
+		return true
+	})
+}
+
+// RedirectResolver returns the resolver redirecting the requests by the redirect map
+// (see Engine.Redirects for the format).
+func RedirectResolver(redirects map[string]string) (NotFoundResolver, error) {
+	table, err := newRedirectTable(redirects)
+	if err != nil {
+		return nil, err
+	}
+	return NewNotFoundResolver("redirect", func(c *Context) bool {
+		if redirect := table.find(c.Path()); redirect != nil {
+			redirect(c)
+			return true
+		}
+		return false
+	}), nil
+}
+
+// SPAResolver returns the resolver serving the single-page application index file to the GET
+// requests which accept HTML and have no file extension in the path, so the client-side
+// routes like "/app/users/1" are handled by the application.
+func SPAResolver(indexFile string) NotFoundResolver {
+	return NewNotFoundResolver("spa", func(c *Context) bool {
+		if c.Method() != "GET" && c.Method() != "HEAD" || path.Ext(c.Path()) != "" || c.Accepts("text/html") == "" {
+			return false
+		}
+		c.File(indexFile)
+		return true
+	})
+}
@@ -0,0 +1,79 @@
+package tokay
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotFoundResolvers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "app.js"), []byte("js"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("index"), 0600))
+
+	engine := New()
+	engine.POST("/api/users", func(c *Context) {})
+	redirects, err := RedirectResolver(map[string]string{"/old": "/new"})
+	assert.Nil(t, err)
+	engine.NotFoundResolvers(FileResolver(dir), redirects, SPAResolver(filepath.Join(dir, "index.html")))
+
+	tests := []struct {
+		method, uri, accept string
+		status              int
+		body                string
+	}{
+		{"GET", "/app.js", "", 200, "js"},
+		{"GET", "/../app.js", "", 200, "js"},
+		{"GET", "/old", "", 301, ""},
+		{"GET", "/users/1", "text/html", 200, "index"},
+		{"GET", "/users/1", "application/json", 404, `{"error":"Not Found","path":"/users/1","status":404}`},
+		{"GET", "/missing.css", "text/html", 404, "<!DOCTYPE html>\n<html><head><title>Not Found</title></head><body><h1>Not Found</h1><p>/missing.css</p></body></html>\n"},
+		{"GET", "/api/users", "text/html", 405, ""},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, test.uri, withMethod(test.method), withHeader("Accept", test.accept))
+		assert.Equal(t, test.status, ctx.Response.StatusCode(), test.uri)
+		if test.body != "" {
+			assert.Equal(t, test.body, string(ctx.Response.Body()), test.uri)
+		}
+	}
+	assert.Equal(t, map[string]uint64{"file": 2, "redirect": 1, "spa": 1}, engine.NotFoundStats())
+
+	_, err = RedirectResolver(map[string]string{"/a": "999 /b"})
+	assert.NotNil(t, err)
+}
+
+func TestNotFoundFor(t *testing.T) {
+	engine := New()
+	engine.GET("/users", func(c *Context) {})
+	engine.NotFoundFor("POST", MethodNotAllowedHandler, func(c *Context) {
+		c.String(404, "no such endpoint")
+	})
+
+	tests := []struct {
+		method, uri, accept, contentType string
+		status                           int
+		body                             string
+	}{
+		{"POST", "/missing", "", "", 404, "no such endpoint"},
+		{"POST", "/users", "", "", 405, ""},
+		{"GET", "/missing", "", "", 404, "Not Found"},
+		{"GET", "/missing", "*/*", "", 404, "Not Found"},
+		{"GET", "/missing", "application/json", "", 404, `{"error":"Not Found","path":"/missing","status":404}`},
+		{"PUT", "/missing", "", "application/json; charset=utf-8", 404, `{"error":"Not Found","path":"/missing","status":404}`},
+		{"GET", "/<missing>", "text/html,application/xhtml+xml,*/*;q=0.8", "", 404,
+			"<!DOCTYPE html>\n<html><head><title>Not Found</title></head><body><h1>Not Found</h1><p>/&lt;missing&gt;</p></body></html>\n"},
+		{"PUT", "/users", "application/json", "", 405, `{"error":"Method Not Allowed","path":"/users","status":405}`},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, test.uri, withMethod(test.method), withHeader("Accept", test.accept),
+			withBody(test.contentType, ""))
+		assert.Equal(t, test.status, ctx.Response.StatusCode(), test.method+" "+test.uri)
+		assert.Equal(t, test.body, string(ctx.Response.Body()), test.method+" "+test.uri)
+	}
+}
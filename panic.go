@@ -0,0 +1,107 @@
+package tokay
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+type (
+	// PanicReport describes a panic occurred while handling a request.
+	PanicReport struct {
+		Time     time.Time
+		Error    interface{}       // the value passed to panic()
+		Stack    string            // goroutine stack trace
+		Method   string            // request method
+		URI      string            // request URI
+		Route    string            // matched route path pattern, empty for the NotFound handlers
+		ClientIP string            // see Context.ClientIP()
+		Headers  map[string]string // request headers, sensitive values are redacted
+		Data     map[string]string // context data items (see Context.Set), sensitive values are redacted
+	}
+
+	// PanicReporter receives panic reports, for example to send them to a crash reporting service.
+	PanicReporter interface {
+		ReportPanic(report *PanicReport)
+	}
+
+	// PanicReporterFunc is an adapter to allow the use of ordinary functions as PanicReporter.
+	PanicReporterFunc func(report *PanicReport)
+)
+
+// RedactedValue replaces sensitive header and context data values in the panic reports.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys are the parts of header and context data names with redacted values.
+var sensitiveKeys = []string{"auth", "cookie", "pass", "secret", "token", "session", "key"}
+
+// ReportPanic calls f(report).
+func (f PanicReporterFunc) ReportPanic(report *PanicReport) {
+	f(report)
+}
+
+// OnPanic registers the reporters called when a request handler panics.
+// The panic is propagated after reporting, so use Recovery or Engine.PanicHandler to keep the server running.
+//
+//	engine.OnPanic(tokay.PanicReporterFunc(func(r *tokay.PanicReport) {
+//		sentry.CaptureMessage(fmt.Sprint(r.Error))
+//	}))
+func (engine *Engine) OnPanic(reporters ...PanicReporter) {
+	engine.panicReporters = append(engine.panicReporters, reporters...)
+}
+
+// reportPanic sends the report about the recovered panic value to the registered reporters.
+func (engine *Engine) reportPanic(c *Context, err interface{}) {
+	report := newPanicReport(c, err)
+	for _, reporter := range engine.panicReporters {
+		reporter.ReportPanic(report)
+	}
+}
+
+// newPanicReport creates a panic report for the current request.
+func newPanicReport(c *Context, err interface{}) *PanicReport {
+	report := &PanicReport{
+		Time:     time.Now(),
+		Error:    err,
+		Stack:    stack(),
+		Method:   c.Method(),
+		URI:      c.RequestURI(),
+		ClientIP: c.ClientIP(),
+		Headers:  make(map[string]string),
+		Data:     make(map[string]string),
+	}
+	if c.route != nil {
+		report.Route = c.route.path
+	}
+	c.Request.Header.VisitAll(func(key, value []byte) {
+		report.Headers[string(key)] = redact(string(key), string(value))
+	})
+	c.items().Range(func(key string, value interface{}) {
+		report.Data[key] = redact(key, fmt.Sprint(value))
+	})
+	return report
+}
+
+// redact hides the value if the name looks like a name of sensitive data.
+func redact(name, value string) string {
+	name = strings.ToLower(name)
+	for _, key := range sensitiveKeys {
+		if strings.Contains(name, key) {
+			return RedactedValue
+		}
+	}
+	return value
+}
+
+// stack returns the formatted stack trace of the current goroutine.
+func stack() string {
+	buf := make([]byte, 8192)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
@@ -0,0 +1,36 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnPanic(t *testing.T) {
+	var report *PanicReport
+	engine := New()
+	engine.OnPanic(PanicReporterFunc(func(r *PanicReport) {
+		report = r
+	}))
+	engine.GET("/users/<id>", func(c *Context) {
+		c.Set("user", "admin")
+		c.Set("sessionToken", "12345")
+		panic("boom")
+	})
+
+	ctx := newTestRequest("/users/1?x=2", withHeader("Authorization", "Basic Zm9vOmJhcg=="), withHeader("Accept", "text/html"))
+	assert.PanicsWithValue(t, "boom", func() {
+		engine.HandleRequest(ctx)
+	})
+
+	assert.NotNil(t, report)
+	assert.Equal(t, "boom", report.Error)
+	assert.Equal(t, "GET", report.Method)
+	assert.Equal(t, "/users/1?x=2", report.URI)
+	assert.Equal(t, "/users/<id>", report.Route)
+	assert.Equal(t, RedactedValue, report.Headers["Authorization"])
+	assert.Equal(t, "text/html", report.Headers["Accept"])
+	assert.Equal(t, "admin", report.Data["user"])
+	assert.Equal(t, RedactedValue, report.Data["sessionToken"])
+	assert.Contains(t, report.Stack, "goroutine")
+}
@@ -0,0 +1,104 @@
+package tokay
+
+// paramMatcher returns the length of the typed parameter value at the start of the path, -1 if the path
+// doesn't start with a value of the type.
+type paramMatcher func(path string) int
+
+// paramTypes are the matchers of the typed parameter tokens like "<id:int>", they are used instead
+// of the regular expressions with the same names:
+//
+//	int    an integer number with the optional minus sign, like -?\d+
+//	float  a decimal number with the optional fraction, like -?\d+(\.\d+)?
+//	alpha  ASCII letters, like [A-Za-z]+
+//	alnum  ASCII letters and digits, like [A-Za-z0-9]+
+//	uuid   a UUID in the canonical form, like 123e4567-e89b-12d3-a456-426614174000
+var paramTypes = map[string]paramMatcher{
+	"int":   matchIntParam,
+	"float": matchFloatParam,
+	"alpha": matchAlphaParam,
+	"alnum": matchAlnumParam,
+	"uuid":  matchUUIDParam,
+}
+
+func isDigit(b byte) bool {
+	return b >= '0' && b <= '9'
+}
+
+func isAlpha(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// matchDigits returns the number of the leading digits of s.
+func matchDigits(s string) int {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+func matchIntParam(path string) int {
+	sign := 0
+	if len(path) > 0 && path[0] == '-' {
+		sign = 1
+	}
+	if n := matchDigits(path[sign:]); n > 0 {
+		return sign + n
+	}
+	return -1
+}
+
+func matchFloatParam(path string) int {
+	i := matchIntParam(path)
+	if i < 0 {
+		return -1
+	}
+	if i+1 < len(path) && path[i] == '.' {
+		if n := matchDigits(path[i+1:]); n > 0 {
+			i += 1 + n
+		}
+	}
+	return i
+}
+
+func matchAlphaParam(path string) int {
+	i := 0
+	for i < len(path) && isAlpha(path[i]) {
+		i++
+	}
+	if i == 0 {
+		return -1
+	}
+	return i
+}
+
+func matchAlnumParam(path string) int {
+	i := 0
+	for i < len(path) && (isAlpha(path[i]) || isDigit(path[i])) {
+		i++
+	}
+	if i == 0 {
+		return -1
+	}
+	return i
+}
+
+func matchUUIDParam(path string) int {
+	const size = 36
+	if len(path) < size {
+		return -1
+	}
+	for i := 0; i < size; i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if path[i] != '-' {
+				return -1
+			}
+		default:
+			if !isHex(path[i]) {
+				return -1
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,72 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamTypes(t *testing.T) {
+	tests := []struct {
+		typ, path string
+		n         int
+	}{
+		{"int", "123", 3},
+		{"int", "-12/x", 3},
+		{"int", "12.5", 2},
+		{"int", "-", -1},
+		{"int", "abc", -1},
+		{"float", "12.5", 4},
+		{"float", "-3", 2},
+		{"float", "3.", 1},
+		{"float", ".5", -1},
+		{"alpha", "abcXYZ1", 6},
+		{"alpha", "1a", -1},
+		{"alnum", "a1B2-", 4},
+		{"alnum", "", -1},
+		{"uuid", "123e4567-e89b-12d3-a456-426614174000/x", 36},
+		{"uuid", "123e4567-e89b-12d3-a456-42661417400", -1},
+		{"uuid", "123e4567xe89b-12d3-a456-426614174000", -1},
+		{"uuid", "g23e4567-e89b-12d3-a456-426614174000", -1},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.n, paramTypes[test.typ](test.path), test.typ+" "+test.path)
+	}
+}
+
+func TestTypedParamRoutes(t *testing.T) {
+	engine := New()
+	engine.GET("/users/<id:int>", func(c *Context) {
+		c.String(200, "user "+c.Param("id"))
+	})
+	engine.GET("/users/<name:alpha>", func(c *Context) {
+		c.String(200, "name "+c.Param("name"))
+	})
+	engine.GET("/price/<amount:float>.json", func(c *Context) {
+		c.String(200, "price "+c.Param("amount"))
+	})
+	engine.GET("/orders/<id:uuid>", func(c *Context) {
+		c.String(200, "order "+c.Param("id"))
+	})
+
+	tests := []struct {
+		path, body string
+		code       int
+	}{
+		{"/users/42", "user 42", 200},
+		{"/users/bob", "name bob", 200},
+		{"/users/bob42", "", 404},
+		{"/price/9.99.json", "price 9.99", 200},
+		{"/price/abc.json", "", 404},
+		{"/orders/123e4567-e89b-12d3-a456-426614174000", "order 123e4567-e89b-12d3-a456-426614174000", 200},
+		{"/orders/123", "", 404},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, test.path)
+		assert.Equal(t, test.code, ctx.Response.StatusCode(), test.path)
+		if test.code == 200 {
+			assert.Equal(t, test.body, string(ctx.Response.Body()), test.path)
+		}
+	}
+	assert.Equal(t, "/users/7", engine.Route("/users/<id:int>").URL("id", 7))
+}
@@ -0,0 +1,119 @@
+package tokay
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// PartWriter writes the parts of the multipart/x-mixed-replace response (see Context.MultipartStream).
+type PartWriter struct {
+	sync.Mutex
+	pw       *io.PipeWriter
+	w        *bufio.Writer
+	boundary string
+	count    int
+	closed   bool
+}
+
+// errPartWriterClosed is returned by PartWriter.WritePart after Close.
+var errPartWriterClosed = errors.New("multipart stream is closed")
+
+// MultipartStream starts the multipart/x-mixed-replace response for the motion JPEG (camera) streams
+// and the incremental document delivery: the client replaces the shown part with every new one.
+// A random boundary is used when the boundary is empty. Every part is flushed to the client as it is
+// written. The response is not compressed.
+//
+// The response body is sent after the handler returns, so the parts must be written from
+// a separate goroutine which must not use the Context. WritePart returns an error when the client
+// has gone away, Close finishes the stream.
+//
+//	w := c.MultipartStream("frame")
+//	go func() {
+//		defer w.Close()
+//		for frame := range camera.Frames() {
+//			if err := w.WritePart("image/jpeg", frame); err != nil {
+//				return
+//			}
+//		}
+//	}()
+func (c *Context) MultipartStream(boundary string) *PartWriter {
+	if boundary == "" {
+		boundary = multipart.NewWriter(nil).Boundary()
+	}
+	pr, pw := io.Pipe()
+	c.SetStatusCode(200)
+	c.SetContentType("multipart/x-mixed-replace; boundary=" + boundary)
+	c.Response.Header.Set("Cache-Control", "no-cache, no-store")
+	c.noCompress = true
+	c.SetBodyStream(pr, -1)
+	return &PartWriter{pw: pw, w: bufio.NewWriter(pw), boundary: boundary}
+}
+
+// Boundary returns the boundary of the parts.
+func (w *PartWriter) Boundary() string {
+	return w.boundary
+}
+
+// WritePart writes the part with the content type and flushes it to the client.
+func (w *PartWriter) WritePart(contentType string, data []byte) error {
+	return w.WritePartHeader(textproto.MIMEHeader{"Content-Type": {contentType}}, data)
+}
+
+// WritePartHeader writes the part with the headers and flushes it to the client.
+// The Content-Length header is set by the data length.
+func (w *PartWriter) WritePartHeader(header textproto.MIMEHeader, data []byte) error {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return errPartWriterClosed
+	}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		if textproto.CanonicalMIMEHeaderKey(name) != "Content-Length" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	w.w.WriteString("--" + w.boundary + "\r\n")
+	for _, name := range names {
+		for _, value := range header[name] {
+			w.w.WriteString(name + ": " + value + "\r\n")
+		}
+	}
+	w.w.WriteString("Content-Length: " + strconv.Itoa(len(data)) + "\r\n\r\n")
+	w.w.Write(data)
+	w.w.WriteString("\r\n")
+	if err := w.w.Flush(); err != nil {
+		w.closed = true
+		return err
+	}
+	w.count++
+	return nil
+}
+
+// Count returns the number of written parts.
+func (w *PartWriter) Count() int {
+	w.Lock()
+	defer w.Unlock()
+	return w.count
+}
+
+// Close writes the closing boundary and finishes the response.
+func (w *PartWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+	if w.closed {
+		return w.pw.Close()
+	}
+	w.closed = true
+	w.w.WriteString("--" + w.boundary + "--\r\n")
+	err := w.w.Flush()
+	w.pw.Close()
+	return err
+}
@@ -0,0 +1,63 @@
+package tokay
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContextMultipartStream(t *testing.T) {
+	engine := New()
+	engine.GET("/camera", func(c *Context) {
+		w := c.MultipartStream(c.Query("boundary"))
+		go func() {
+			defer w.Close()
+			w.WritePart("image/jpeg", []byte("frame1"))
+			w.WritePartHeader(textproto.MIMEHeader{"Content-Type": {"image/jpeg"}, "X-Timestamp": {"2"}}, []byte("frame2"))
+		}()
+	})
+
+	ctx := serveTestRequest(engine, "/camera?boundary=frame")
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	assert.Nil(t, ctx.Response.Write(bw))
+	bw.Flush()
+	var resp fasthttp.Response
+	assert.Nil(t, resp.Read(bufio.NewReader(&buf)))
+	assert.Equal(t, "multipart/x-mixed-replace; boundary=frame", string(resp.Header.ContentType()))
+	assert.Equal(t, "--frame\r\nContent-Type: image/jpeg\r\nContent-Length: 6\r\n\r\nframe1\r\n"+
+		"--frame\r\nContent-Type: image/jpeg\r\nX-Timestamp: 2\r\nContent-Length: 6\r\n\r\nframe2\r\n--frame--\r\n", string(resp.Body()))
+
+	_, params, err := mime.ParseMediaType(string(resp.Header.ContentType()))
+	assert.Nil(t, err)
+	reader := multipart.NewReader(bytes.NewReader(resp.Body()), params["boundary"])
+	for _, expected := range []string{"frame1", "frame2"} {
+		part, err := reader.NextPart()
+		if !assert.Nil(t, err) {
+			return
+		}
+		data, _ := ioutil.ReadAll(part)
+		assert.Equal(t, expected, string(data))
+	}
+
+	ctx = serveTestRequest(engine, "/camera")
+	_, params, _ = mime.ParseMediaType(string(ctx.Response.Header.ContentType()))
+	assert.NotEmpty(t, params["boundary"])
+}
+
+func TestPartWriterClientGone(t *testing.T) {
+	c := newTestContext("/")
+	w := c.MultipartStream("frame")
+	c.Response.ResetBody() // closes the body stream like a failed write to the client
+	assert.NotNil(t, w.WritePart("image/jpeg", []byte("frame")))
+	assert.Equal(t, errPartWriterClosed, w.WritePart("image/jpeg", []byte("frame")))
+	assert.Equal(t, 0, w.Count())
+	assert.Nil(t, w.Close())
+}
@@ -0,0 +1,261 @@
+package tokay
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ProxyConfig is a struct for specifying ReverseProxy options.
+type ProxyConfig struct {
+	// Client sends the upstream requests. Default is a zero fasthttp.Client.
+	Client *fasthttp.Client
+	// Timeout is the timeout of a single upstream attempt. Default is 30 seconds.
+	Timeout time.Duration
+	// MaxAttempts is the maximum number of upstream attempts (retries and hedged requests)
+	// per client request. Default is 1, so neither retries nor hedging are used.
+	MaxAttempts int
+	// Hedge enables hedged requests: when the upstream does not respond within HedgeDelay,
+	// one more attempt is sent and the first successful response wins.
+	Hedge bool
+	// HedgeDelay is the delay of the hedged attempt. By default it is the observed p95
+	// latency of the upstream (no hedging until enough responses are observed).
+	HedgeDelay time.Duration
+	// RetryRatio is the retry budget: the number of extra attempts earned by every client
+	// request. Default is 0.1, so retries and hedges add at most 10% of the upstream load.
+	RetryRatio float64
+	// RetryBurst is the maximum number of extra attempts made in a row when the budget is full.
+	// Default is 10.
+	RetryBurst int
+	// RetryNonIdempotent enables retries and hedging for the non-idempotent requests (POST, PATCH)
+	// without the Idempotency-Key header.
+	RetryNonIdempotent bool
+}
+
+type (
+	// reverseProxy forwards requests to the upstream.
+	reverseProxy struct {
+		config  ProxyConfig
+		target  string
+		budget  retryBudget
+		latency latencyWindow
+	}
+
+	// proxyResult is the result of a single upstream attempt.
+	proxyResult struct {
+		resp     *fasthttp.Response
+		err      error
+		duration time.Duration
+	}
+
+	// retryBudget is a token bucket limiting the number of extra upstream attempts.
+	retryBudget struct {
+		sync.Mutex
+		ratio  float64
+		burst  float64
+		tokens float64
+	}
+
+	// latencyWindow keeps the recent upstream latencies to estimate the hedging delay.
+	latencyWindow struct {
+		sync.Mutex
+		samples []time.Duration
+		next    int
+		count   int
+		p95     time.Duration
+	}
+)
+
+// latencySamples is the size of the latency window and minLatencySamples is the number of
+// samples required to estimate the hedging delay.
+const (
+	latencySamples    = 1000
+	minLatencySamples = 20
+)
+
+// ReverseProxy returns a handler which forwards requests to the target ("http://backend:8080")
+// keeping the request URI. With MaxAttempts above 1 the failed idempotent requests (connection
+// errors, 502, 503 and 504 responses) are retried and, if Hedge is set, slow ones are hedged.
+// Retries and hedges are limited by the retry budget, so they cannot multiply the load of
+// the overloaded upstream.
+//
+//	engine.Any("/api/*", tokay.ReverseProxy("http://backend:8080", tokay.ProxyConfig{
+//		MaxAttempts: 2,
+//		Hedge:       true,
+//	}))
+func ReverseProxy(target string, config ...ProxyConfig) Handler {
+	var cfg ProxyConfig
+	if len(config) != 0 {
+		cfg = config[0]
+	}
+	if cfg.Client == nil {
+		cfg.Client = &fasthttp.Client{}
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.RetryRatio <= 0 {
+		cfg.RetryRatio = 0.1
+	}
+	if cfg.RetryBurst <= 0 {
+		cfg.RetryBurst = 10
+	}
+	p := &reverseProxy{
+		config:  cfg,
+		target:  strings.TrimSuffix(target, "/"),
+		budget:  retryBudget{ratio: cfg.RetryRatio, burst: float64(cfg.RetryBurst), tokens: float64(cfg.RetryBurst)},
+		latency: latencyWindow{samples: make([]time.Duration, latencySamples)},
+	}
+	return p.serve
+}
+
+// serve forwards the request and writes the upstream response.
+func (p *reverseProxy) serve(c *Context) {
+	retryable := p.config.MaxAttempts > 1 && (p.config.RetryNonIdempotent || isIdempotent(c))
+	p.budget.deposit()
+
+	results := make(chan *proxyResult, p.config.MaxAttempts)
+	attempts, inflight := 0, 0
+	launch := func() {
+		req := &fasthttp.Request{}
+		c.Request.CopyTo(req)
+		req.SetRequestURI(p.target + c.RequestURI())
+		req.Header.Del("Connection")
+		req.Header.Set("X-Forwarded-For", forwardedFor(c))
+		attempts++
+		inflight++
+		go p.attempt(req, results)
+	}
+	launch()
+
+	var hedge <-chan time.Time
+	if retryable && p.config.Hedge {
+		delay := p.config.HedgeDelay
+		if delay <= 0 {
+			delay = p.latency.percentile()
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			hedge = timer.C
+		}
+	}
+
+	var last *proxyResult
+	for inflight != 0 {
+		select {
+		case res := <-results:
+			inflight--
+			last = res
+			if res.err == nil {
+				p.latency.add(res.duration)
+			}
+			if !res.failed() {
+				inflight = 0
+			} else if inflight == 0 && retryable && attempts < p.config.MaxAttempts && p.budget.withdraw() {
+				launch()
+			}
+		case <-hedge:
+			hedge = nil
+			if attempts < p.config.MaxAttempts && p.budget.withdraw() {
+				launch()
+			}
+		}
+	}
+
+	if last.err != nil {
+		c.AbortWithError(http.StatusBadGateway, last.err)
+		return
+	}
+	last.resp.CopyTo(&c.Response)
+}
+
+// attempt sends the upstream request and reports the result.
+func (p *reverseProxy) attempt(req *fasthttp.Request, results chan<- *proxyResult) {
+	start := time.Now()
+	resp := &fasthttp.Response{}
+	err := p.config.Client.DoTimeout(req, resp, p.config.Timeout)
+	results <- &proxyResult{resp: resp, err: err, duration: time.Since(start)}
+}
+
+// failed reports whether the attempt may be retried.
+func (res *proxyResult) failed() bool {
+	if res.err != nil {
+		return true
+	}
+	switch res.resp.StatusCode() {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isIdempotent reports whether the request may be safely sent to the upstream more than once.
+func isIdempotent(c *Context) bool {
+	switch c.Method() {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return true
+	}
+	return c.GetHeader("Idempotency-Key") != ""
+}
+
+// forwardedFor returns the X-Forwarded-For header value with the client IP appended.
+func forwardedFor(c *Context) string {
+	ip := c.RemoteIP().String()
+	if prior := c.GetHeader("X-Forwarded-For"); prior != "" {
+		return prior + ", " + ip
+	}
+	return ip
+}
+
+// deposit adds the budget earned by a client request.
+func (b *retryBudget) deposit() {
+	b.Lock()
+	if b.tokens += b.ratio; b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.Unlock()
+}
+
+// withdraw takes the budget of one extra attempt. It returns false if the budget is exhausted.
+func (b *retryBudget) withdraw() bool {
+	b.Lock()
+	defer b.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// add records the latency of the upstream response.
+func (w *latencyWindow) add(d time.Duration) {
+	w.Lock()
+	defer w.Unlock()
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % len(w.samples)
+	if w.count < len(w.samples) {
+		w.count++
+	}
+	// the percentile is recalculated on every 10th sample to keep the proxy cheap
+	if w.count >= minLatencySamples && w.next%10 == 0 {
+		sorted := make([]time.Duration, w.count)
+		copy(sorted, w.samples[:w.count])
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		w.p95 = sorted[w.count*95/100]
+	}
+}
+
+// percentile returns the p95 latency or 0 if there are not enough samples.
+func (w *latencyWindow) percentile() time.Duration {
+	w.Lock()
+	defer w.Unlock()
+	return w.p95
+}
@@ -0,0 +1,91 @@
+package tokay
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// runUpstream serves the handler on a free port and returns the upstream URL.
+func runUpstream(t *testing.T, handler fasthttp.RequestHandler) (string, func()) {
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go fasthttp.Serve(ln, handler)
+	return "http://" + ln.Addr().String(), func() { ln.Close() }
+}
+
+func TestReverseProxyRetry(t *testing.T) {
+	var calls int32
+	target, stop := runUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			ctx.SetStatusCode(503)
+			return
+		}
+		ctx.SetBodyString(string(ctx.RequestURI()) + " " + string(ctx.Request.Header.Peek("X-Forwarded-For")))
+	})
+	defer stop()
+
+	engine := New()
+	engine.Any("/api/*", ReverseProxy(target, ProxyConfig{MaxAttempts: 2}))
+
+	ctx := serveTestRequest(engine, "/api/users?id=1")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "/api/users?id=1 0.0.0.0", string(ctx.Response.Body()))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// non-idempotent requests are not retried
+	atomic.StoreInt32(&calls, 0)
+	ctx = serveTestRequest(engine, "/api/users", withMethod("POST"))
+	assert.Equal(t, 503, ctx.Response.StatusCode())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	engine.Any("/down/*", ReverseProxy("http://127.0.0.1:1"))
+	ctx = serveTestRequest(engine, "/down/")
+	assert.Equal(t, 502, ctx.Response.StatusCode())
+}
+
+func TestReverseProxyHedge(t *testing.T) {
+	var calls int32
+	target, stop := runUpstream(t, func(ctx *fasthttp.RequestCtx) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			time.Sleep(time.Second)
+			ctx.SetBodyString("slow")
+			return
+		}
+		ctx.SetBodyString("fast")
+	})
+	defer stop()
+
+	engine := New()
+	engine.GET("/", ReverseProxy(target, ProxyConfig{MaxAttempts: 2, Hedge: true, HedgeDelay: 20 * time.Millisecond}))
+
+	start := time.Now()
+	ctx := serveTestRequest(engine, "/")
+	assert.Equal(t, "fast", string(ctx.Response.Body()))
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := retryBudget{ratio: 0.5, burst: 2, tokens: 2}
+	assert.True(t, b.withdraw())
+	assert.True(t, b.withdraw())
+	assert.False(t, b.withdraw())
+	b.deposit()
+	assert.False(t, b.withdraw())
+	b.deposit()
+	assert.True(t, b.withdraw())
+	for i := 0; i < 10; i++ {
+		b.deposit()
+	}
+	assert.Equal(t, 2.0, b.tokens)
+
+	w := latencyWindow{samples: make([]time.Duration, latencySamples)}
+	for i := 1; i <= 100; i++ {
+		w.add(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 96*time.Millisecond, w.percentile())
+}
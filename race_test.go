@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package tokay
+
+// raceEnabled is set when the tests run with the race detector, which adds allocations.
+const raceEnabled = true
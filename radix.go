@@ -17,17 +17,19 @@ type node struct {
 	order    int // the order at which the data was added. used to be pick the first one when matching multiple
 	minOrder int // minimum order among all the child nodes and this node
 
-	children  []*node // child static nodes, indexed by the first byte of each child key
+	children  []*node // child static nodes, indexed by the first byte of each child key, nil if there are none
 	pchildren []*node // child param nodes
 
-	regex  *regexp.Regexp // regular expression for a param node containing regular expression key
-	pindex int            // the parameter index, meaningful only for param node
-	pnames []string       // the parameter names collected from the root till this node
+	regex   *regexp.Regexp // regular expression for a param node containing regular expression key
+	matcher paramMatcher   // matcher of a param node with the typed key like "<id:int>"
+	pindex  int            // the parameter index, meaningful only for param node
+	pnames  []string       // the parameter names collected from the root till this node
 }
 
 // store is a radix tree that supports storing data with parametric keys and retrieving them back with concrete keys.
 // When retrieving a data item with a concrete key, the matching parameter names and values will be returned as well.
-// A parametric key is a string containing tokens in the format of "<name>", "<name:pattern>", or "<:pattern>".
+// A parametric key is a string containing tokens in the format of "<name>", "<name:pattern>", or "<:pattern>",
+// where the pattern is a regular expression or a parameter type (see paramTypes).
 // Each token represents a single parameter.
 type store struct {
 	root  *node // the root node of the radix tree
@@ -39,7 +41,6 @@ func newStore() *store {
 	return &store{
 		root: &node{
 			static:    true,
-			children:  make([]*node, 256),
 			pchildren: make([]*node, 0),
 			pindex:    -1,
 			pnames:    []string{},
@@ -94,7 +95,7 @@ func (n *node) add(key string, data interface{}, order int) int {
 		newKey := key[matched:]
 
 		// try adding to a static child
-		if child := n.children[newKey[0]]; child != nil {
+		if child := n.child(newKey[0]); child != nil {
 			if pn := child.add(newKey, data, order); pn >= 0 {
 				return pn
 			}
@@ -130,12 +131,28 @@ func (n *node) add(key string, data interface{}, order int) int {
 	n.key = key[0:matched]
 	n.data = nil
 	n.pchildren = make([]*node, 0)
-	n.children = make([]*node, 256)
-	n.children[n1.key[0]] = n1
+	n.children = nil
+	n.setChild(n1)
 
 	return n.add(key, data, order)
 }
 
+// child returns the static child node with the key starting with the byte, or nil.
+func (n *node) child(b byte) *node {
+	if n.children == nil {
+		return nil
+	}
+	return n.children[b]
+}
+
+// setChild sets the static child node, the children index is allocated on the first child.
+func (n *node) setChild(child *node) {
+	if n.children == nil {
+		n.children = make([]*node, 256)
+	}
+	n.children[child.key[0]] = child
+}
+
 // addChild creates static and param nodes to store the given data
 func (n *node) addChild(key string, data interface{}, order int) int {
 	// find the first occurrence of a param token
@@ -156,12 +173,11 @@ func (n *node) addChild(key string, data interface{}, order int) int {
 			static:    true,
 			key:       key,
 			minOrder:  order,
-			children:  make([]*node, 256),
 			pchildren: make([]*node, 0),
 			pindex:    n.pindex,
 			pnames:    n.pnames,
 		}
-		n.children[key[0]] = child
+		n.setChild(child)
 		if p1 > 0 {
 			// param token occurs after a static string
 			child.key = key[:p0]
@@ -179,7 +195,6 @@ func (n *node) addChild(key string, data interface{}, order int) int {
 		static:    false,
 		key:       key[p0 : p1+1],
 		minOrder:  order,
-		children:  make([]*node, 256),
 		pchildren: make([]*node, 0),
 		pindex:    n.pindex,
 		pnames:    n.pnames,
@@ -193,13 +208,16 @@ func (n *node) addChild(key string, data interface{}, order int) int {
 			break
 		}
 	}
-	if pattern != "" {
+	if matcher, ok := paramTypes[pattern]; ok {
+		// the typed param token
+		child.matcher = matcher
+	} else if pattern != "" {
 		// the param token contains a regular expression
 		child.regex = regexp.MustCompile("^" + pattern)
 	}
 	pnames := make([]string, len(n.pnames)+1)
 	copy(pnames, n.pnames)
-	pnames[len(n.pnames)] = pname
+	pnames[len(n.pnames)] = internName(pname)
 	child.pnames = pnames
 	child.pindex = len(pnames) - 1
 	n.pchildren = append(n.pchildren, child)
@@ -233,6 +251,14 @@ repeat:
 			}
 		}
 		key = key[nkl:]
+	} else if n.matcher != nil {
+		// param node with the typed key
+		i := n.matcher(key)
+		if i < 0 {
+			return
+		}
+		pvalues[n.pindex] = key[:i]
+		key = key[i:]
 	} else if n.regex != nil {
 		// param node with regular expression
 		if n.regex.String() == "^.*" {
@@ -262,7 +288,7 @@ repeat:
 
 	if len(key) > 0 {
 		// find a static child that can match the rest of the key
-		if child := n.children[key[0]]; child != nil {
+		if child := n.child(key[0]); child != nil {
 			if len(n.pchildren) == 0 {
 				// use goto to avoid recursion when no param children
 				n = child
@@ -0,0 +1,140 @@
+package tokay
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// maxByteRanges is the maximal number of the ranges served as multipart/byteranges,
+// the requests with more ranges get the whole file.
+const maxByteRanges = 16
+
+// byteRange is the inclusive range of the file bytes.
+type byteRange struct {
+	start, end int64
+}
+
+// parseByteRanges parses the Range header value, like "bytes=0-99,200-,-50", for the file of the size.
+// The unsatisfiable ranges are skipped. The false ok means the header is malformed.
+func parseByteRanges(header string, size int64) (ranges []byteRange, ok bool) {
+	if !strings.HasPrefix(header, "bytes=") {
+		return nil, false
+	}
+	for _, spec := range strings.Split(header[len("bytes="):], ",") {
+		spec = strings.TrimSpace(spec)
+		i := strings.IndexByte(spec, '-')
+		if i < 0 {
+			return nil, false
+		}
+		first, last := spec[:i], spec[i+1:]
+		var r byteRange
+		if first == "" {
+			// the suffix range: the last n bytes
+			n, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || n < 0 {
+				return nil, false
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			r = byteRange{size - n, size - 1}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, false
+			}
+			r = byteRange{start, size - 1}
+			if last != "" {
+				if r.end, err = strconv.ParseInt(last, 10, 64); err != nil || r.end < start {
+					return nil, false
+				}
+				if r.end >= size {
+					r.end = size - 1
+				}
+			}
+			if start >= size {
+				continue
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, true
+}
+
+// serveByteRanges serves the file parts requested by the multi-range Range header as the multipart/byteranges
+// response. It returns false for the requests without multiple ranges, which are served by fasthttp.
+func (c *Context) serveByteRanges(name string) bool {
+	header := string(c.Request.Header.Peek("Range"))
+	if !strings.Contains(header, ",") || c.Method() != "GET" && c.Method() != "HEAD" {
+		return false
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		f.Close()
+		return false
+	}
+	ranges, ok := parseByteRanges(header, fi.Size())
+	ifRange := string(c.Request.Header.Peek("If-Range"))
+	if !ok || len(ranges) > maxByteRanges || ifRange != "" && ifRange != fi.ModTime().UTC().Format(http.TimeFormat) {
+		// the whole file is served
+		f.Close()
+		c.Request.Header.Del("Range")
+		return false
+	}
+	if len(ranges) == 0 {
+		f.Close()
+		c.Response.Header.Set("Content-Range", "bytes */"+strconv.FormatInt(fi.Size(), 10))
+		c.AbortWithStatus(http.StatusRequestedRangeNotSatisfiable)
+		return true
+	}
+
+	var b [16]byte
+	rand.Read(b[:])
+	boundary := hex.EncodeToString(b[:])
+	contentType := c.engine.MIMEType(filepath.Ext(name))
+	c.SetStatusCode(http.StatusPartialContent)
+	c.SetContentType("multipart/byteranges; boundary=" + boundary)
+	c.Response.Header.Set("Accept-Ranges", "bytes")
+	c.Response.Header.Set("Last-Modified", fi.ModTime().UTC().Format(http.TimeFormat))
+	if c.Method() == "HEAD" {
+		f.Close()
+		return true
+	}
+	size := fi.Size()
+	c.SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer f.Close()
+		mw := multipart.NewWriter(w)
+		mw.SetBoundary(boundary)
+		for _, r := range ranges {
+			part, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":  {contentType},
+				"Content-Range": {fmt.Sprintf("bytes %d-%d/%d", r.start, r.end, size)},
+			})
+			if err != nil {
+				return
+			}
+			if _, err = io.Copy(part, io.NewSectionReader(f, r.start, r.end-r.start+1)); err != nil {
+				return
+			}
+		}
+		mw.Close()
+	})
+	return true
+}
@@ -0,0 +1,87 @@
+package tokay
+
+import (
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestParseByteRanges(t *testing.T) {
+	tests := []struct {
+		header string
+		ranges []byteRange
+		ok     bool
+	}{
+		{"bytes=0-4,10-", []byteRange{{0, 4}, {10, 25}}, true},
+		{"bytes=-5, 3-3", []byteRange{{21, 25}, {3, 3}}, true},
+		{"bytes=20-100,100-200", []byteRange{{20, 25}}, true},
+		{"bytes=-100", []byteRange{{0, 25}}, true},
+		{"bytes=30-40,50-", nil, true},
+		{"bytes=5-1", nil, false},
+		{"bytes=a-b", nil, false},
+		{"items=0-1", nil, false},
+	}
+	for _, test := range tests {
+		ranges, ok := parseByteRanges(test.header, 26)
+		assert.Equal(t, test.ok, ok, test.header)
+		assert.Equal(t, test.ranges, ranges, test.header)
+	}
+}
+
+func TestMultipartByteRanges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "doc.pdf")
+	assert.Nil(t, ioutil.WriteFile(name, []byte("abcdefghijklmnopqrstuvwxyz"), 0600))
+
+	engine := New()
+	engine.GET("/file", func(c *Context) {
+		c.File(name)
+	})
+	engine.StaticWithConfig("/static", dir, StaticConfig{AcceptByteRange: true})
+
+	request := func(uri, ranges string) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, uri, withHeader("Range", ranges))
+	}
+
+	for _, uri := range []string{"/file", "/static/doc.pdf"} {
+		ctx := request(uri, "bytes=0-2, -3")
+		assert.Equal(t, 206, ctx.Response.StatusCode(), uri)
+		mediaType, params, err := mime.ParseMediaType(string(ctx.Response.Header.ContentType()))
+		assert.Nil(t, err)
+		assert.Equal(t, "multipart/byteranges", mediaType)
+
+		reader := multipart.NewReader(strings.NewReader(string(ctx.Response.Body())), params["boundary"])
+		var parts []string
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			data, _ := ioutil.ReadAll(part)
+			assert.Equal(t, "application/pdf", part.Header.Get("Content-Type"))
+			parts = append(parts, part.Header.Get("Content-Range")+" "+string(data))
+		}
+		assert.Equal(t, []string{"bytes 0-2/26 abc", "bytes 23-25/26 xyz"}, parts, uri)
+	}
+
+	ctx := request("/file", "bytes=2-4")
+	assert.Equal(t, 206, ctx.Response.StatusCode())
+	assert.Equal(t, "cde", string(ctx.Response.Body()))
+
+	ctx = request("/file", "bytes=30-31,40-")
+	assert.Equal(t, 416, ctx.Response.StatusCode())
+	assert.Equal(t, "bytes */26", string(ctx.Response.Header.Peek("Content-Range")))
+
+	ctx = request("/file", "bytes=0-1,x")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, 26, len(ctx.Response.Body()))
+}
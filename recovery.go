@@ -0,0 +1,61 @@
+package tokay
+
+import (
+	"net/http"
+)
+
+// PanicHandlerFunc renders the response to the request whose handler panicked with the err value.
+type PanicHandlerFunc func(c *Context, err interface{})
+
+// PanicHandler sets the handler rendering the response after a panic recovered by Recovery, and
+// makes the engine recover the panics of all request handlers. The response is reset and has
+// the 500 status when the handler is called.
+//
+//	engine.PanicHandler(func(c *tokay.Context, err interface{}) {
+//		c.JSON(500, map[string]string{"error": "internal error"})
+//	})
+func (engine *Engine) PanicHandler(handler PanicHandlerFunc) {
+	engine.panicHandler = handler
+}
+
+// Recovery returns the middleware recovering the panics of the next handlers: the panic is logged
+// with the stack trace, sent to the panic reporters (see Engine.OnPanic) and the request gets
+// 500 Internal Server Error or the response of Engine.PanicHandler.
+//
+//	engine.Use(tokay.Recovery())
+func Recovery() Handler {
+	return func(c *Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				c.engine.recoverPanic(c, err)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// nextRecovered calls the request handlers recovering their panics (see PanicHandler).
+func (engine *Engine) nextRecovered(c *Context) {
+	defer func() {
+		if err := recover(); err != nil {
+			engine.recoverPanic(c, err)
+		}
+	}()
+	c.Next()
+}
+
+// recoverPanic logs and reports the recovered panic and writes the 500 response.
+func (engine *Engine) recoverPanic(c *Context, err interface{}) {
+	engine.logger.Error("panic recovered", "error", err, "method", c.Method(), "uri", c.RequestURI(), "stack", stack())
+	if len(engine.panicReporters) != 0 {
+		engine.reportPanic(c, err)
+	}
+	c.Response.ResetBody()
+	c.Response.SetStatusCode(http.StatusInternalServerError)
+	if engine.panicHandler != nil {
+		engine.panicHandler(c, err)
+	} else {
+		c.Error(http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+	c.Abort()
+}
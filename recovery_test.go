@@ -0,0 +1,50 @@
+package tokay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+	logger := &eventLogger{}
+	engine := New(&Config{Logger: logger})
+	var reported interface{}
+	engine.OnPanic(PanicReporterFunc(func(r *PanicReport) {
+		reported = r.Error
+	}))
+	api := engine.Group("/api", Recovery())
+	api.GET("/panic", func(c *Context) {
+		c.Response.Header.Set("X-Partial", "1")
+		c.String(200, "partial")
+		panic("boom")
+	})
+
+	ctx := serveTestRequest(engine, "/api/panic")
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+	assert.Equal(t, "Internal Server Error", string(ctx.Response.Body()))
+	assert.Equal(t, "boom", reported)
+	if assert.Len(t, logger.events, 1) {
+		assert.True(t, strings.HasPrefix(logger.events[0], "panic recovered error boom method GET uri /api/panic stack goroutine"))
+	}
+}
+
+func TestEnginePanicHandler(t *testing.T) {
+	engine := New(&Config{Logger: &testLogger{}})
+	engine.PanicHandler(func(c *Context, err interface{}) {
+		c.JSON(c.Response.StatusCode(), map[string]interface{}{"error": err})
+	})
+	var post bool
+	engine.Post(func(c *Context) {
+		post = true
+	})
+	engine.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+
+	ctx := serveTestRequest(engine, "/panic")
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+	assert.Equal(t, `{"error":"boom"}`, string(ctx.Response.Body()))
+	assert.True(t, post)
+}
@@ -0,0 +1,153 @@
+package tokay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// redirectRule is a redirect from the path (or the path prefix for wildcard rules) to the target.
+	redirectRule struct {
+		from, to string
+		code     int
+		wildcard bool // from and to end with "*": the rest of the path is appended to the target
+	}
+
+	// redirectTable is the immutable set of redirect rules, replaced as a whole on reload.
+	redirectTable struct {
+		exact     map[string]redirectRule
+		wildcards []redirectRule // sorted by prefix length, longest first
+	}
+)
+
+// Redirects installs the redirect map evaluated before routing, replacing the previously installed one.
+// Keys are request paths, values are target paths or URLs, optionally prefixed with the status code
+// (301 Moved Permanently by default). A path ending with "*" matches any path with the prefix;
+// if the target ends with "*" too, the rest of the path is appended to it.
+// The query string is kept. Redirects is safe to call while the engine is serving requests.
+//
+//	engine.Redirects(map[string]string{
+//		"/about-us": "/about",
+//		"/promo":    "302 https://shop.example.com/sale",
+//		"/blog/*":   "/news/*",
+//	})
+func (engine *Engine) Redirects(redirects map[string]string) error {
+	table, err := newRedirectTable(redirects)
+	if err != nil {
+		return err
+	}
+	engine.redirects.Store(table)
+	return nil
+}
+
+// newRedirectTable parses the redirect map (see Redirects). It returns nil for the empty map.
+func newRedirectTable(redirects map[string]string) (*redirectTable, error) {
+	table := &redirectTable{exact: make(map[string]redirectRule)}
+	for from, to := range redirects {
+		code := http.StatusMovedPermanently
+		if fields := strings.Fields(to); len(fields) == 2 {
+			c, err := strconv.Atoi(fields[0])
+			if err != nil || c < 300 || c > 399 {
+				return nil, fmt.Errorf("invalid redirect status code for %s: %s", from, fields[0])
+			}
+			code, to = c, fields[1]
+		}
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("invalid redirect %q -> %q", from, to)
+		}
+		rule := redirectRule{from: from, to: to, code: code}
+		if strings.HasSuffix(from, "*") {
+			rule.from = from[:len(from)-1]
+			rule.wildcard = true
+			table.wildcards = append(table.wildcards, rule)
+		} else {
+			table.exact[from] = rule
+		}
+	}
+	sort.Slice(table.wildcards, func(i, j int) bool {
+		return len(table.wildcards[i].from) > len(table.wildcards[j].from)
+	})
+	if len(table.exact)+len(table.wildcards) == 0 {
+		return nil, nil
+	}
+	return table, nil
+}
+
+// LoadRedirects reads the redirect map from r and installs it (see Redirects). Every line contains
+// the path, the target and the optional status code separated by spaces. Empty lines and lines
+// starting with "#" are ignored.
+//
+//	# legacy URLs
+//	/about-us   /about
+//	/promo      https://shop.example.com/sale  302
+//	/blog/*     /news/*
+func (engine *Engine) LoadRedirects(r io.Reader) error {
+	redirects := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 2:
+			redirects[fields[0]] = fields[1]
+		case 3:
+			redirects[fields[0]] = fields[2] + " " + fields[1]
+		default:
+			return fmt.Errorf("invalid redirect at line %d: %s", n, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return engine.Redirects(redirects)
+}
+
+// findRedirect returns the handler of the installed redirect for the path, or nil.
+func (engine *Engine) findRedirect(path string) Handler {
+	table, _ := engine.redirects.Load().(*redirectTable)
+	return table.find(path)
+}
+
+// find returns the redirect handler for the path, or nil.
+func (table *redirectTable) find(path string) Handler {
+	if table == nil {
+		return nil
+	}
+	rule, ok := table.exact[path]
+	target := rule.to
+	if !ok {
+		for _, rule = range table.wildcards {
+			if strings.HasPrefix(path, rule.from) {
+				ok, target = true, rule.to
+				if strings.HasSuffix(target, "*") {
+					target = target[:len(target)-1] + path[len(rule.from):]
+				}
+				break
+			}
+		}
+		if !ok {
+			return nil
+		}
+	}
+	code := rule.code
+	return func(c *Context) {
+		uri := c.appURL(target)
+		if query := c.URI().QueryString(); len(query) != 0 {
+			if strings.IndexByte(uri, '?') >= 0 {
+				uri += "&" + string(query)
+			} else {
+				uri += "?" + string(query)
+			}
+		}
+		c.Redirect(code, uri)
+		c.skipHandlers()
+	}
+}
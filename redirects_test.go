@@ -0,0 +1,45 @@
+package tokay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedirects(t *testing.T) {
+	engine := New()
+	engine.GET("/about", func(c *Context) { c.String(200, "about") })
+	assert.Nil(t, engine.Redirects(map[string]string{
+		"/about-us":   "/about",
+		"/promo":      "302 https://shop.example.com/sale?src=promo",
+		"/blog/*":     "/news/*",
+		"/blog/old/*": "/archive",
+	}))
+
+	tests := []struct {
+		uri      string
+		code     int
+		location string
+	}{
+		{"/about-us?a=1", 301, "http://example.com/about?a=1"},
+		{"/promo?b=2", 302, "https://shop.example.com/sale?src=promo&b=2"},
+		{"/blog/2020/post", 301, "http://example.com/news/2020/post"},
+		{"/blog/old/post", 301, "http://example.com/archive"},
+		{"/about", 200, ""},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, "http://example.com"+test.uri)
+		assert.Equal(t, test.code, ctx.Response.StatusCode(), test.uri)
+		assert.Equal(t, test.location, string(ctx.Response.Header.Peek("Location")), test.uri)
+	}
+
+	assert.Nil(t, engine.LoadRedirects(strings.NewReader("# comment\n\n/a  /b\n/c /d 307\n")))
+	assert.Equal(t, 404, serveTestRequest(engine, "/about-us").Response.StatusCode())
+	assert.Equal(t, 307, serveTestRequest(engine, "/c").Response.StatusCode())
+	assert.NotNil(t, engine.LoadRedirects(strings.NewReader("/a\n")))
+	assert.NotNil(t, engine.Redirects(map[string]string{"/a": "200 /b"}))
+
+	assert.Nil(t, engine.Redirects(nil))
+	assert.Equal(t, 404, serveTestRequest(engine, "/c").Response.StatusCode())
+}
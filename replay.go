@@ -0,0 +1,200 @@
+package tokay
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/night-codes/go-json"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// RecorderConfig is a struct for specifying Recorder middleware options.
+	RecorderConfig struct {
+		// Writer receives the records as JSON lines (see ReadRecords), like a RotatingFile.
+		Writer io.Writer
+		// Paths are the recorded request path patterns (see path.Match). Default is all paths.
+		Paths []string
+		// MinStatus is the minimal recorded response status. Default is 500.
+		MinStatus int
+		// Filter additionally selects the recorded requests after they are handled.
+		Filter func(c *Context) bool
+		// RedactHeaders are the request and response headers replaced with "REDACTED".
+		// Default is Authorization, Cookie and Set-Cookie; use the empty slice to keep all headers.
+		RedactHeaders []string
+		// MaxBodySize is the maximum size of the recorded bodies, the longer ones are truncated. Default is 1 MB.
+		MaxBodySize int
+	}
+
+	// Record is the recorded request and response (see Recorder and Engine.Replay).
+	Record struct {
+		Time       time.Time      `json:"time"`
+		Duration   time.Duration  `json:"duration"`
+		RemoteAddr string         `json:"remote_addr"`
+		Request    RecordRequest  `json:"request"`
+		Response   RecordResponse `json:"response"`
+	}
+
+	// RecordRequest is the recorded request.
+	RecordRequest struct {
+		Method  string      `json:"method"`
+		URI     string      `json:"uri"`
+		Host    string      `json:"host"`
+		Headers [][2]string `json:"headers"`
+		Body    []byte      `json:"body,omitempty"`
+	}
+
+	// RecordResponse is the recorded response.
+	RecordResponse struct {
+		Status  int         `json:"status"`
+		Headers [][2]string `json:"headers"`
+		Body    []byte      `json:"body,omitempty"`
+	}
+)
+
+// Recorder returns a development middleware writing the matching request/response pairs (by default
+// the 5xx responses) to the writer, so the failing requests can be reproduced with Engine.Replay.
+//
+//	file, _ := tokay.NewRotatingFile(tokay.RotatingFileConfig{Filename: "records.jsonl"})
+//	if engine.Debug {
+//		engine.Use(tokay.Recorder(tokay.RecorderConfig{Writer: file, Paths: []string{"/api/*"}}))
+//	}
+func Recorder(config RecorderConfig) Handler {
+	if config.Writer == nil {
+		panic("Recorder: Writer is required")
+	}
+	if config.MinStatus == 0 {
+		config.MinStatus = http.StatusInternalServerError
+	}
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	var mu sync.Mutex
+
+	return func(c *Context) {
+		if len(config.Paths) != 0 && !matchPaths(config.Paths, c.Path()) {
+			return
+		}
+		start := time.Now()
+		request := RecordRequest{
+			Method:  c.Method(),
+			URI:     c.RequestURI(),
+			Host:    c.Host(),
+			Headers: recordHeaders(&c.Request.Header, config.RedactHeaders),
+			Body:    recordBody(c.Request.Body(), config.MaxBodySize),
+		}
+		c.Next()
+		if c.Response.StatusCode() < config.MinStatus || config.Filter != nil && !config.Filter(c) {
+			return
+		}
+		record := Record{
+			Time:       start,
+			Duration:   time.Since(start),
+			RemoteAddr: c.RemoteAddr().String(),
+			Request:    request,
+			Response: RecordResponse{
+				Status:  c.Response.StatusCode(),
+				Headers: recordHeaders(&c.Response.Header, config.RedactHeaders),
+			},
+		}
+		if !c.Response.IsBodyStream() {
+			record.Response.Body = recordBody(c.Response.Body(), config.MaxBodySize)
+		}
+		data, err := json.Marshal(record)
+		if err != nil {
+			c.Logger().Warn("cannot record request", "error", err)
+			return
+		}
+		mu.Lock()
+		config.Writer.Write(append(data, '\n'))
+		mu.Unlock()
+	}
+}
+
+// matchPaths reports whether the path matches one of the patterns.
+func matchPaths(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// recordHeaders returns the headers with the redacted values.
+func recordHeaders(h interface {
+	VisitAll(func(key, value []byte))
+}, redact []string) [][2]string {
+	var headers [][2]string
+	h.VisitAll(func(key, value []byte) {
+		v := string(value)
+		for _, name := range redact {
+			if strings.EqualFold(name, string(key)) {
+				v = "REDACTED"
+			}
+		}
+		headers = append(headers, [2]string{string(key), v})
+	})
+	return headers
+}
+
+// recordBody returns the copy of the body truncated to the max size.
+func recordBody(body []byte, max int) []byte {
+	if len(body) > max {
+		body = body[:max]
+	}
+	return append([]byte(nil), body...)
+}
+
+// ReadRecords reads the records written by the Recorder middleware.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	var records []Record
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) != 0 {
+			var record Record
+			if err := json.Unmarshal(line, &record); err != nil {
+				return records, err
+			}
+			records = append(records, record)
+		}
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+	}
+}
+
+// Replay dispatches the recorded request through HandleRequest and returns the new response.
+//
+//	records, _ := tokay.ReadRecords(file)
+//	resp := engine.Replay(records[0])
+//	fmt.Println(resp.StatusCode(), string(resp.Body()))
+func (engine *Engine) Replay(record Record) *fasthttp.Response {
+	ctx := &fasthttp.RequestCtx{}
+	for _, h := range record.Request.Headers {
+		ctx.Request.Header.Set(h[0], h[1])
+	}
+	ctx.Request.Header.SetMethod(record.Request.Method)
+	ctx.Request.SetRequestURI(record.Request.URI)
+	if record.Request.Host != "" {
+		ctx.Request.Header.SetHost(record.Request.Host)
+	}
+	ctx.Request.SetBody(record.Request.Body)
+	engine.HandleRequest(ctx)
+
+	resp := &fasthttp.Response{}
+	ctx.Response.CopyTo(resp)
+	return resp
+}
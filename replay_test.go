@@ -0,0 +1,59 @@
+package tokay
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderReplay(t *testing.T) {
+	var buf bytes.Buffer
+	engine := New()
+	engine.SetLogger(&testLogger{})
+	engine.Use(Recorder(RecorderConfig{Writer: &buf, Paths: []string{"/api/*"}}))
+	fail := true
+	engine.POST("/api/orders", func(c *Context) {
+		if fail {
+			c.AbortWithError(500, errors.New("order failed: "+string(c.PostBody())))
+			return
+		}
+		c.String(201, "created %s", c.GetHeader("Authorization"))
+	})
+	engine.GET("/api/ok", func(c *Context) {
+		c.String(200, "ok")
+	})
+	engine.GET("/other", func(c *Context) {
+		c.AbortWithStatus(500)
+	})
+
+	post := func(uri string) {
+		serveTestRequest(engine, uri, withMethod("POST"), withHeader("Authorization", "Bearer secret"),
+			withHeader("X-Tenant", "acme"), withBody("", `{"qty":2}`))
+	}
+	post("/api/orders?debug=1")
+	serveTestRequest(engine, "/api/ok")
+	serveTestRequest(engine, "/other")
+
+	records, err := ReadRecords(&buf)
+	assert.Nil(t, err)
+	if !assert.Len(t, records, 1) {
+		return
+	}
+	record := records[0]
+	assert.Equal(t, "POST", record.Request.Method)
+	assert.Equal(t, "/api/orders?debug=1", record.Request.URI)
+	assert.Equal(t, `{"qty":2}`, string(record.Request.Body))
+	assert.Contains(t, record.Request.Headers, [2]string{"Authorization", "REDACTED"})
+	assert.Contains(t, record.Request.Headers, [2]string{"X-Tenant", "acme"})
+	assert.Equal(t, 500, record.Response.Status)
+	assert.Contains(t, string(record.Response.Body), `order failed: {"qty":2}`)
+
+	resp := engine.Replay(record)
+	assert.Equal(t, 500, resp.StatusCode())
+	fail = false
+	resp = engine.Replay(record)
+	assert.Equal(t, 201, resp.StatusCode())
+	assert.Equal(t, "created REDACTED", string(resp.Body()))
+}
@@ -0,0 +1,129 @@
+package tokay
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The request log formats (see LoggerConfig).
+const (
+	LogFormatKeyValue = "kv"
+	LogFormatJSON     = "json"
+)
+
+// LoggerConfig is a struct for specifying Logger middleware options.
+type LoggerConfig struct {
+	// Format is LogFormatKeyValue (default) or LogFormatJSON.
+	Format string
+	// Output is the writer of the log lines. Default is os.Stdout.
+	Output io.Writer
+	// Callback receives the log entries instead of Output, for example to pass them to a FieldLogger.
+	Callback func(entry *LogEntry)
+	// SkipPaths are the request paths which are not logged, the paths ending with "*" are the prefixes.
+	SkipPaths []string
+}
+
+// LogEntry describes a handled request (see Logger).
+type LogEntry struct {
+	Time      time.Time
+	Latency   time.Duration
+	Status    int
+	Method    string
+	Path      string // the request path before the prefixes were stripped (see Context.OriginalPath)
+	ClientIP  string
+	Bytes     int // the response body size, -1 for the streamed bodies of unknown size
+	RequestID string
+}
+
+// Keyvals returns the entry fields as the alternating keys and values (see FieldLogger).
+func (e *LogEntry) Keyvals() []interface{} {
+	return []interface{}{
+		"time", e.Time.Format(time.RFC3339),
+		"status", e.Status,
+		"latency", e.Latency.String(),
+		"method", e.Method,
+		"path", e.Path,
+		"client_ip", e.ClientIP,
+		"bytes", e.Bytes,
+		"request_id", e.RequestID,
+	}
+}
+
+// Logger returns the middleware writing the structured log line of every request with the latency,
+// status, method, path, client IP, response size and request ID.
+//
+//	engine.Use(tokay.Logger(tokay.LoggerConfig{Format: tokay.LogFormatJSON, SkipPaths: []string{"/health"}}))
+func Logger(config LoggerConfig) Handler {
+	if config.Output == nil {
+		config.Output = os.Stdout
+	}
+	var mu sync.Mutex
+	write := config.Callback
+	if write == nil {
+		write = func(entry *LogEntry) {
+			line, err := formatLogEntry(entry, config.Format)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			config.Output.Write(line)
+			mu.Unlock()
+		}
+	}
+	return func(c *Context) {
+		path := c.OriginalPath()
+		if skipLogPath(path, config.SkipPaths) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		c.Next()
+		entry := &LogEntry{
+			Time:      start,
+			Latency:   time.Since(start),
+			Status:    c.Response.StatusCode(),
+			Method:    c.Method(),
+			Path:      path,
+			ClientIP:  c.ClientIP(),
+			Bytes:     -1,
+			RequestID: c.RequestID(),
+		}
+		if !c.Response.IsBodyStream() {
+			entry.Bytes = len(c.Response.Body())
+		} else if size := c.Response.Header.ContentLength(); size >= 0 {
+			entry.Bytes = size
+		}
+		write(entry)
+	}
+}
+
+// formatLogEntry returns the log line of the entry in the format.
+func formatLogEntry(entry *LogEntry, format string) ([]byte, error) {
+	keyvals := entry.Keyvals()
+	if format == LogFormatJSON {
+		obj := make(orderedObject, 0, len(keyvals)/2)
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			obj = append(obj, orderedField{keyvals[i].(string), keyvals[i+1]})
+		}
+		line, err := obj.MarshalJSON()
+		return append(line, '\n'), err
+	}
+	var buf bytes.Buffer
+	appendKeyvals(&buf, keyvals)
+	buf.WriteByte('\n')
+	return buf.Bytes()[1:], nil
+}
+
+// skipLogPath reports whether the path is in the skip list, the entries ending with "*" are the prefixes.
+func skipLogPath(path string, skip []string) bool {
+	for _, p := range skip {
+		if p == path || strings.HasSuffix(p, "*") && strings.HasPrefix(path, p[:len(p)-1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package tokay
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	engine := New()
+	engine.Use(Logger(LoggerConfig{Output: &buf, SkipPaths: []string{"/health", "/static/*"}}))
+	engine.GET("/users/<id>", func(c *Context) {
+		c.String(200, "user "+c.Param("id"))
+	})
+	engine.GET("/health", func(c *Context) {})
+	engine.GET("/static/*", func(c *Context) {})
+
+	serveTestRequest(engine, "/users/1", withHeader(RequestIDHeader, "req 1"), withHeader("X-Forwarded-For", "10.0.0.1"))
+	serveTestRequest(engine, "/health")
+	serveTestRequest(engine, "/static/app.js")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 1) {
+		assert.True(t, strings.HasPrefix(lines[0], "time="))
+		assert.Contains(t, lines[0], ` status=200 latency=`)
+		assert.True(t, strings.HasSuffix(lines[0], ` method=GET path=/users/1 client_ip=10.0.0.1 bytes=6 request_id="req 1"`), lines[0])
+	}
+}
+
+func TestRequestLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	engine := New()
+	engine.Use(Logger(LoggerConfig{Format: LogFormatJSON, Output: &buf}))
+	engine.GET("/", func(c *Context) {
+		c.String(201, "ok")
+	})
+	serveTestRequest(engine, "/")
+	line := buf.String()
+	assert.True(t, strings.HasPrefix(line, `{"time":"`), line)
+	assert.Contains(t, line, `"status":201,"latency":"`)
+	assert.Contains(t, line, `"method":"GET","path":"/","client_ip":"0.0.0.0","bytes":2,"request_id":"`)
+	assert.True(t, strings.HasSuffix(line, "}\n"))
+
+	var entries []*LogEntry
+	engine = New()
+	engine.Use(Logger(LoggerConfig{Callback: func(entry *LogEntry) {
+		entries = append(entries, entry)
+	}}))
+	serveTestRequest(engine, "/missing")
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, 404, entries[0].Status)
+		assert.Equal(t, "/missing", entries[0].Path)
+	}
+}
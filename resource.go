@@ -0,0 +1,93 @@
+package tokay
+
+import (
+	"strings"
+)
+
+type (
+	// ResourceHandlers are the handlers of the RESTful resource actions (see RouterGroup.Resource).
+	// The routes of the nil handlers are not registered.
+	ResourceHandlers struct {
+		Index, New, Create, Show, Edit, Update, Delete Handler
+		// Middleware are called before the handlers of all the resource routes, including the nested resources.
+		Middleware []Handler
+	}
+
+	// Resource is a group of the RESTful resource routes.
+	Resource struct {
+		// Routes are the registered routes by the action names ("Index", "Show" etc.).
+		Routes map[string]*Route
+		name   string       // the route names prefix, like "users.posts"
+		param  string       // the member ID parameter name of the nested resources, like "user_id"
+		group  *RouterGroup // the resource group
+	}
+)
+
+// handler returns the handler of the action.
+func (h *ResourceHandlers) handler(action string) Handler {
+	switch action {
+	case "Index":
+		return h.Index
+	case "New":
+		return h.New
+	case "Create":
+		return h.Create
+	case "Show":
+		return h.Show
+	case "Edit":
+		return h.Edit
+	case "Update":
+		return h.Update
+	case "Delete":
+		return h.Delete
+	}
+	return nil
+}
+
+// Resource registers the standard RESTful routes of the resource (see Mount for the list). The member
+// ID parameter is "id", the routes are named like "users.show" (or "users.posts.show" for the nested resources).
+//
+//	users := engine.Resource("/users", tokay.ResourceHandlers{Index: listUsers, Show: showUser})
+//	users.Resource("/posts", tokay.ResourceHandlers{Index: listPosts}) // GET /users/<user_id>/posts
+//	users.Routes["Show"].SetMeta("cache", true)
+func (r *RouterGroup) Resource(path string, handlers ResourceHandlers) *Resource {
+	return r.resource("", path, handlers)
+}
+
+// Resource registers the nested resource at the member path of the resource, like "/users/<user_id>/posts".
+func (res *Resource) Resource(path string, handlers ResourceHandlers) *Resource {
+	return res.group.Group("/<"+res.param+">").resource(res.name+".", path, handlers)
+}
+
+func (r *RouterGroup) resource(prefix, path string, handlers ResourceHandlers) *Resource {
+	name := strings.Trim(path, "/")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	groupHandlers := append(r.handlers[:len(r.handlers):len(r.handlers)], handlers.Middleware...)
+	res := &Resource{
+		Routes: make(map[string]*Route),
+		name:   prefix + name,
+		param:  singular(name) + "_id",
+		group:  r.Group(path, groupHandlers...),
+	}
+	for _, action := range resourceActions {
+		if handler := handlers.handler(action.name); handler != nil {
+			res.Routes[action.name] = res.group.To(action.methods, action.path, handler).Name(res.name + "." + strings.ToLower(action.name))
+		}
+	}
+	return res
+}
+
+// singular returns the singular form of the plural English noun: "users" -> "user", "categories" -> "category".
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return name[:len(name)-1]
+	}
+	return name
+}
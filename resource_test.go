@@ -0,0 +1,70 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResource(t *testing.T) {
+	engine := New()
+	action := func(name string) Handler {
+		return func(c *Context) {
+			c.String(200, name+" "+c.Param("user_id")+" "+c.Param("id"))
+		}
+	}
+	users := engine.Group("/api").Resource("/users", ResourceHandlers{
+		Index:  action("users.index"),
+		Create: action("users.create"),
+		Show:   action("users.show"),
+		Update: action("users.update"),
+		Delete: action("users.delete"),
+		Middleware: []Handler{func(c *Context) {
+			c.Header("X-Resource", "users")
+		}},
+	})
+	posts := users.Resource("/posts", ResourceHandlers{
+		Index: action("posts.index"),
+		New:   action("posts.new"),
+		Show:  action("posts.show"),
+		Edit:  action("posts.edit"),
+	})
+
+	assert.Len(t, users.Routes, 5)
+	assert.Nil(t, users.Routes["Edit"])
+	assert.Equal(t, "/api/users/<id>", users.Routes["Show"].Path())
+	assert.Equal(t, "/api/users/<user_id>/posts/<id>/edit", posts.Routes["Edit"].Path())
+	assert.Equal(t, "/api/users/5/posts/7", engine.routes["users.posts.show"].URL("user_id", 5, "id", 7))
+
+	tests := []struct {
+		method, uri, body string
+	}{
+		{"GET", "/api/users", "users.index  "},
+		{"POST", "/api/users", "users.create  "},
+		{"GET", "/api/users/5", "users.show  5"},
+		{"PUT", "/api/users/5", "users.update  5"},
+		{"PATCH", "/api/users/5", "users.update  5"},
+		{"DELETE", "/api/users/5", "users.delete  5"},
+		{"GET", "/api/users/5/posts", "posts.index 5 "},
+		{"GET", "/api/users/5/posts/new", "posts.new 5 "},
+		{"GET", "/api/users/5/posts/7", "posts.show 5 7"},
+		{"GET", "/api/users/5/posts/7/edit", "posts.edit 5 7"},
+	}
+	for _, test := range tests {
+		ctx := serveTestRequest(engine, test.uri, withMethod(test.method))
+		assert.Equal(t, test.body, string(ctx.Response.Body()), test.method+" "+test.uri)
+		assert.Equal(t, "users", string(ctx.Response.Header.Peek("X-Resource")), test.method+" "+test.uri)
+	}
+
+	ctx := serveTestRequest(engine, "/api/users/5/posts/7/unknown")
+	assert.Equal(t, 404, ctx.Response.StatusCode())
+}
+
+func TestSingular(t *testing.T) {
+	for plural, expected := range map[string]string{
+		"users": "user", "categories": "category", "addresses": "address",
+		"boxes": "box", "branches": "branch", "data": "data",
+	} {
+		assert.Equal(t, expected, singular(plural), plural)
+	}
+}
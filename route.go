@@ -4,17 +4,50 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 )
 
 // Route represents a URL path pattern that can be used to match requested URLs.
 type Route struct {
-	group      *RouterGroup
-	name, path string
-	template   string
+	group        *RouterGroup
+	name, path   string
+	template     string    // the URL template, built on the first URL call for the routes added by AddRoutes
+	templateOnce sync.Once // builds the deferred template
+	meta         map[string]interface{}
+	methods      []string
+	chains       map[string][]Handler // combined handlers by methods
+	guards       []func(c *Context) bool
+	inserted     []insertedHandler // the handlers inserted before the last route handler (see CacheFor)
+	middleware   []Handler         // the route middlewares (see Use)
+	// registered are the group and the route handlers by methods as they were registered
+	registered map[string]registeredHandlers
+	// autoHEAD is set when the HEAD handlers are the GET ones (see Engine.HandleHEAD)
+	autoHEAD bool
+	// summary and description document the route (see Engine.Routes)
+	summary, description string
+}
+
+// RouteInfo describes a registered route (see Engine.Routes).
+type RouteInfo struct {
+	Name        string
+	Path        string
+	Methods     []string
+	Summary     string
+	Description string
+	Meta        map[string]interface{}
+	// Handlers are the names of the handler functions of the full handlers chains by the methods
+	Handlers map[string][]string
 }
 
 // newRoute creates a new Route with the given route path and route group.
 func newRoute(path string, group *RouterGroup) *Route {
+	route := registerRoute(path, group)
+	route.template = buildURLTemplate(route.path)
+	return route
+}
+
+// registerRoute creates a new Route without the URL template and registers it in the engine.
+func registerRoute(path string, group *RouterGroup) *Route {
 	path = group.path + path
 	name := path
 
@@ -24,16 +57,28 @@ func newRoute(path string, group *RouterGroup) *Route {
 	}
 
 	route := &Route{
-		group:    group,
-		name:     name,
-		path:     path,
-		template: buildURLTemplate(path),
+		group: group,
+		name:  name,
+		path:  path,
 	}
 	group.engine.routes[name] = route
+	group.engine.routeList = append(group.engine.routeList, route)
 
 	return route
 }
 
+// routeHandlers is the route store data item: the handlers registered for the route with a HTTP method.
+type routeHandlers struct {
+	route    *Route
+	handlers []Handler
+	next     *routeHandlers // the next candidate with the same method and path pattern (see Route.When)
+}
+
+// Path returns the route path pattern (including the group path).
+func (r *Route) Path() string {
+	return r.path
+}
+
 // Name sets the name of the route.
 // This method will update the registration of the route in the engine as well.
 func (r *Route) Name(name string) *Route {
@@ -42,6 +87,76 @@ func (r *Route) Name(name string) *Route {
 	return r
 }
 
+// SetMeta stores the route metadata item which can be used by middlewares (see Context.Route()).
+// Metadata should be set while registering the routes, before the engine starts serving requests.
+func (r *Route) SetMeta(key string, value interface{}) *Route {
+	if r.meta == nil {
+		r.meta = make(map[string]interface{})
+	}
+	r.meta[key] = value
+	return r
+}
+
+// Meta returns the route metadata item stored by SetMeta, or nil.
+func (r *Route) Meta(key string) interface{} {
+	return r.meta[key]
+}
+
+// Summary sets the short summary of the route used by the route documentation (see Engine.Routes).
+func (r *Route) Summary(s string) *Route {
+	r.summary = s
+	return r
+}
+
+// Description sets the detailed description of the route used by the route documentation (see Engine.Routes).
+func (r *Route) Description(s string) *Route {
+	r.description = s
+	return r
+}
+
+// Info returns the route description.
+func (r *Route) Info() RouteInfo {
+	info := RouteInfo{
+		Name:        r.name,
+		Path:        r.path,
+		Methods:     append([]string(nil), r.methods...),
+		Summary:     r.summary,
+		Description: r.description,
+		Meta:        make(map[string]interface{}, len(r.meta)),
+		Handlers:    make(map[string][]string, len(r.methods)),
+	}
+	for key, value := range r.meta {
+		info.Meta[key] = value
+	}
+	for _, method := range r.methods {
+		info.Handlers[method] = handlerNames(r.chains[method])
+	}
+	return info
+}
+
+// When adds the guard evaluated when the request matches the route path. If any guard returns false,
+// the next route registered with the same method and path pattern is tried, so the routes with guards
+// should be registered before the fallback one. Guards may use the route parameters.
+//
+//	engine.GET("/checkout", checkoutB).When(func(c *tokay.Context) bool { return c.Cookie("ab") == "b" })
+//	engine.GET("/checkout", checkoutA)
+//	engine.POST("/import", importCSV).When(func(c *tokay.Context) bool { return c.ContentType() == "text/csv" })
+//	engine.POST("/import", importJSON)
+func (r *Route) When(guard func(c *Context) bool) *Route {
+	r.guards = append(r.guards, guard)
+	return r
+}
+
+// allows reports whether all the route guards pass.
+func (r *Route) allows(c *Context) bool {
+	for _, guard := range r.guards {
+		if !guard(c) {
+			return false
+		}
+	}
+	return true
+}
+
 // GET adds the route to the engine using the GET HTTP method.
 func (r *Route) GET(handlers ...Handler) *Route {
 	return r.add("GET", handlers)
@@ -101,7 +216,12 @@ func (r *Route) To(methods string, handlers ...Handler) *Route {
 // If a parameter in the route is not provided a value, the parameter token will remain in the resulting URL.
 // The method will perform URL encoding for all given parameter values.
 func (r *Route) URL(pairs ...interface{}) (s string) {
-	s = r.template
+	r.templateOnce.Do(func() {
+		if r.template == "" {
+			r.template = buildURLTemplate(r.path)
+		}
+	})
+	s = r.group.engine.basePath + r.template
 	for i := 0; i < len(pairs); i++ {
 		name := fmt.Sprintf("<%v>", pairs[i])
 		value := ""
@@ -116,11 +236,109 @@ func (r *Route) URL(pairs ...interface{}) (s string) {
 // add registers the route, the specified HTTP method and the handlers to the engine.
 // The handlers will be combined with the handlers of the route group.
 func (r *Route) add(method string, handlers []Handler) *Route {
-	hh := combineHandlers(r.group.handlers, handlers)
-	r.group.engine.add(method, r.path, hh)
+	if r.registered == nil {
+		r.registered = make(map[string]registeredHandlers)
+		r.chains = make(map[string][]Handler)
+	}
+	if method == "HEAD" && r.autoHEAD {
+		// the own HEAD handlers replace the GET ones
+		r.autoHEAD = false
+		r.registered[method] = registeredHandlers{group: r.group.handlers, handlers: handlers}
+		r.rebuild()
+		return r
+	}
+	r.registered[method] = registeredHandlers{group: r.group.handlers, handlers: handlers}
+	hh := r.chain(method)
+	r.group.engine.add(method, r, hh)
+	if _, ok := r.chains[method]; !ok {
+		r.methods = append(r.methods, method)
+	}
+	r.chains[method] = hh
+	if method == "GET" && r.group.engine.HandleHEAD {
+		r.addAutoHEAD(handlers)
+	}
+	return r
+}
+
+// registeredHandlers are the handlers of the route method.
+type registeredHandlers struct {
+	group    []Handler // the group handlers at the registration time
+	handlers []Handler
+}
+
+// chain combines the group handlers, the route middlewares, the route handlers
+// and the inserted handlers of the method.
+func (r *Route) chain(method string) []Handler {
+	reg := r.registered[method]
+	hh := combineHandlers(combineHandlers(reg.group, r.middleware), reg.handlers)
+	for _, ih := range r.inserted {
+		if ih.applies(method) {
+			hh = insertHandler(hh, ih.handler)
+		}
+	}
+	return hh
+}
+
+// rebuild updates the combined handlers of the registered methods.
+func (r *Route) rebuild() {
+	engine := r.group.engine
+	for _, method := range r.methods {
+		hh := r.chain(method)
+		r.chains[method] = hh
+		for rh := engine.candidates[method+" "+r.path]; rh != nil; rh = rh.next {
+			if rh.route == r {
+				rh.handlers = hh
+			}
+		}
+	}
+}
+
+// Use appends the middlewares of the route, called after the group middlewares and before the route
+// handlers of all methods, both registered and the ones registered later. Like the group
+// middlewares, they should be added before the engine starts serving requests.
+//
+//	engine.DELETE("/users/<id>", deleteUser).Use(requireAdmin, audit)
+func (r *Route) Use(handlers ...Handler) *Route {
+	r.middleware = combineHandlers(r.middleware, handlers)
+	r.rebuild()
 	return r
 }
 
+// Prepend inserts the middlewares of the route before the ones added by Use.
+func (r *Route) Prepend(handlers ...Handler) *Route {
+	r.middleware = combineHandlers(handlers, r.middleware)
+	r.rebuild()
+	return r
+}
+
+// insertedHandler is the handler inserted before the last route handler of the methods (all methods if empty).
+type insertedHandler struct {
+	handler Handler
+	methods []string
+}
+
+func (ih insertedHandler) applies(method string) bool {
+	return len(ih.methods) == 0 || inStrings(method, ih.methods)
+}
+
+// insert inserts the handler before the last route handler of the methods (all methods if empty),
+// both for the registered methods and the ones registered later.
+func (r *Route) insert(handler Handler, methods ...string) {
+	r.inserted = append(r.inserted, insertedHandler{handler: handler, methods: methods})
+	r.rebuild()
+}
+
+// insertHandler returns the copy of the handlers with the handler inserted before the last one.
+func insertHandler(handlers []Handler, handler Handler) []Handler {
+	if len(handlers) == 0 {
+		return handlers
+	}
+	last := len(handlers) - 1
+	hh := make([]Handler, 0, len(handlers)+1)
+	hh = append(hh, handlers[:last]...)
+	return append(hh, handler, handlers[last])
+}
+
 // buildURLTemplate converts a route pattern into a URL template by removing regular expressions in parameter tokens.
 func buildURLTemplate(path string) string {
 	template, start, end := "", -1, -1
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -18,7 +19,7 @@ func newMockStore() *mockStore {
 }
 
 func (s *mockStore) Add(key string, data interface{}) int {
-	for _, handler := range data.([]Handler) {
+	for _, handler := range data.(*routeHandlers).handlers {
 		handler(nil)
 	}
 	return s.store.Add(key, data)
@@ -96,6 +97,30 @@ func TestRouteAdd(t *testing.T) {
 	assert.Equal(t, "1.2.", buf.String(), "buf@3 =")
 }
 
+func TestRouteUse(t *testing.T) {
+	engine := New()
+	trace := ""
+	mw := func(name string) Handler {
+		return func(c *Context) { trace += name }
+	}
+	engine.Use(mw("engine."))
+	r := engine.GET("/users", mw("get."))
+	r.Use(mw("auth."), mw("audit.")).Prepend(mw("first."))
+	r.POST(mw("post."))
+	r.CacheFor(time.Minute)
+	engine.Use(mw("late."))
+
+	serveTestRequest(engine, "/users")
+	assert.Equal(t, "engine.first.auth.audit.get.", trace)
+	trace = ""
+	serveTestRequest(engine, "/users")
+	assert.Equal(t, "engine.first.auth.audit.", trace, "cached response")
+
+	trace = ""
+	serveTestRequest(engine, "/users", withMethod("POST"))
+	assert.Equal(t, "engine.first.auth.audit.post.", trace)
+}
+
 func TestRouteMethods(t *testing.T) {
 	router := New()
 	for _, method := range Methods {
@@ -130,6 +155,37 @@ func TestRouteMethods(t *testing.T) {
 	assert.Equal(t, 1, router.stores.Get("PUT").(*mockStore).count, "router.stores.Get(PUT).count =")
 }
 
+func TestAllowedMethods(t *testing.T) {
+	router := New()
+	router.GET("/users/<id:\\d+>", func(*Context) {})
+	router.POST("/users/<id:\\d+>", func(*Context) {})
+	router.PUT("/users/<id:\\d+>", func(*Context) {})
+	router.GET("/posts", func(*Context) {})
+
+	assert.Equal(t, []string{"GET", "OPTIONS", "POST", "PUT"}, router.AllowedMethods("/users/12"))
+	assert.Equal(t, []string{"GET", "OPTIONS"}, router.AllowedMethods("/posts"))
+	assert.Nil(t, router.AllowedMethods("/users/abc"))
+	assert.Equal(t, "GET, OPTIONS, POST, PUT", router.findAllowedMethods("/users/1").header)
+}
+
+func TestRouteDocumentation(t *testing.T) {
+	router := New()
+	router.To("GET,POST", "/users", func(*Context) {}).Summary("Users").Description("Lists or creates users.")
+	router.GET("/users/<id>", func(*Context) {}).Name("user").SetMeta("auth", true)
+	router.Group("/api")
+
+	routes := router.Routes()
+	if assert.Len(t, routes, 2) {
+		assert.Equal(t, RouteInfo{Name: "/users", Path: "/users", Methods: []string{"GET", "POST"}, Summary: "Users",
+			Description: "Lists or creates users.", Meta: map[string]interface{}{}, Handlers: map[string][]string{
+				"GET":  {"github.com/night-codes/tokay.TestRouteDocumentation.func1"},
+				"POST": {"github.com/night-codes/tokay.TestRouteDocumentation.func1"},
+			}}, routes[0])
+		assert.Equal(t, "user", routes[1].Name)
+		assert.Equal(t, true, routes[1].Meta["auth"])
+	}
+}
+
 func TestBuildURLTemplate(t *testing.T) {
 	tests := []struct {
 		path, expected string
@@ -153,3 +209,21 @@ func TestBuildURLTemplate(t *testing.T) {
 		assert.Equal(t, test.expected, actual, "buildURLTemplate("+test.path+") =")
 	}
 }
+
+func TestRouteWhen(t *testing.T) {
+	router := New()
+	router.GET("/checkout/<id>", func(c *Context) { c.String(200, "B"+c.Param("id")) }).When(func(c *Context) bool {
+		return c.Cookie("ab") == "b"
+	})
+	router.GET("/checkout/<id>", func(c *Context) { c.String(200, "A"+c.Param("id")) })
+	router.GET("/beta", func(c *Context) { c.String(200, "beta") }).When(func(c *Context) bool {
+		return c.Param("none") == "" && c.GetHeader("X-Beta") == "1"
+	})
+
+	assert.Equal(t, "A1", string(serveTestRequest(router, "/checkout/1").Response.Body()))
+
+	assert.Equal(t, "B2", string(serveTestRequest(router, "/checkout/2", withHeader("Cookie", "ab=b")).Response.Body()))
+
+	assert.Equal(t, 404, serveTestRequest(router, "/beta").Response.StatusCode())
+	assert.Equal(t, "beta", string(serveTestRequest(router, "/beta", withHeader("X-Beta", "1")).Response.Body()))
+}
@@ -1,6 +1,8 @@
 package tokay
 
 import (
+	pathpkg "path"
+	"path/filepath"
 	"strings"
 
 	"github.com/valyala/fasthttp"
@@ -11,6 +13,10 @@ type RouterGroup struct {
 	path     string
 	engine   *Engine
 	handlers []Handler
+	// stripPrefix removes the group path from the request paths of the group routes (see StripPrefix)
+	stripPrefix bool
+	// csrf is the CSRF protection mode of the group routes, nil if not set (see CSRF)
+	csrf *bool
 }
 
 // newRouteGroup creates a new RouterGroup with the given path, engine, and handlers.
@@ -72,10 +78,14 @@ func (r *RouterGroup) TRACE(path string, handlers ...Handler) *Route {
 	return newRoute(path, r).TRACE(handlers...)
 }
 
-// Any adds a route with the given route, handlers, and the HTTP methods as listed in routing.Methods.
+// Any adds a route with the given route, handlers, and the HTTP methods as listed in routing.Methods,
+// except the disabled ones (see Engine.DisableMethods).
 func (r *RouterGroup) Any(path string, handlers ...Handler) *Route {
 	route := newRoute(path, r)
 	for _, method := range Methods {
+		if r.engine.isDisabledMethod(method) {
+			continue
+		}
 		route.add(method, handlers)
 	}
 	return route
@@ -99,7 +109,10 @@ func (r *RouterGroup) Group(path string, handlers ...Handler) *RouterGroup {
 	if path == "" || path[0] != '/' {
 		path = "/" + path
 	}
-	return newRouteGroup(r.path+path, r.engine, handlers)
+	group := newRouteGroup(r.path+path, r.engine, handlers)
+	group.csrf = r.csrf
+	r.engine.groups = append(r.engine.groups, group)
+	return group
 }
 
 // Use registers one or multiple handlers to the current route group.
@@ -108,6 +121,22 @@ func (r *RouterGroup) Use(handlers ...Handler) {
 	r.handlers = append(r.handlers, handlers...)
 }
 
+// StaticConfig is a struct for specifying static files serving options.
+type StaticConfig struct {
+	// Transparently compresses responses. Compressed copies of the files are
+	// cached on disk next to the originals, so root should be writable.
+	Compress bool
+	// Uses brotli encoding (with gzip fallback) when Compress is enabled.
+	CompressBrotli bool
+	// Serves pre-compressed "<file>.br" and "<file>.gz" files when they are present
+	// and newer than the original file. Enables Compress and CompressBrotli.
+	Precompressed bool
+	// Enables byte range requests. The requests for multiple ranges get the multipart/byteranges responses.
+	AcceptByteRange bool
+	// Index file names to serve for directory requests. Default is none.
+	IndexNames []string
+}
+
 // Static serves files from the given file system root.
 // Where:
 // 'path' - relative path from current engine path on site (must be without trailing slash),
@@ -117,21 +146,53 @@ func (r *RouterGroup) Static(path, root string, compress ...bool) *Route {
 	if len(compress) == 0 {
 		compress = append(compress, true)
 	}
+	return r.StaticWithConfig(path, root, StaticConfig{Compress: compress[0]})
+}
+
+// StaticWithConfig serves files from the given file system root with the given options.
+//     engine.StaticWithConfig("/static", "/var/www", tokay.StaticConfig{Precompressed: true})
+func (r *RouterGroup) StaticWithConfig(path, root string, config StaticConfig) *Route {
 	if path == "" || path[len(path)-1] != '/' {
 		path += "/"
 	}
 
 	group := r.Group(path)
-	handler := (&fasthttp.FS{
-		Root:     root,
-		Compress: compress[0],
+	fs := &fasthttp.FS{
+		Root:            root,
+		Compress:        config.Compress,
+		CompressBrotli:  config.CompressBrotli,
+		AcceptByteRange: config.AcceptByteRange,
+		IndexNames:      config.IndexNames,
 		PathRewrite: func(ctx *fasthttp.RequestCtx) []byte {
 			url := strings.Split(string(ctx.Request.RequestURI()), "?")[0]
 			return []byte("/" + strings.TrimPrefix(url, group.path))
 		},
-	}).NewRequestHandler()
+	}
+	if config.Precompressed {
+		fs.Compress = true
+		fs.CompressBrotli = true
+		fs.CompressedFileSuffixes = map[string]string{
+			"gzip": ".gz",
+			"br":   ".br",
+		}
+	}
+	handler := fs.NewRequestHandler()
 
 	return newRoute("*", group).To("GET,HEAD", func(c *Context) {
+		if config.AcceptByteRange {
+			name := filepath.Join(root, filepath.FromSlash(pathpkg.Clean("/"+strings.TrimPrefix(c.Path(), group.path))))
+			if c.serveByteRanges(name) {
+				return
+			}
+		}
+		if fs.Compress {
+			// FS has already compressed the file (if client accepts it)
+			c.DisableCompression()
+		}
 		handler(c.RequestCtx)
+		if fs.Compress {
+			c.Response.Header.Add("Vary", "Accept-Encoding")
+		}
+		c.setFileContentType(c.Path())
 	})
 }
@@ -0,0 +1,84 @@
+package tokay
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// RoutesManifest is the declarative routes definition read by Engine.LoadRoutes.
+	RoutesManifest struct {
+		Routes []RouteDefinition `yaml:"routes" json:"routes"`
+	}
+
+	// RouteDefinition is a route of the RoutesManifest.
+	RouteDefinition struct {
+		Method      string                 `yaml:"method" json:"method"` // HTTP methods separated by commas, default is GET
+		Path        string                 `yaml:"path" json:"path"`
+		Handler     string                 `yaml:"handler" json:"handler"`
+		Middlewares []string               `yaml:"middlewares" json:"middlewares"`
+		Name        string                 `yaml:"name" json:"name"`
+		Summary     string                 `yaml:"summary" json:"summary"`
+		Description string                 `yaml:"description" json:"description"`
+		Meta        map[string]interface{} `yaml:"meta" json:"meta"`
+	}
+)
+
+// LoadRoutes reads the YAML or JSON routes manifest and registers the routes. Handler and
+// middleware names are looked up in the handlers map. Nothing is registered if the manifest
+// is invalid or refers to unknown handlers.
+//
+//	routes:
+//	  - method: GET
+//	    path: /users/<id>
+//	    handler: getUser
+//	    middlewares: [auth]
+//	    name: user
+//	    meta: {priority: 1}
+//
+//	err := engine.LoadRoutes(f, map[string]tokay.Handler{"getUser": getUser, "auth": auth})
+func (engine *Engine) LoadRoutes(r io.Reader, handlers map[string]Handler) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var manifest RoutesManifest
+	// JSON documents are valid YAML
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("invalid routes manifest: %v", err)
+	}
+
+	chains := make([][]Handler, len(manifest.Routes))
+	for i, def := range manifest.Routes {
+		if def.Path == "" || def.Path[0] != '/' {
+			return fmt.Errorf("route #%d: invalid path %q", i+1, def.Path)
+		}
+		for _, name := range append(def.Middlewares, def.Handler) {
+			h, ok := handlers[name]
+			if !ok || h == nil {
+				return fmt.Errorf("route %s: unknown handler %q", def.Path, name)
+			}
+			chains[i] = append(chains[i], h)
+		}
+	}
+
+	for i, def := range manifest.Routes {
+		method := strings.ToUpper(strings.Replace(def.Method, " ", "", -1))
+		if method == "" {
+			method = "GET"
+		}
+		route := engine.To(method, def.Path, chains[i]...)
+		if def.Name != "" {
+			route.Name(def.Name)
+		}
+		route.Summary(def.Summary).Description(def.Description)
+		for key, value := range def.Meta {
+			route.SetMeta(key, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package tokay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadRoutes(t *testing.T) {
+	handlers := map[string]Handler{
+		"auth": func(c *Context) {
+			if c.GetHeader("Authorization") == "" {
+				c.AbortWithStatus(401)
+			}
+		},
+		"getUser": func(c *Context) { c.String(200, "user "+c.Param("id")) },
+		"health":  func(c *Context) { c.String(200, "OK") },
+	}
+
+	engine := New()
+	err := engine.LoadRoutes(strings.NewReader(`
+routes:
+  - method: GET
+    path: /users/<id>
+    handler: getUser
+    middlewares: [auth]
+    name: user
+    summary: Get user
+    meta:
+      priority: 1
+  - path: /health
+    handler: health
+`), handlers)
+	assert.Nil(t, err)
+	assert.Equal(t, 401, serveTestRequest(engine, "/users/1").Response.StatusCode())
+	assert.Equal(t, "OK", string(serveTestRequest(engine, "/health").Response.Body()))
+	route := engine.Route("user")
+	if assert.NotNil(t, route) {
+		assert.Equal(t, 1, route.Meta(PriorityMetaKey))
+		assert.Equal(t, "Get user", route.Info().Summary)
+	}
+
+	engine = New()
+	err = engine.LoadRoutes(strings.NewReader(`{"routes": [{"method": "get,post", "path": "/health", "handler": "health"}]}`), handlers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GET", "OPTIONS", "POST"}, engine.AllowedMethods("/health"))
+
+	engine = New()
+	err = engine.LoadRoutes(strings.NewReader(`{"routes": [{"path": "/a", "handler": "health"}, {"path": "/b", "handler": "missing"}]}`), handlers)
+	assert.NotNil(t, err)
+	assert.Empty(t, engine.Routes())
+	assert.NotNil(t, engine.LoadRoutes(strings.NewReader(`routes: [{path: a, handler: health}]`), handlers))
+	assert.NotNil(t, engine.LoadRoutes(strings.NewReader(`routes: {`), handlers))
+}
@@ -0,0 +1,170 @@
+package tokay
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ServerGroupConfig is a struct for specifying ServerGroup options.
+type ServerGroupConfig struct {
+	// Logger is shared by the engines of the group (with the "server" field set to the server name).
+	// By default the engines keep their own loggers.
+	Logger FieldLogger
+	// Signals stop the group. Default is SIGINT and SIGTERM.
+	Signals []os.Signal
+	// ShutdownTimeout is the maximum time of the graceful shutdown of the group. Default is 30 seconds.
+	ShutdownTimeout time.Duration
+}
+
+type (
+	// ServerGroup runs several engines together (for example, the public API, the internal admin
+	// and the metrics servers) and shuts them all down gracefully when a signal is received
+	// or any of the servers stops.
+	ServerGroup struct {
+		config   ServerGroupConfig
+		logger   FieldLogger
+		servers  []*groupServer
+		stop     chan struct{}
+		stopOnce sync.Once
+	}
+
+	groupServer struct {
+		name   string
+		engine *Engine
+		run    func() error
+		done   chan struct{} // closed when run returns
+	}
+)
+
+// NewServerGroup creates a new ServerGroup.
+//
+//	group := tokay.NewServerGroup()
+//	group.AddTLS("api", api, ":443", "cert.pem", "key.pem")
+//	group.Add("admin", admin, "127.0.0.1:9000")
+//	group.Add("metrics", metrics, ":9100")
+//	log.Fatal(group.Run())
+func NewServerGroup(config ...ServerGroupConfig) *ServerGroup {
+	var cfg ServerGroupConfig
+	if len(config) != 0 {
+		cfg = config[0]
+	}
+	if len(cfg.Signals) == 0 {
+		cfg.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+	g := &ServerGroup{config: cfg, logger: cfg.Logger, stop: make(chan struct{})}
+	if g.logger == nil {
+		g.logger = &stdLogger{}
+	}
+	return g
+}
+
+// Add adds the engine serving HTTP requests on the addr (see Engine.Run).
+func (g *ServerGroup) Add(name string, engine *Engine, addr string) *ServerGroup {
+	return g.add(name, engine, func() error {
+		return engine.Run(addr)
+	})
+}
+
+// AddTLS adds the engine serving HTTPS requests on the addr (see Engine.RunTLS).
+func (g *ServerGroup) AddTLS(name string, engine *Engine, addr, certFile, keyFile string) *ServerGroup {
+	return g.add(name, engine, func() error {
+		return engine.RunTLS(addr, certFile, keyFile)
+	})
+}
+
+func (g *ServerGroup) add(name string, engine *Engine, run func() error) *ServerGroup {
+	if g.config.Logger != nil {
+		engine.SetLogger(g.config.Logger.With("server", name))
+	}
+	g.servers = append(g.servers, &groupServer{name: name, engine: engine, run: run, done: make(chan struct{})})
+	return g
+}
+
+// Run starts all the servers and blocks until a signal is received, Shutdown is called or any
+// of the servers stops. Then the other servers are shut down gracefully. The errors of the servers
+// and their shutdown are returned as MultiError.
+func (g *ServerGroup) Run() error {
+	var errs MultiError
+	stopped := make(chan error, len(g.servers))
+	for _, s := range g.servers {
+		go func(s *groupServer) {
+			err := s.run()
+			close(s.done)
+			if err != nil {
+				err = fmt.Errorf("%s: %w", s.name, err)
+			}
+			stopped <- err
+		}(s)
+	}
+	running := len(g.servers)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, g.config.Signals...)
+	defer signal.Stop(signals)
+	select {
+	case sig := <-signals:
+		g.logger.Info("server group stopping", "signal", sig.String())
+	case <-g.stop:
+		g.logger.Info("server group stopping")
+	case err := <-stopped:
+		running--
+		if err != nil {
+			errs = append(errs, err)
+		}
+		g.logger.Info("server group stopping", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.config.ShutdownTimeout)
+	defer cancel()
+	errs = append(errs, g.shutdown(ctx)...)
+	for ; running != 0; running-- {
+		select {
+		case err := <-stopped:
+			if err != nil {
+				errs = append(errs, err)
+			}
+		case <-ctx.Done():
+			return append(errs, ctx.Err()).err()
+		}
+	}
+	g.logger.Info("server group stopped")
+	return errs.err()
+}
+
+// Shutdown stops the running group (see Run).
+func (g *ServerGroup) Shutdown() {
+	g.stopOnce.Do(func() {
+		close(g.stop)
+	})
+}
+
+// shutdown gracefully stops the engines in parallel. The engines which are still starting
+// are stopped as soon as they create the listeners.
+func (g *ServerGroup) shutdown(ctx context.Context) (errs MultiError) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, s := range g.servers {
+		wg.Add(1)
+		go func(s *groupServer) {
+			defer wg.Done()
+			if !s.engine.waitStarted(ctx, s.done) {
+				return
+			}
+			if err := s.engine.Shutdown(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+				mu.Unlock()
+			}
+		}(s)
+	}
+	wg.Wait()
+	return
+}
@@ -0,0 +1,68 @@
+package tokay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// waitStarted waits for the engines to create their listeners.
+func waitStarted(engines ...*Engine) bool {
+	for i := 0; i < 50; i++ {
+		started := true
+		for _, engine := range engines {
+			started = started && engine.Addr() != nil
+		}
+		if started {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestServerGroup(t *testing.T) {
+	logger := &eventLogger{}
+	api, admin := New(), New()
+	api.GET("/", func(c *Context) { c.String(200, "api") })
+	admin.GET("/", func(c *Context) { c.String(200, "admin") })
+
+	group := NewServerGroup(ServerGroupConfig{Logger: logger})
+	group.Add("api", api, "127.0.0.1:0").Add("admin", admin, "127.0.0.1:0")
+	assert.Equal(t, []interface{}{"server", "api"}, api.Logger().(*testLogger).keyvals)
+	done := make(chan error)
+	go func() {
+		done <- group.Run()
+	}()
+	if !assert.True(t, waitStarted(api, admin)) {
+		return
+	}
+	for engine, body := range map[*Engine]string{api: "api", admin: "admin"} {
+		status, resp, err := fasthttp.Get(nil, "http://"+engine.Addr().String()+"/")
+		assert.Nil(t, err)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, body, string(resp))
+	}
+	group.Shutdown()
+	assert.Nil(t, <-done)
+}
+
+func TestServerGroupFailure(t *testing.T) {
+	api, admin := New(), New()
+	group := NewServerGroup(ServerGroupConfig{Logger: &testLogger{}})
+	group.Add("api", api, "127.0.0.1:0").Add("admin", admin, "invalid address")
+	done := make(chan error)
+	go func() {
+		done <- group.Run()
+	}()
+	select {
+	case err := <-done:
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "admin: ")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the group is not stopped")
+	}
+}
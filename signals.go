@@ -0,0 +1,104 @@
+package tokay
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// OnReload registers the function called by Reload, for example to reload the configuration
+// or the templates. Functions are called in the registration order.
+//
+//	engine.OnReload(func() error {
+//		return cache.Flush()
+//	})
+func (engine *Engine) OnReload(fn func() error) {
+	engine.onReload = append(engine.onReload, fn)
+}
+
+// Reload reloads the TLS certificates loaded from files (see ReloadCertificates) and calls
+// the OnReload functions. All errors are returned as MultiError.
+func (engine *Engine) Reload() error {
+	var errs MultiError
+	if engine.certs != nil {
+		if err := engine.ReloadCertificates(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, fn := range engine.onReload {
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.err()
+}
+
+// RunWithSignals runs the HTTP server (see Run) until one of the signals (SIGINT and SIGTERM
+// by default) is received, then gracefully shuts it down (see Shutdown). SIGHUP triggers Reload
+// (on the platforms having it).
+//
+//	func main() {
+//		engine := tokay.New()
+//		engine.OnReload(reloadConfig)
+//		if err := engine.RunWithSignals(":8080"); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func (engine *Engine) RunWithSignals(addr string, sig ...os.Signal) error {
+	if len(sig) == 0 {
+		sig = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append(sig, reloadSignals...)...)
+	defer signal.Stop(signals)
+
+	var runErr error
+	done := make(chan struct{})
+	go func() {
+		runErr = engine.Run(addr)
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			return runErr
+		case s := <-signals:
+			if isReloadSignal(s) {
+				if err := engine.Reload(); err != nil {
+					engine.logger.Error("server reload failed", "error", err)
+				} else {
+					engine.logger.Info("server reloaded")
+				}
+				continue
+			}
+			engine.logger.Info("signal received", "signal", s.String())
+			ctx, cancel := context.WithTimeout(context.Background(), engine.maxGracefulWaitTime+engine.wsShutdownTimeout)
+			defer cancel()
+			if !engine.waitStarted(ctx, done) {
+				select {
+				case <-done:
+					return runErr
+				default:
+					return ctx.Err()
+				}
+			}
+			err := engine.Shutdown(ctx)
+			<-done
+			if err == nil {
+				err = runErr
+			}
+			return err
+		}
+	}
+}
+
+// isReloadSignal reports whether the signal triggers Reload.
+func isReloadSignal(s os.Signal) bool {
+	for _, r := range reloadSignals {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+//go:build js || plan9
+// +build js plan9
+
+package tokay
+
+import "os"
+
+// reloadSignals trigger Reload in RunWithSignals: there is no SIGHUP on the platform.
+var reloadSignals []os.Signal
@@ -0,0 +1,12 @@
+//go:build !js && !plan9
+// +build !js,!plan9
+
+package tokay
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals trigger Reload in RunWithSignals.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,65 @@
+package tokay
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReload(t *testing.T) {
+	engine := New()
+	var calls []string
+	engine.OnReload(func() error {
+		calls = append(calls, "config")
+		return errors.New("bad config")
+	})
+	engine.OnReload(func() error {
+		calls = append(calls, "templates")
+		return nil
+	})
+	assert.Equal(t, "bad config", engine.Reload().Error())
+	assert.Equal(t, []string{"config", "templates"}, calls)
+}
+
+func TestRunWithSignals(t *testing.T) {
+	engine := New(&Config{Logger: &testLogger{}})
+	reloaded := make(chan struct{}, 1)
+	engine.OnReload(func() error {
+		reloaded <- struct{}{}
+		return nil
+	})
+	stopped := make(chan struct{})
+	engine.OnStop(func(ctx context.Context) error {
+		close(stopped)
+		return nil
+	})
+	done := make(chan error)
+	go func() {
+		done <- engine.RunWithSignals("127.0.0.1:0")
+	}()
+	if !assert.True(t, waitStarted(engine)) {
+		return
+	}
+	process, _ := os.FindProcess(os.Getpid())
+
+	assert.Nil(t, process.Signal(syscall.SIGHUP))
+	select {
+	case <-reloaded:
+	case <-time.After(time.Second):
+		t.Error("the server is not reloaded")
+	}
+
+	assert.Nil(t, process.Signal(syscall.SIGTERM))
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+		<-stopped
+	case <-time.After(5 * time.Second):
+		t.Error("the server is not stopped")
+	}
+}
@@ -0,0 +1,63 @@
+package tokay
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestSnapshot is a copy of the request data as plain Go values.
+// It doesn't refer to the pooled Context and fasthttp buffers, so it is safe to use
+// after the handler returns (for example, in audit or event queues).
+type RequestSnapshot struct {
+	Time     time.Time
+	Method   string
+	Host     string
+	Path     string
+	URI      string
+	Route    string // matched route path pattern, empty for the NotFound handlers
+	ClientIP string
+	Headers  http.Header
+	Query    url.Values
+	Params   map[string]string      // route parameters
+	Body     []byte                 // request body copy
+	Data     map[string]interface{} // the selected context data items (values are not deep copied)
+}
+
+// Snapshot returns a copy of the request data with the given context data items (see Set).
+//
+//	snapshot := c.Snapshot(tokay.AuthUserKey)
+//	go audit.Push(snapshot)
+func (c *Context) Snapshot(dataKeys ...string) RequestSnapshot {
+	s := RequestSnapshot{
+		Time:     c.Time(),
+		Method:   c.Method(),
+		Host:     c.Host(),
+		Path:     c.Path(),
+		URI:      c.RequestURI(),
+		ClientIP: c.ClientIP(),
+		Headers:  make(http.Header),
+		Query:    make(url.Values),
+		Params:   make(map[string]string, len(c.pnames)),
+		Body:     append([]byte(nil), c.Request.Body()...),
+		Data:     make(map[string]interface{}, len(dataKeys)),
+	}
+	if c.route != nil {
+		s.Route = c.route.path
+	}
+	c.Request.Header.VisitAll(func(key, value []byte) {
+		s.Headers.Add(string(key), string(value))
+	})
+	c.QueryArgs().VisitAll(func(key, value []byte) {
+		s.Query.Add(string(key), string(value))
+	})
+	for i, name := range c.pnames {
+		s.Params[name] = c.pvalues[i]
+	}
+	for _, key := range dataKeys {
+		if value, ok := c.GetEx(key); ok {
+			s.Data[key] = value
+		}
+	}
+	return s
+}
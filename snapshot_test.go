@@ -0,0 +1,30 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextSnapshot(t *testing.T) {
+	engine := New()
+	var s RequestSnapshot
+	engine.POST("/users/<id>", func(c *Context) {
+		c.Set("user", "admin")
+		c.Set("other", 1)
+		s = c.Snapshot("user", "missing")
+	})
+
+	ctx := serveTestRequest(engine, "/users/5?tag=a&tag=b", withMethod("POST"), withHeader("X-Test", "1"), withBody("", "body"))
+	ctx.Request.Reset()
+
+	assert.Equal(t, "POST", s.Method)
+	assert.Equal(t, "/users/5", s.Path)
+	assert.Equal(t, "/users/5?tag=a&tag=b", s.URI)
+	assert.Equal(t, "/users/<id>", s.Route)
+	assert.Equal(t, "1", s.Headers.Get("X-Test"))
+	assert.Equal(t, []string{"a", "b"}, s.Query["tag"])
+	assert.Equal(t, map[string]string{"id": "5"}, s.Params)
+	assert.Equal(t, []byte("body"), s.Body)
+	assert.Equal(t, map[string]interface{}{"user": "admin"}, s.Data)
+}
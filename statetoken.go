@@ -0,0 +1,118 @@
+package tokay
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/night-codes/go-json"
+)
+
+var (
+	// ErrStateToken is returned for the missing, malformed or forged state tokens (see Context.StateToken).
+	ErrStateToken = errors.New("invalid state token")
+	// ErrStateTokenExpired is returned for the state tokens older than their TTL.
+	ErrStateTokenExpired = errors.New("state token expired")
+	// errNoStateKeys is returned by the state token methods when no keys are set (see Engine.SetStateKeys).
+	errNoStateKeys = errors.New("state token keys are not set")
+)
+
+// SetStateKeys sets the secret keys of the state tokens (see Context.SetStateToken). The tokens are
+// encrypted with the first key and decrypted with any of them, so the keys can be rotated by putting
+// the new key first. The AES-256 keys are derived from the secrets with SHA-256.
+//
+//	engine.SetStateKeys([]byte(os.Getenv("STATE_KEY")), []byte(os.Getenv("OLD_STATE_KEY")))
+func (engine *Engine) SetStateKeys(secrets ...[]byte) {
+	keys := make([]cipher.AEAD, 0, len(secrets))
+	for _, secret := range secrets {
+		key := sha256.Sum256(secret)
+		block, _ := aes.NewCipher(key[:])
+		aead, _ := cipher.NewGCM(block)
+		keys = append(keys, aead)
+	}
+	engine.stateKeys = keys
+}
+
+// EncodeState serializes v to JSON and returns it as the URL-safe authenticated and encrypted token
+// valid for the ttl. The name is authenticated too, so the token of one name is rejected for another.
+// Use it for the state kept in the headers or the URLs, like the OAuth state parameter.
+func (engine *Engine) EncodeState(name string, v interface{}, ttl time.Duration) (string, error) {
+	if len(engine.stateKeys) == 0 {
+		return "", errNoStateKeys
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	aead := engine.stateKeys[0]
+	plain := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(plain, uint64(time.Now().Add(ttl).Unix()))
+	plain = append(plain, data...)
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, []byte(name))), nil
+}
+
+// DecodeState decrypts the token created by EncodeState with the same name into v.
+// ErrStateToken or ErrStateTokenExpired is returned for the invalid tokens.
+func (engine *Engine) DecodeState(name, token string, v interface{}) error {
+	if len(engine.stateKeys) == 0 {
+		return errNoStateKeys
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrStateToken
+	}
+	for _, aead := range engine.stateKeys {
+		size := aead.NonceSize()
+		if len(sealed) < size+aead.Overhead()+8 {
+			return ErrStateToken
+		}
+		plain, err := aead.Open(nil, sealed[:size], sealed[size:], []byte(name))
+		if err != nil {
+			continue
+		}
+		if time.Now().Unix() > int64(binary.BigEndian.Uint64(plain)) {
+			return ErrStateTokenExpired
+		}
+		return json.Unmarshal(plain[8:], v)
+	}
+	return ErrStateToken
+}
+
+// SetStateToken keeps v in the encrypted HTTP-only cookie with the name for the ttl, the stateless
+// alternative to the server sessions for the multi-step flows like wizards (see Engine.EncodeState).
+// The state keys must be set with Engine.SetStateKeys.
+//
+//	c.SetStateToken("signup", wizard, 30*time.Minute)
+func (c *Context) SetStateToken(name string, v interface{}, ttl time.Duration) error {
+	token, err := c.engine.EncodeState(name, v, ttl)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(name, token, "/", "", c.IsTLS(), true, time.Now().Add(ttl))
+	return nil
+}
+
+// StateToken reads the state kept by SetStateToken in the cookie with the name into v.
+// ErrStateToken is returned if there is no valid cookie, ErrStateTokenExpired if it is expired.
+//
+//	var wizard SignUpWizard
+//	if err := c.StateToken("signup", &wizard); err != nil {
+//		c.Redirect(303, "/signup/step1")
+//		return
+//	}
+func (c *Context) StateToken(name string, v interface{}) error {
+	token := c.Cookie(name)
+	if token == "" {
+		return ErrStateToken
+	}
+	return c.engine.DecodeState(name, token, v)
+}
@@ -0,0 +1,66 @@
+package tokay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type wizardState struct {
+	Step  int    `json:"step"`
+	Email string `json:"email"`
+}
+
+func TestStateToken(t *testing.T) {
+	engine := New()
+	_, err := engine.EncodeState("wizard", wizardState{}, time.Minute)
+	assert.Equal(t, errNoStateKeys, err)
+
+	engine.SetStateKeys([]byte("secret"))
+	engine.GET("/step1", func(c *Context) {
+		assert.Nil(t, c.SetStateToken("wizard", wizardState{Step: 2, Email: "bob@example.com"}, time.Minute))
+	})
+	var state wizardState
+	var stateErr error
+	engine.GET("/step2", func(c *Context) {
+		stateErr = c.StateToken("wizard", &state)
+	})
+
+	ctx := serveTestRequest(engine, "/step1")
+	cookie := fasthttp.AcquireCookie()
+	cookie.SetKey("wizard")
+	assert.True(t, ctx.Response.Header.Cookie(cookie))
+	assert.True(t, cookie.HTTPOnly())
+	assert.NotContains(t, string(cookie.Value()), "bob")
+
+	serveTestRequest(engine, "/step2", withHeader("Cookie", string(cookie.Key())+"="+string(cookie.Value())))
+	assert.Nil(t, stateErr)
+	assert.Equal(t, wizardState{Step: 2, Email: "bob@example.com"}, state)
+
+	serveTestRequest(engine, "/step2")
+	assert.Equal(t, ErrStateToken, stateErr)
+
+	// rotated keys decrypt the old tokens
+	token := string(cookie.Value())
+	engine.SetStateKeys([]byte("new secret"), []byte("secret"))
+	state = wizardState{}
+	assert.Nil(t, engine.DecodeState("wizard", token, &state))
+	assert.Equal(t, 2, state.Step)
+
+	// the token is bound to its name
+	assert.Equal(t, ErrStateToken, engine.DecodeState("other", token, &state))
+	// tampered tokens are rejected
+	tampered := []byte(token)
+	tampered[len(tampered)-2] ^= 1
+	assert.Equal(t, ErrStateToken, engine.DecodeState("wizard", string(tampered), &state))
+	assert.Equal(t, ErrStateToken, engine.DecodeState("wizard", "bad token", &state))
+
+	engine.SetStateKeys([]byte("other secret"))
+	assert.Equal(t, ErrStateToken, engine.DecodeState("wizard", token, &state))
+
+	expired, err := engine.EncodeState("wizard", wizardState{}, -time.Second)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrStateTokenExpired, engine.DecodeState("wizard", expired, &state))
+}
@@ -0,0 +1,95 @@
+package tokay
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+type (
+	// Stats contains the engine request counters (see Engine.Stats).
+	Stats struct {
+		Requests        uint64         `json:"requests"`         // the number of handled requests
+		ClientErrors    uint64         `json:"client_errors"`    // the number of responses with 4xx status codes
+		ServerErrors    uint64         `json:"server_errors"`    // the number of responses with 5xx status codes
+		Statuses        map[int]uint64 `json:"statuses"`         // the number of responses by the status codes
+		ActiveRequests  int64          `json:"active_requests"`  // the number of requests being handled now
+		OpenConnections int32          `json:"open_connections"` // the number of open client connections
+		// ListenerConnections is the number of the connections accepted by the GracefulListener
+		// of the engine and not closed yet, including the hijacked ones.
+		ListenerConnections uint64 `json:"listener_connections"`
+		// PoolHitRate is the share of the requests handled by the reused contexts.
+		PoolHitRate float64 `json:"pool_hit_rate"`
+		// Websockets is the number of the active WebSocket connections.
+		Websockets int `json:"websockets"`
+		// WriteErrors is the number of the responses not written to the clients (see Engine.OnWriteError).
+		WriteErrors uint64 `json:"write_errors"`
+	}
+
+	// statsCounters are updated atomically by HandleRequest.
+	statsCounters struct {
+		requests, clientErrors, serverErrors uint64
+		active                               int64
+		// writeErrors is the number of the responses failed to be written
+		writeErrors uint64
+		// contexts is the number of the contexts created by the pool
+		contexts uint64
+		// statuses are the response counts by the status codes
+		statuses [600]uint64
+	}
+)
+
+// Stats returns the current values of the engine request counters.
+func (engine *Engine) Stats() Stats {
+	stats := Stats{
+		Requests:        atomic.LoadUint64(&engine.stats.requests),
+		ClientErrors:    atomic.LoadUint64(&engine.stats.clientErrors),
+		ServerErrors:    atomic.LoadUint64(&engine.stats.serverErrors),
+		Statuses:        make(map[int]uint64),
+		ActiveRequests:  atomic.LoadInt64(&engine.stats.active),
+		OpenConnections: engine.Server.GetOpenConnectionsCount(),
+		Websockets:      engine.websockets.count(),
+		WriteErrors:     atomic.LoadUint64(&engine.stats.writeErrors),
+	}
+	for status := range engine.stats.statuses {
+		if n := atomic.LoadUint64(&engine.stats.statuses[status]); n != 0 {
+			stats.Statuses[status] = n
+		}
+	}
+	for _, ln := range engine.runningListeners() {
+		stats.ListenerConnections += atomic.LoadUint64(&ln.connsCount)
+	}
+	if acquired := stats.Requests + uint64(stats.ActiveRequests); acquired != 0 {
+		if contexts := atomic.LoadUint64(&engine.stats.contexts); contexts < acquired {
+			stats.PoolHitRate = 1 - float64(contexts)/float64(acquired)
+		}
+	}
+	return stats
+}
+
+// StatsHandler responds with the engine statistics (see Engine.Stats) as JSON. The handler is opt-in,
+// protect its route with an authorization middleware or serve it on the internal address.
+//
+//	engine.GET("/internal/stats", tokay.StatsHandler)
+func StatsHandler(c *Context) {
+	c.JSON(http.StatusOK, c.engine.Stats())
+}
+
+// begin counts the started request.
+func (s *statsCounters) begin() {
+	atomic.AddInt64(&s.active, 1)
+}
+
+// end counts the finished request with the response status code.
+func (s *statsCounters) end(statusCode int) {
+	atomic.AddInt64(&s.active, -1)
+	atomic.AddUint64(&s.requests, 1)
+	if statusCode > 0 && statusCode < len(s.statuses) {
+		atomic.AddUint64(&s.statuses[statusCode], 1)
+	}
+	switch {
+	case statusCode >= 500:
+		atomic.AddUint64(&s.serverErrors, 1)
+	case statusCode >= 400:
+		atomic.AddUint64(&s.clientErrors, 1)
+	}
+}
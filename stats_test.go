@@ -0,0 +1,36 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/night-codes/go-json"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineStats(t *testing.T) {
+	engine := New()
+	engine.GET("/ok", func(c *Context) {})
+	engine.GET("/fail", func(c *Context) {
+		c.AbortWithStatus(503)
+	})
+	engine.GET("/internal/stats", StatsHandler)
+
+	for _, uri := range []string{"/ok", "/ok", "/missing", "/fail"} {
+		serveTestRequest(engine, uri)
+	}
+	stats := engine.Stats()
+	assert.Equal(t, uint64(4), stats.Requests)
+	assert.Equal(t, uint64(1), stats.ClientErrors)
+	assert.Equal(t, uint64(1), stats.ServerErrors)
+	assert.Equal(t, map[int]uint64{200: 2, 404: 1, 503: 1}, stats.Statuses)
+	assert.Equal(t, int64(0), stats.ActiveRequests)
+	assert.Equal(t, 0, stats.Websockets)
+	assert.True(t, stats.PoolHitRate >= 0 && stats.PoolHitRate < 1)
+
+	ctx := serveTestRequest(engine, "/internal/stats")
+	var body map[string]interface{}
+	assert.Nil(t, json.Unmarshal(ctx.Response.Body(), &body))
+	assert.Equal(t, float64(4), body["requests"])
+	assert.Equal(t, map[string]interface{}{"200": float64(2), "404": float64(1), "503": float64(1)}, body["statuses"])
+	assert.Contains(t, body, "pool_hit_rate")
+}
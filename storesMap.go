@@ -29,11 +29,11 @@ func (m *storesMap) Set(key string, val routeStore) {
 }
 
 func (m *storesMap) Range(fn func(key string, value routeStore)) {
-	m.Lock()
+	m.RLock()
 	for key, value := range m.M {
 		fn(key, value)
 	}
-	m.Unlock()
+	m.RUnlock()
 }
 
 func (m *storesMap) Get(key string) routeStore {
@@ -0,0 +1,45 @@
+package tokay
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Timeout returns a middleware running the rest of the handlers with the deadline: Context.Deadline
+// returns it and Context.Done is closed (with Context.Err returning context.DeadlineExceeded) when it
+// passes, so the handlers and the code they call with the Context (database queries, HTTP requests)
+// can stop the work. The handlers run synchronously and must observe the cancellation; when they return
+// after the deadline the response is replaced with the statusCode error (503 Service Unavailable by
+// default, http.StatusGatewayTimeout is handy for the proxying routes). The tighter outer deadline is kept.
+//
+//	api.GET("/report", tokay.Timeout(5*time.Second), func(c *tokay.Context) {
+//		rows, err := db.QueryContext(c, reportQuery)
+//		...
+//	})
+func Timeout(timeout time.Duration, statusCode ...int) Handler {
+	code := http.StatusServiceUnavailable
+	if len(statusCode) != 0 {
+		code = statusCode[0]
+	}
+	return func(c *Context) {
+		deadline := time.Now().Add(timeout)
+		if outer, ok := c.Deadline(); ok && outer.Before(deadline) {
+			c.Next()
+			return
+		}
+		outer := c.deadline
+		c.deadline = deadline
+		signal := c.doneSignal()
+		timer := time.AfterFunc(timeout, func() {
+			signal.close(context.DeadlineExceeded)
+		})
+		c.Next()
+		timer.Stop()
+		c.deadline = outer
+		if signal.closedWith(context.DeadlineExceeded) {
+			c.Response.Reset()
+			c.AbortWithError(code, nil)
+		}
+	}
+}
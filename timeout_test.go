@@ -0,0 +1,61 @@
+package tokay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	engine := New()
+	var deadline time.Time
+	var err error
+	engine.GET("/slow", Timeout(20*time.Millisecond), func(c *Context) {
+		deadline, _ = c.Deadline()
+		var ctx context.Context = c
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(time.Second):
+		}
+		c.String(200, "late")
+	})
+	engine.GET("/fast", Timeout(time.Second, 504), func(c *Context) {
+		c.String(200, "ok")
+	})
+	engine.GET("/nested", Timeout(20*time.Millisecond, 504), Timeout(time.Minute), func(c *Context) {
+		deadline, _ = c.Deadline()
+		<-c.Done()
+	})
+
+	start := time.Now()
+	ctx := serveTestRequest(engine, "/slow")
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+	assert.Equal(t, 503, ctx.Response.StatusCode())
+	assert.Equal(t, "Service Unavailable", string(ctx.Response.Body()))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.WithinDuration(t, start.Add(20*time.Millisecond), deadline, 10*time.Millisecond)
+
+	ctx = serveTestRequest(engine, "/fast")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "ok", string(ctx.Response.Body()))
+
+	start = time.Now()
+	ctx = serveTestRequest(engine, "/nested")
+	assert.Equal(t, 504, ctx.Response.StatusCode())
+	assert.WithinDuration(t, start.Add(20*time.Millisecond), deadline, 10*time.Millisecond)
+}
+
+func TestContextValue(t *testing.T) {
+	engine := New()
+	engine.GET("/", func(c *Context) {
+		c.Set("user", "john")
+		c.SetUserValue("trace", "t1")
+		_, ok := c.Deadline()
+		c.String(200, c.Value("user").(string)+" "+c.Value("trace").(string)+" "+map[bool]string{true: "deadline", false: "none"}[ok])
+	})
+	ctx := serveTestRequest(engine, "/")
+	assert.Equal(t, "john t1 none", string(ctx.Response.Body()))
+}
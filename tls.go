@@ -0,0 +1,220 @@
+package tokay
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// certReloader keeps the TLS certificate loaded from files and reloads it when the files are changed.
+type certReloader struct {
+	sync.RWMutex
+	certFile, keyFile string
+	cert              *tls.Certificate
+	modTime           time.Time
+}
+
+// newCertReloader loads the certificate from the given files.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// reload loads the certificate key pair from files.
+func (r *certReloader) reload() error {
+	modTime, err := r.filesModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.Unlock()
+	return nil
+}
+
+// filesModTime returns the latest modification time of the certificate and key files.
+func (r *certReloader) filesModTime() (modTime time.Time, err error) {
+	for _, file := range []string{r.certFile, r.keyFile} {
+		var fi os.FileInfo
+		if fi, err = os.Stat(file); err != nil {
+			return
+		}
+		if fi.ModTime().After(modTime) {
+			modTime = fi.ModTime()
+		}
+	}
+	return
+}
+
+// reloadIfChanged reloads the certificate if the files are changed since the last loading.
+func (r *certReloader) reloadIfChanged(logger FieldLogger) {
+	r.RLock()
+	lastModTime := r.modTime
+	r.RUnlock()
+	if modTime, err := r.filesModTime(); err == nil && modTime.After(lastModTime) {
+		if err := r.reload(); err != nil {
+			logger.Error("cannot reload TLS certificate", "cert", r.certFile, "error", err)
+		} else {
+			logger.Info("TLS certificate reloaded", "cert", r.certFile)
+		}
+	}
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.RLock()
+	cert := r.cert
+	r.RUnlock()
+	return cert, nil
+}
+
+// certStore selects the TLS certificate by the SNI server name.
+type certStore struct {
+	def   *certReloader            // certificate for the unknown server names, may be nil
+	hosts map[string]*certReloader // certificates by host names ("example.com", "*.example.com")
+}
+
+// newCertStore loads the default certificate (if files are given) and the certificates by host names.
+func newCertStore(certFile, keyFile string, hosts map[string]CertKeyPair) (s *certStore, err error) {
+	s = &certStore{hosts: make(map[string]*certReloader, len(hosts))}
+	if certFile != "" || keyFile != "" {
+		if s.def, err = newCertReloader(certFile, keyFile); err != nil {
+			return nil, err
+		}
+	}
+	for host, pair := range hosts {
+		if s.hosts[strings.ToLower(host)], err = newCertReloader(pair.CertFile, pair.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// each calls fn for every certificate in the store.
+func (s *certStore) each(fn func(r *certReloader) error) error {
+	if s.def != nil {
+		if err := fn(s.def); err != nil {
+			return err
+		}
+	}
+	for _, r := range s.hosts {
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watch checks the files every interval and reloads the changed certificates until stop is closed.
+func (s *certStore) watch(interval time.Duration, stop <-chan struct{}, logger FieldLogger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			s.each(func(r *certReloader) error {
+				r.reloadIfChanged(logger)
+				return nil
+			})
+		}
+	}
+}
+
+// GetCertificate implements tls.Config.GetCertificate.
+// Exact host name match has priority over the wildcard ("*.example.com") one.
+func (s *certStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if hello != nil && hello.ServerName != "" {
+		name := strings.ToLower(strings.TrimSuffix(hello.ServerName, "."))
+		if r, ok := s.hosts[name]; ok {
+			return r.GetCertificate(hello)
+		}
+		if i := strings.IndexByte(name, '.'); i > 0 {
+			if r, ok := s.hosts["*"+name[i:]]; ok {
+				return r.GetCertificate(hello)
+			}
+		}
+	}
+	if s.def != nil {
+		return s.def.GetCertificate(hello)
+	}
+	return nil, errors.New("no TLS certificate for the requested server name")
+}
+
+// ReloadCertificates reloads TLS certificate and key files of the running HTTPS server.
+// Handy to call when certificates are renewed (for example, on SIGHUP).
+func (engine *Engine) ReloadCertificates() error {
+	if engine.certs == nil {
+		return errors.New("TLS certificates are not loaded from files")
+	}
+	return engine.certs.each((*certReloader).reload)
+}
+
+// tlsConfig prepares server TLS config for the mutual TLS, reloadable certificates, SNI certificates
+// or custom GetCertificate callback.
+// It returns false when the certificate files are served by fasthttp as is.
+func (engine *Engine) tlsConfig(certFile, keyFile string) (reloadable bool, err error) {
+	s := engine.Server
+	if engine.clientAuth != tls.NoClientCert || engine.clientCAs != nil || engine.clientCAFile != "" {
+		clientCAs := engine.clientCAs
+		if engine.clientCAFile != "" {
+			pem, err := ioutil.ReadFile(engine.clientCAFile)
+			if err != nil {
+				return false, err
+			}
+			// the pool of the config may be shared, the file certificates go to its copy
+			if clientCAs == nil {
+				clientCAs = x509.NewCertPool()
+			} else if pool, ok := interface{}(clientCAs).(interface{ Clone() *x509.CertPool }); ok {
+				clientCAs = pool.Clone()
+			} else {
+				return false, errors.New("ClientCAFile cannot be added to ClientCAs before Go 1.19")
+			}
+			if !clientCAs.AppendCertsFromPEM(pem) {
+				return false, fmt.Errorf("no certificates found in %s", engine.clientCAFile)
+			}
+		}
+		serverTLSConfig(s).ClientAuth = engine.clientAuth
+		s.TLSConfig.ClientCAs = clientCAs
+	}
+
+	getCertificate := engine.getCertificate
+	if getCertificate == nil {
+		if engine.tlsReloadInterval <= 0 && len(engine.sniCertificates) == 0 {
+			return false, nil
+		}
+		if engine.certs, err = newCertStore(certFile, keyFile, engine.sniCertificates); err != nil {
+			return false, err
+		}
+		getCertificate = engine.certs.GetCertificate
+	}
+	serverTLSConfig(s).GetCertificate = getCertificate
+	return true, nil
+}
+
+// serverTLSConfig returns fasthttp server TLS config, creating it if necessary.
+func serverTLSConfig(s *fasthttp.Server) *tls.Config {
+	if s.TLSConfig == nil {
+		s.TLSConfig = &tls.Config{
+			PreferServerCipherSuites: true,
+		}
+	}
+	return s.TLSConfig
+}
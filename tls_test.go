@@ -0,0 +1,107 @@
+package tokay
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestCert generates self-signed certificate for the given host names and writes it to dir.
+func writeTestCert(t *testing.T, dir, name string, hosts ...string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     hosts,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	assert.Nil(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return
+}
+
+func TestCertReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile := writeTestCert(t, dir, "site")
+	r, err := newCertStore(certFile, keyFile, nil)
+	assert.Nil(t, err)
+	cert1, _ := r.GetCertificate(nil)
+	assert.NotNil(t, cert1)
+
+	writeTestCert(t, dir, "site")
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(certFile, future, future)
+
+	stop := make(chan struct{})
+	logger := &eventLogger{}
+	go r.watch(10*time.Millisecond, stop, logger)
+	time.Sleep(100 * time.Millisecond)
+	close(stop)
+
+	cert2, _ := r.GetCertificate(nil)
+	assert.NotEqual(t, cert1.Certificate[0], cert2.Certificate[0])
+	logger.Lock()
+	assert.Contains(t, logger.events, "TLS certificate reloaded cert "+certFile)
+	logger.Unlock()
+
+	_, err = newCertReloader(filepath.Join(dir, "missing.crt"), keyFile)
+	assert.NotNil(t, err)
+}
+
+func TestCertStoreSNI(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	defCert, defKey := writeTestCert(t, dir, "default")
+	exactCert, exactKey := writeTestCert(t, dir, "exact", "example.com")
+	wildCert, wildKey := writeTestCert(t, dir, "wildcard", "*.example.com")
+	s, err := newCertStore(defCert, defKey, map[string]CertKeyPair{
+		"Example.com":   {exactCert, exactKey},
+		"*.example.com": {wildCert, wildKey},
+	})
+	assert.Nil(t, err)
+
+	tests := map[string]string{
+		"example.com":     "exact",
+		"EXAMPLE.COM.":    "exact",
+		"www.example.com": "wildcard",
+		"a.b.example.com": "default",
+		"other.org":       "default",
+		"":                "default",
+	}
+	for serverName, expected := range tests {
+		cert, err := s.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+		assert.Nil(t, err, serverName)
+		leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+		assert.Equal(t, expected, leaf.Subject.CommonName, serverName)
+	}
+
+	s.def = nil
+	_, err = s.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.org"})
+	assert.NotNil(t, err)
+}
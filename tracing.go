@@ -0,0 +1,257 @@
+package tokay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// The trace context propagation formats (see TracingConfig).
+const (
+	// PropagationW3C is the W3C Trace Context: the traceparent and baggage headers.
+	PropagationW3C = "w3c"
+	// PropagationB3 is the Zipkin B3 format: the b3 single header or the X-B3-* headers.
+	PropagationB3 = "b3"
+	// PropagationJaeger is the Jaeger format: the uber-trace-id and uberctx-* headers.
+	PropagationJaeger = "jaeger"
+)
+
+// TraceKey is the context data item with the trace context of the request set by Tracing.
+const TraceKey = "trace"
+
+// TracingConfig is a struct for specifying Tracing middleware options.
+type TracingConfig struct {
+	// Propagation are the formats of the trace headers: the first format found in the request is
+	// extracted and all of them are injected into the outgoing requests (see Context.InjectTrace).
+	// Default is PropagationW3C.
+	Propagation []string
+	// ResponseHeader is the response header with the trace ID, like "X-Trace-ID". Empty to skip.
+	ResponseHeader string
+}
+
+// SpanContext identifies the span of the request in the distributed trace.
+type SpanContext struct {
+	TraceID  string // 32 lowercase hex characters
+	SpanID   string // 16 lowercase hex characters, the span of the request
+	ParentID string // the span of the caller, empty for the new traces
+	Sampled  bool
+}
+
+// traceContext is the trace context of the request kept in the TraceKey data item.
+type traceContext struct {
+	span        SpanContext
+	baggage     map[string]string
+	propagation []string
+}
+
+// Tracing returns the middleware extracting the trace context of the request from the headers of the
+// configured propagation formats, or starting a new trace. Every request gets its own span, the span
+// of the caller becomes the parent. The span is returned by Context.SpanContext, the baggage items by
+// Context.Baggage, and Context.InjectTrace propagates them to the outgoing requests.
+//
+//	engine.Use(tokay.Tracing(tokay.TracingConfig{Propagation: []string{tokay.PropagationB3, tokay.PropagationW3C}}))
+func Tracing(config TracingConfig) Handler {
+	if len(config.Propagation) == 0 {
+		config.Propagation = []string{PropagationW3C}
+	}
+	return func(c *Context) {
+		tc := &traceContext{baggage: map[string]string{}, propagation: config.Propagation}
+		for _, format := range config.Propagation {
+			if extractTrace(format, &c.Request.Header, tc) {
+				break
+			}
+		}
+		if tc.span.TraceID == "" {
+			tc.span = SpanContext{TraceID: randomHex(16), Sampled: true}
+		}
+		tc.span.SpanID = randomHex(8)
+		c.Set(TraceKey, tc)
+		if config.ResponseHeader != "" {
+			c.Response.Header.Set(config.ResponseHeader, tc.span.TraceID)
+		}
+		c.Next()
+	}
+}
+
+// trace returns the trace context of the request, nil if there is no Tracing middleware.
+func (c *Context) trace() *traceContext {
+	tc, _ := c.Get(TraceKey).(*traceContext)
+	return tc
+}
+
+// SpanContext returns the span of the request set by the Tracing middleware,
+// the empty SpanContext if there is none.
+func (c *Context) SpanContext() SpanContext {
+	if tc := c.trace(); tc != nil {
+		return tc.span
+	}
+	return SpanContext{}
+}
+
+// Baggage returns the baggage item of the trace, received from the caller or set by SetBaggage.
+func (c *Context) Baggage(key string) string {
+	if tc := c.trace(); tc != nil {
+		return tc.baggage[key]
+	}
+	return ""
+}
+
+// SetBaggage sets the baggage item of the trace propagated by InjectTrace, the empty value removes it.
+func (c *Context) SetBaggage(key, value string) {
+	tc := c.trace()
+	if tc == nil {
+		return
+	}
+	if value == "" {
+		delete(tc.baggage, key)
+	} else {
+		tc.baggage[key] = value
+	}
+}
+
+// InjectTrace adds the trace headers of all the configured propagation formats to the header of the
+// outgoing request, so the called service continues the trace with the request span as the parent.
+//
+//	req := fasthttp.AcquireRequest()
+//	c.InjectTrace(&req.Header)
+func (c *Context) InjectTrace(header *fasthttp.RequestHeader) {
+	tc := c.trace()
+	if tc == nil {
+		return
+	}
+	keys := make([]string, 0, len(tc.baggage))
+	for key := range tc.baggage {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	span := tc.span
+	flags := "0"
+	if span.Sampled {
+		flags = "1"
+	}
+	for _, format := range tc.propagation {
+		switch format {
+		case PropagationW3C:
+			header.Set("traceparent", "00-"+span.TraceID+"-"+span.SpanID+"-0"+flags)
+			if len(keys) != 0 {
+				items := make([]string, len(keys))
+				for i, key := range keys {
+					items[i] = key + "=" + url.QueryEscape(tc.baggage[key])
+				}
+				header.Set("baggage", strings.Join(items, ","))
+			}
+		case PropagationB3:
+			header.Set("b3", span.TraceID+"-"+span.SpanID+"-"+flags)
+		case PropagationJaeger:
+			header.Set("uber-trace-id", span.TraceID+":"+span.SpanID+":0:"+flags)
+			for _, key := range keys {
+				header.Set("uberctx-"+key, url.QueryEscape(tc.baggage[key]))
+			}
+		}
+	}
+}
+
+// extractTrace reads the trace context of the format from the request header,
+// false is returned if the header has no valid trace context of the format.
+func extractTrace(format string, header *fasthttp.RequestHeader, tc *traceContext) bool {
+	switch format {
+	case PropagationW3C:
+		parts := strings.Split(string(header.Peek("traceparent")), "-")
+		if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || !isTraceID(parts[1], 32) ||
+			!isTraceID(parts[2], 16) || len(parts[3]) != 2 || !isHexString(parts[3]) {
+			return false
+		}
+		flags, _ := strconv.ParseUint(parts[3], 16, 8)
+		tc.span = SpanContext{TraceID: parts[1], ParentID: parts[2], Sampled: flags&1 == 1}
+		for _, item := range strings.Split(string(header.Peek("baggage")), ",") {
+			if i := strings.IndexByte(item, ';'); i >= 0 {
+				// the item properties
+				item = item[:i]
+			}
+			if i := strings.IndexByte(item, '='); i > 0 {
+				if value, err := url.QueryUnescape(strings.TrimSpace(item[i+1:])); err == nil {
+					tc.baggage[strings.TrimSpace(item[:i])] = value
+				}
+			}
+		}
+		return true
+
+	case PropagationB3:
+		var traceID, spanID, sampled string
+		if single := string(header.Peek("b3")); single != "" {
+			parts := strings.Split(single, "-")
+			if len(parts) < 2 {
+				return false
+			}
+			traceID, spanID = parts[0], parts[1]
+			if len(parts) > 2 {
+				sampled = parts[2]
+			}
+		} else {
+			traceID, spanID = string(header.Peek("X-B3-TraceId")), string(header.Peek("X-B3-SpanId"))
+			sampled = string(header.Peek("X-B3-Sampled"))
+			if string(header.Peek("X-B3-Flags")) == "1" {
+				sampled = "d"
+			}
+		}
+		traceID = strings.ToLower(traceID)
+		if len(traceID) == 16 {
+			traceID = strings.Repeat("0", 16) + traceID
+		}
+		if !isTraceID(traceID, 32) || !isTraceID(strings.ToLower(spanID), 16) {
+			return false
+		}
+		tc.span = SpanContext{TraceID: traceID, ParentID: strings.ToLower(spanID), Sampled: sampled != "0" && sampled != "false"}
+		return true
+
+	case PropagationJaeger:
+		value, err := url.QueryUnescape(string(header.Peek("uber-trace-id")))
+		parts := strings.Split(value, ":")
+		if err != nil || len(parts) != 4 || len(parts[0]) > 32 || len(parts[1]) > 16 {
+			return false
+		}
+		traceID := strings.Repeat("0", 32-len(parts[0])) + strings.ToLower(parts[0])
+		spanID := strings.Repeat("0", 16-len(parts[1])) + strings.ToLower(parts[1])
+		flags, err := strconv.ParseUint(parts[3], 16, 8)
+		if !isTraceID(traceID, 32) || !isTraceID(spanID, 16) || err != nil {
+			return false
+		}
+		tc.span = SpanContext{TraceID: traceID, ParentID: spanID, Sampled: flags&1 == 1}
+		header.VisitAll(func(key, value []byte) {
+			if name := strings.ToLower(string(key)); strings.HasPrefix(name, "uberctx-") {
+				if v, err := url.QueryUnescape(string(value)); err == nil {
+					tc.baggage[name[len("uberctx-"):]] = v
+				}
+			}
+		})
+		return true
+	}
+	return false
+}
+
+// isTraceID reports whether s is the non-zero lowercase hex ID of the size.
+func isTraceID(s string, size int) bool {
+	return len(s) == size && isHexString(s) && strings.Trim(s, "0") != "" && strings.ToLower(s) == s
+}
+
+func isHexString(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isHex(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// randomHex returns the random non-zero ID of n bytes as hex.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	b[0] |= 1
+	return hex.EncodeToString(b)
+}
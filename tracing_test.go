@@ -0,0 +1,94 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTracing(t *testing.T) {
+	engine := New()
+	engine.Use(Tracing(TracingConfig{
+		Propagation:    []string{PropagationW3C, PropagationB3, PropagationJaeger},
+		ResponseHeader: "X-Trace-ID",
+	}))
+	var span SpanContext
+	var tenant string
+	out := &fasthttp.RequestHeader{}
+	engine.GET("/", func(c *Context) {
+		span = c.SpanContext()
+		tenant = c.Baggage("tenant")
+		c.SetBaggage("user", "bob smith")
+		out.Reset()
+		c.InjectTrace(out)
+	})
+
+	ctx := serveTestRequest(engine, "/", withHeader("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"),
+		withHeader("baggage", "tenant=acme;ttl=1, other=x"))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentID)
+	assert.Len(t, span.SpanID, 16)
+	assert.True(t, span.Sampled)
+	assert.Equal(t, "acme", tenant)
+	assert.Equal(t, span.TraceID, string(ctx.Response.Header.Peek("X-Trace-ID")))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanID+"-01", string(out.Peek("traceparent")))
+	assert.Equal(t, "other=x,tenant=acme,user=bob+smith", string(out.Peek("baggage")))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanID+"-1", string(out.Peek("b3")))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736:"+span.SpanID+":0:1", string(out.Peek("uber-trace-id")))
+	assert.Equal(t, "bob+smith", string(out.Peek("uberctx-user")))
+
+	serveTestRequest(engine, "/", withHeader("X-B3-TraceId", "a3ce929d0e0e4736"),
+		withHeader("X-B3-SpanId", "00F067AA0BA902B7"), withHeader("X-B3-Sampled", "0"))
+	assert.Equal(t, "0000000000000000a3ce929d0e0e4736", span.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", span.ParentID)
+	assert.False(t, span.Sampled)
+
+	serveTestRequest(engine, "/", withHeader("b3", "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	assert.True(t, span.Sampled)
+
+	serveTestRequest(engine, "/", withHeader("uber-trace-id", "a3ce929d0e0e4736%3A7b%3A0%3A1"), withHeader("uberctx-tenant", "acme"))
+	assert.Equal(t, "0000000000000000a3ce929d0e0e4736", span.TraceID)
+	assert.Equal(t, "000000000000007b", span.ParentID)
+	assert.True(t, span.Sampled)
+	assert.Equal(t, "acme", tenant)
+
+	// the flags are hex: the sampled bit is the lowest bit of the value, not of the character
+	for flags, sampled := range map[string]bool{"0a": false, "0b": true, "00": false, "01": true} {
+		serveTestRequest(engine, "/", withHeader("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-"+flags))
+		assert.Equal(t, "00f067aa0ba902b7", span.ParentID, flags)
+		assert.Equal(t, sampled, span.Sampled, flags)
+		serveTestRequest(engine, "/", withHeader("uber-trace-id", "a3ce929d0e0e4736:7b:0:"+flags[1:]))
+		assert.Equal(t, "000000000000007b", span.ParentID, flags)
+		assert.Equal(t, sampled, span.Sampled, flags)
+	}
+	serveTestRequest(engine, "/", withHeader("uber-trace-id", "a3ce929d0e0e4736:7b:0:0b"))
+	assert.True(t, span.Sampled)
+
+	// the malformed headers start a new trace
+	serveTestRequest(engine, "/", withHeader("traceparent", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"))
+	assert.Len(t, span.TraceID, 32)
+	assert.NotEqual(t, "00000000000000000000000000000000", span.TraceID)
+	assert.Equal(t, "", span.ParentID)
+	assert.True(t, span.Sampled)
+}
+
+func TestTracingDefaultPropagation(t *testing.T) {
+	engine := New()
+	engine.Use(Tracing(TracingConfig{}))
+	out := &fasthttp.RequestHeader{}
+	var span SpanContext
+	engine.GET("/", func(c *Context) {
+		span = c.SpanContext()
+		c.InjectTrace(out)
+	})
+	serveTestRequest(engine, "/", withHeader("b3", "4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"))
+	assert.NotEqual(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID)
+	assert.NotEmpty(t, out.Peek("traceparent"))
+	assert.Empty(t, out.Peek("b3"))
+
+	c := newTestContext("/")
+	assert.Equal(t, SpanContext{}, c.SpanContext())
+	assert.Equal(t, "", c.Baggage("tenant"))
+}
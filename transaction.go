@@ -0,0 +1,84 @@
+package tokay
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+)
+
+// TxKey is the context data key for the request transaction started by Transactional.
+const TxKey = "sqlTx"
+
+// Transactional returns a middleware which begins a database transaction for the request.
+// The transaction is committed when the handlers chain finishes with 2xx status code and rolled
+// back when the chain is aborted, finishes with other status code or panics. The transaction is canceled
+// when the client disconnects, the server shuts down or the deadline of the Timeout middleware passes.
+// Use c.Tx() to access the transaction in handlers.
+//
+//	api := engine.Group("/api", tokay.Transactional(db))
+//	api.POST("/orders", func(c *tokay.Context) {
+//		if _, err := c.Tx().Exec("INSERT INTO orders ..."); err != nil {
+//			c.AbortWithError(500, err)
+//			return
+//		}
+//		c.String(201, "created")
+//	})
+func Transactional(db *sql.DB, opts ...*sql.TxOptions) Handler {
+	opts = append(opts, nil)
+	return func(c *Context) {
+		ctx, cancel := requestContext(c)
+		defer cancel()
+		tx, err := db.BeginTx(ctx, opts[0])
+		if err != nil {
+			c.AbortWithError(http.StatusServiceUnavailable, nil)
+			c.Logger().Error("cannot begin transaction", "error", err)
+			return
+		}
+		c.Set(TxKey, tx)
+
+		committed := false
+		defer func() {
+			if !committed {
+				tx.Rollback()
+			}
+		}()
+
+		c.Next()
+		if code := c.Response.StatusCode(); !c.IsAborted() && code >= 200 && code < 300 {
+			if err := tx.Commit(); err != nil {
+				c.Logger().Error("cannot commit transaction", "error", err)
+				c.Error(http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+			committed = true
+		}
+	}
+}
+
+// Tx returns the request transaction started by Transactional middleware, or nil.
+func (c *Context) Tx() *sql.Tx {
+	tx, _ := c.Get(TxKey).(*sql.Tx)
+	return tx
+}
+
+// requestContext returns the context canceled with the request Context: the Context itself can't be
+// passed to the database drivers, they watch it in the goroutines which outlive the pooled Context.
+// The cancel must be called before the handler returns.
+func requestContext(c *Context) (context.Context, context.CancelFunc) {
+	ctx, cancelDeadline := context.Background(), context.CancelFunc(func() {})
+	if deadline, ok := c.Deadline(); ok {
+		ctx, cancelDeadline = context.WithDeadline(ctx, deadline)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := c.Done()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, func() {
+		cancel()
+		cancelDeadline()
+	}
+}
@@ -0,0 +1,104 @@
+package tokay
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubDriver is the database driver recording the transaction calls.
+type stubDriver struct {
+	mu       sync.Mutex
+	calls    []string
+	deadline bool // whether the last transaction was begun with the deadline
+}
+
+func (d *stubDriver) Open(name string) (driver.Conn, error) { return &stubConn{d}, nil }
+
+func (d *stubDriver) record(call string) {
+	d.mu.Lock()
+	d.calls = append(d.calls, call)
+	d.mu.Unlock()
+}
+
+func (d *stubDriver) reset() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	calls := d.calls
+	d.calls = nil
+	return calls
+}
+
+type stubConn struct{ d *stubDriver }
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *stubConn) Close() error              { return nil }
+func (c *stubConn) Begin() (driver.Tx, error) { return nil, errors.New("BeginTx expected") }
+
+func (c *stubConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	_, ok := ctx.Deadline()
+	c.d.mu.Lock()
+	c.d.deadline = ok
+	c.d.mu.Unlock()
+	c.d.record("begin")
+	return c, nil
+}
+
+func (c *stubConn) Commit() error   { c.d.record("commit"); return nil }
+func (c *stubConn) Rollback() error { c.d.record("rollback"); return nil }
+
+var stubDB = &stubDriver{}
+
+func init() {
+	sql.Register("tokay-stub", stubDB)
+}
+
+func TestTransactional(t *testing.T) {
+	db, err := sql.Open("tokay-stub", "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer db.Close()
+
+	engine := New()
+	engine.Use(Timeout(time.Minute), Transactional(db))
+	var tx *sql.Tx
+	engine.GET("/ok", func(c *Context) {
+		tx = c.Tx()
+		c.String(201, "created")
+	})
+	engine.GET("/abort", func(c *Context) {
+		c.AbortWithError(400, nil)
+	})
+	engine.GET("/error", func(c *Context) {
+		c.String(500, "failed")
+	})
+	engine.GET("/panic", func(c *Context) {
+		panic("boom")
+	})
+
+	assert.Equal(t, 201, serveTestRequest(engine, "/ok").Response.StatusCode())
+	assert.NotNil(t, tx)
+	assert.Equal(t, []string{"begin", "commit"}, stubDB.reset())
+	assert.True(t, stubDB.deadline)
+
+	assert.Equal(t, 400, serveTestRequest(engine, "/abort").Response.StatusCode())
+	assert.Equal(t, []string{"begin", "rollback"}, stubDB.reset())
+
+	assert.Equal(t, 500, serveTestRequest(engine, "/error").Response.StatusCode())
+	assert.Equal(t, []string{"begin", "rollback"}, stubDB.reset())
+
+	assert.PanicsWithValue(t, "boom", func() { serveTestRequest(engine, "/panic") })
+	assert.Equal(t, []string{"begin", "rollback"}, stubDB.reset())
+
+	c := newTestContext("/")
+	assert.Nil(t, c.Tx())
+}
@@ -0,0 +1,204 @@
+package tokay
+
+import (
+	"strings"
+	"sync"
+)
+
+// Device classes of the parsed user agents.
+const (
+	DeviceUnknown = ""
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// maxUACacheEntries is the maximum number of cached parsed user agents.
+const maxUACacheEntries = 4096
+
+// ParsedUA is the classification of the User-Agent header (see Context.UserAgent).
+type ParsedUA struct {
+	// Browser is the browser name, like "Chrome" or "Safari", or the bot name, like "Googlebot".
+	Browser        string
+	BrowserVersion string
+	// OS is the operating system name, like "Windows", "macOS", "iOS" or "Android".
+	OS        string
+	OSVersion string
+	// Device is the device class (DeviceDesktop, DeviceMobile etc.).
+	Device string
+}
+
+// IsBot reports whether the user agent is a crawler or an HTTP library.
+func (ua ParsedUA) IsBot() bool {
+	return ua.Device == DeviceBot
+}
+
+// IsMobile reports whether the user agent is a mobile phone.
+func (ua ParsedUA) IsMobile() bool {
+	return ua.Device == DeviceMobile
+}
+
+// IsTablet reports whether the user agent is a tablet.
+func (ua ParsedUA) IsTablet() bool {
+	return ua.Device == DeviceTablet
+}
+
+// IsDesktop reports whether the user agent is a desktop browser.
+func (ua ParsedUA) IsDesktop() bool {
+	return ua.Device == DeviceDesktop
+}
+
+// uaToken is a User-Agent product token and its name.
+type uaToken struct {
+	token, name string
+}
+
+var (
+	// uaBots are the well-known bots checked before the generic bot markers.
+	uaBots = []uaToken{
+		{"Googlebot", "Googlebot"}, {"bingbot", "Bingbot"}, {"YandexBot", "YandexBot"},
+		{"Baiduspider", "Baiduspider"}, {"DuckDuckBot", "DuckDuckBot"}, {"Applebot", "Applebot"},
+		{"facebookexternalhit", "Facebook"}, {"Twitterbot", "Twitterbot"}, {"Slackbot", "Slackbot"},
+		{"curl/", "curl"}, {"Wget/", "Wget"}, {"python-requests/", "python-requests"},
+		{"Go-http-client/", "Go-http-client"}, {"HeadlessChrome/", "HeadlessChrome"},
+	}
+	// uaBotMarkers are the lower-case substrings of the other bots.
+	uaBotMarkers = []string{"bot", "crawl", "spider", "slurp", "scrape", "http-client", "headless"}
+	// uaBrowsers are checked in order, since the browsers mimic each other's tokens.
+	uaBrowsers = []uaToken{
+		{"Edg/", "Edge"}, {"EdgA/", "Edge"}, {"EdgiOS/", "Edge"}, {"Edge/", "Edge"},
+		{"OPR/", "Opera"}, {"Opera/", "Opera"}, {"YaBrowser/", "Yandex"}, {"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"}, {"FxiOS/", "Firefox"}, {"CriOS/", "Chrome"}, {"Chrome/", "Chrome"},
+		{"MSIE ", "Internet Explorer"}, {"Trident/", "Internet Explorer"},
+	}
+
+	uaCache = struct {
+		sync.RWMutex
+		entries map[string]ParsedUA
+	}{entries: make(map[string]ParsedUA)}
+)
+
+// UserAgent returns the classification of the User-Agent request header.
+// The parsed user agents are cached, so the repeated calls are cheap.
+//
+//	if c.UserAgent().IsMobile() {
+//		c.HTML(200, "index.mobile.html", data)
+//	}
+func (c *Context) UserAgent() ParsedUA {
+	return parseUserAgent(string(c.Request.Header.UserAgent()))
+}
+
+// parseUserAgent returns the cached or parsed user agent.
+func parseUserAgent(header string) ParsedUA {
+	uaCache.RLock()
+	ua, ok := uaCache.entries[header]
+	uaCache.RUnlock()
+	if ok {
+		return ua
+	}
+	ua = parseUA(header)
+	uaCache.Lock()
+	if len(uaCache.entries) >= maxUACacheEntries {
+		uaCache.entries = make(map[string]ParsedUA)
+	}
+	uaCache.entries[header] = ua
+	uaCache.Unlock()
+	return ua
+}
+
+func parseUA(header string) ParsedUA {
+	var ua ParsedUA
+	if header == "" {
+		return ua
+	}
+	ua.OS, ua.OSVersion = parseUAOS(header)
+
+	for _, bot := range uaBots {
+		if strings.Contains(header, bot.token) {
+			ua.Browser, ua.BrowserVersion, ua.Device = bot.name, uaVersion(header, bot.token), DeviceBot
+			return ua
+		}
+	}
+	lower := strings.ToLower(header)
+	for _, marker := range uaBotMarkers {
+		if strings.Contains(lower, marker) {
+			ua.Browser, ua.Device = "Bot", DeviceBot
+			return ua
+		}
+	}
+
+	for _, browser := range uaBrowsers {
+		if strings.Contains(header, browser.token) {
+			ua.Browser, ua.BrowserVersion = browser.name, uaVersion(header, browser.token)
+			if browser.token == "Trident/" {
+				ua.BrowserVersion = uaVersion(header, "rv:")
+			}
+			break
+		}
+	}
+	if ua.Browser == "" && strings.Contains(header, "Safari/") {
+		ua.Browser, ua.BrowserVersion = "Safari", uaVersion(header, "Version/")
+	}
+
+	switch {
+	case strings.Contains(header, "iPad") || strings.Contains(header, "Tablet") ||
+		ua.OS == "Android" && !strings.Contains(header, "Mobile"):
+		ua.Device = DeviceTablet
+	case strings.Contains(header, "Mobi") || strings.Contains(header, "iPhone") || strings.Contains(header, "iPod"):
+		ua.Device = DeviceMobile
+	default:
+		ua.Device = DeviceDesktop
+	}
+	return ua
+}
+
+// parseUAOS returns the operating system name and version.
+func parseUAOS(header string) (string, string) {
+	switch {
+	case strings.Contains(header, "Windows Phone"):
+		return "Windows Phone", uaVersion(header, "Windows Phone ")
+	case strings.Contains(header, "Windows NT "):
+		version := uaVersion(header, "Windows NT ")
+		switch version {
+		case "10.0":
+			version = "10"
+		case "6.3":
+			version = "8.1"
+		case "6.2":
+			version = "8"
+		case "6.1":
+			version = "7"
+		}
+		return "Windows", version
+	case strings.Contains(header, "iPhone") || strings.Contains(header, "iPad") || strings.Contains(header, "iPod"):
+		version := uaVersion(header, "iPhone OS ")
+		if version == "" {
+			version = uaVersion(header, "CPU OS ")
+		}
+		return "iOS", version
+	case strings.Contains(header, "Android"):
+		return "Android", uaVersion(header, "Android ")
+	case strings.Contains(header, "Mac OS X"):
+		return "macOS", uaVersion(header, "Mac OS X ")
+	case strings.Contains(header, "CrOS"):
+		return "ChromeOS", ""
+	case strings.Contains(header, "Linux"):
+		return "Linux", ""
+	}
+	return "", ""
+}
+
+// uaVersion returns the dotted version following the token.
+func uaVersion(header, token string) string {
+	i := strings.Index(header, token)
+	if i < 0 {
+		return ""
+	}
+	s := strings.TrimPrefix(header[i+len(token):], "/")
+	end := 0
+	for end < len(s) && (s[end] >= '0' && s[end] <= '9' || s[end] == '.' || s[end] == '_') {
+		end++
+	}
+	return strings.Trim(strings.ReplaceAll(s[:end], "_", "."), ".")
+}
@@ -0,0 +1,54 @@
+package tokay
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		header string
+		ua     ParsedUA
+	}{
+		{"", ParsedUA{}},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36",
+			ParsedUA{"Chrome", "120.0.6099.109", "Windows", "10", DeviceDesktop}},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			ParsedUA{"Edge", "120.0.2210.91", "Windows", "10", DeviceDesktop}},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+			ParsedUA{"Safari", "17.2", "macOS", "10.15.7", DeviceDesktop}},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			ParsedUA{"Safari", "17.2", "iOS", "17.2", DeviceMobile}},
+		{"Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			ParsedUA{"Chrome", "120.0.6099.119", "iOS", "16.6", DeviceTablet}},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+			ParsedUA{"Chrome", "120.0.6099.144", "Android", "14", DeviceMobile}},
+		{"Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/23.0 Chrome/115.0.0.0 Safari/537.36",
+			ParsedUA{"Samsung Internet", "23.0", "Android", "13", DeviceTablet}},
+		{"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			ParsedUA{"Firefox", "121.0", "Linux", "", DeviceDesktop}},
+		{"Mozilla/5.0 (Windows NT 6.1; Trident/7.0; rv:11.0) like Gecko",
+			ParsedUA{"Internet Explorer", "11.0", "Windows", "7", DeviceDesktop}},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			ParsedUA{"Googlebot", "2.1", "", "", DeviceBot}},
+		{"curl/8.4.0", ParsedUA{"curl", "8.4.0", "", "", DeviceBot}},
+		{"Mozilla/5.0 (compatible; SemrushBot/7~bl; +http://www.semrush.com/bot.html)",
+			ParsedUA{"Bot", "", "", "", DeviceBot}},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.ua, parseUserAgent(test.header), test.header)
+	}
+}
+
+func TestContextUserAgent(t *testing.T) {
+	engine := New()
+	var ua ParsedUA
+	engine.GET("/", func(c *Context) {
+		ua = c.UserAgent()
+	})
+	serveTestRequest(engine, "/", withHeader("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) Mobile/15E148"))
+	assert.True(t, ua.IsMobile())
+	assert.False(t, ua.IsBot())
+	assert.Equal(t, "iOS", ua.OS)
+}
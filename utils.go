@@ -66,6 +66,11 @@ func joinPaths(absolutePath, relativePath string) string {
 }
 
 func mapArgs(ptr interface{}, args *fasthttp.Args) error {
+	return mapTaggedArgs(ptr, args, "form")
+}
+
+// mapTaggedArgs sets the struct fields from args using the names from the field tags with the given key.
+func mapTaggedArgs(ptr interface{}, args *fasthttp.Args, tag string) error {
 	typ := reflect.TypeOf(ptr).Elem()
 	val := reflect.ValueOf(ptr).Elem()
 	for i := 0; i < typ.NumField(); i++ {
@@ -76,11 +81,11 @@ func mapArgs(ptr interface{}, args *fasthttp.Args) error {
 		}
 
 		structFieldKind := structField.Kind()
-		inputFieldName := typeField.Tag.Get("form")
+		inputFieldName := typeField.Tag.Get(tag)
 		if inputFieldName == "" {
 			inputFieldName = typeField.Name
 			if structFieldKind == reflect.Struct {
-				err := mapArgs(structField.Addr().Interface(), args)
+				err := mapTaggedArgs(structField.Addr().Interface(), args, tag)
 				if err != nil {
 					return err
 				}
@@ -0,0 +1,88 @@
+package tokay
+
+import (
+	"html/template"
+	"strings"
+)
+
+type (
+	// TemplateVariantsConfig is a struct for specifying the device template variants options (see Engine.TemplateVariants).
+	TemplateVariantsConfig struct {
+		// Suffixes are the template name suffixes by the device classes (see Context.UserAgent).
+		// Default is {DeviceMobile: "mobile", DeviceTablet: "tablet"}.
+		Suffixes map[string]string
+		// Header is the request header with the device class set by the CDN or the client, like "X-Device".
+		// The header is used instead of the User-Agent parsing if it is present.
+		Header string
+	}
+
+	// templateVariants selects the device variants of the templates.
+	templateVariants struct {
+		suffixes map[string]string
+		header   string
+		vary     string
+	}
+
+	// templateLookuper is implemented by the renders able to check the template existence.
+	templateLookuper interface {
+		TemplateLookup(name string) *template.Template
+	}
+)
+
+// TemplateVariants enables the device variants of the HTML templates: Context.HTML renders the "index.mobile"
+// template (the index.mobile.html file) instead of "index" for the mobile devices if it exists. The device
+// class is detected by the User-Agent header or taken from the configured header, the Vary header of
+// the HTML responses is set accordingly. The Render must implement TemplateLookup (like the default one).
+//
+//	engine.TemplateVariants(tokay.TemplateVariantsConfig{Header: "CloudFront-Is-Mobile-Viewer"})
+func (engine *Engine) TemplateVariants(config ...TemplateVariantsConfig) {
+	var cfg TemplateVariantsConfig
+	if len(config) != 0 {
+		cfg = config[0]
+	}
+	if cfg.Suffixes == nil {
+		cfg.Suffixes = map[string]string{DeviceMobile: "mobile", DeviceTablet: "tablet"}
+	}
+	v := &templateVariants{suffixes: cfg.Suffixes, header: cfg.Header, vary: "User-Agent"}
+	if cfg.Header != "" {
+		v.vary = cfg.Header + ", User-Agent"
+	}
+	engine.variants = v
+}
+
+// templateVariant returns the name of the device variant of the template if it exists, or the name.
+func (c *Context) templateVariant(name string) string {
+	v := c.engine.variants
+	if v == nil {
+		return name
+	}
+	c.Response.Header.Add("Vary", v.vary)
+
+	device := ""
+	if v.header != "" {
+		device = strings.ToLower(strings.TrimSpace(c.GetHeader(v.header)))
+		// the boolean headers like CloudFront-Is-Mobile-Viewer
+		switch device {
+		case "true":
+			device = DeviceMobile
+		case "false":
+			device = DeviceDesktop
+		}
+	}
+	if device == "" {
+		device = c.UserAgent().Device
+	}
+	suffix, ok := v.suffixes[device]
+	if !ok {
+		return name
+	}
+	lookuper, ok := c.engine.Render.(templateLookuper)
+	if !ok {
+		return name
+	}
+	variant := name + "." + suffix
+	if lookuper.TemplateLookup(variant) == nil {
+		return name
+	}
+	return variant
+}
@@ -0,0 +1,41 @@
+package tokay
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestTemplateVariants(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tokay")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.html"), []byte("desktop"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "index.mobile.html"), []byte("mobile"), 0600))
+
+	engine := New(&Config{TemplatesDirs: []string{dir}})
+	engine.GET("/", func(c *Context) {
+		c.HTML(200, "index", nil)
+	})
+	iphone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) Mobile/15E148"
+	ipad := "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) Mobile/15E148"
+
+	request := func(ua, device string) *fasthttp.RequestCtx {
+		return serveTestRequest(engine, "/", withHeader("User-Agent", ua), withHeader("X-Device", device))
+	}
+
+	assert.Equal(t, "desktop", string(request(iphone, "").Response.Body()))
+
+	engine.TemplateVariants(TemplateVariantsConfig{Header: "X-Device"})
+	ctx := request(iphone, "")
+	assert.Equal(t, "mobile", string(ctx.Response.Body()))
+	assert.Equal(t, "X-Device, User-Agent", string(ctx.Response.Header.Peek("Vary")))
+	assert.Equal(t, "desktop", string(request(ipad, "").Response.Body()))
+	assert.Equal(t, "desktop", string(request("Mozilla/5.0 (X11; Linux x86_64) Firefox/121.0", "").Response.Body()))
+	assert.Equal(t, "mobile", string(request("", "mobile").Response.Body()))
+	assert.Equal(t, "desktop", string(request(iphone, "desktop").Response.Body()))
+}
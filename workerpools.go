@@ -0,0 +1,111 @@
+package tokay
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// PoolMetaKey is the route metadata key of the worker pool name set by Route.Pool.
+const PoolMetaKey = "pool"
+
+// Pool assigns the route to the named worker pool of the WorkerPools middleware,
+// like "interactive" or "batch".
+func (r *Route) Pool(name string) *Route {
+	return r.SetMeta(PoolMetaKey, name)
+}
+
+// WorkerPoolsConfig is a struct for specifying WorkerPools middleware options.
+type WorkerPoolsConfig struct {
+	// Pools are the maximum numbers of concurrently handled requests by the pool names.
+	Pools map[string]int
+	// Default is the pool of the routes without Route.Pool and the requests without a route.
+	// These requests are not limited if it is empty (the default).
+	Default string
+	// MaxQueue is the maximum number of requests waiting for a pool worker, the requests above
+	// the limit are rejected with 503 Service Unavailable. Default is the pool size.
+	MaxQueue int
+	// Timeout is the maximum waiting time for a pool worker. Requests waiting longer are rejected
+	// with 503 Service Unavailable. Default is 10 seconds.
+	Timeout time.Duration
+}
+
+// workerPool is the concurrency budget of the routes class.
+type workerPool struct {
+	workers  chan struct{}
+	waiting  int32
+	maxQueue int32
+}
+
+// WorkerPools returns a middleware which dedicates separate concurrency budgets to the route classes
+// (see Route.Pool), so the slow batch endpoints cannot take all the server workers (see
+// fasthttp.Server.Concurrency) and starve the interactive ones. The sum of the pool sizes and queues
+// should stay below the server concurrency. The rejected requests get the Retry-After header.
+//
+//	engine.Use(tokay.WorkerPools(tokay.WorkerPoolsConfig{
+//		Pools:   map[string]int{"interactive": 512, "batch": 16},
+//		Default: "interactive",
+//	}))
+//	engine.GET("/exports/<id>", exportHandler).Pool("batch")
+func WorkerPools(config WorkerPoolsConfig) Handler {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	pools := make(map[string]*workerPool, len(config.Pools))
+	for name, size := range config.Pools {
+		if size < 1 {
+			size = 1
+		}
+		maxQueue := config.MaxQueue
+		if maxQueue <= 0 {
+			maxQueue = size
+		}
+		pools[name] = &workerPool{workers: make(chan struct{}, size), maxQueue: int32(maxQueue)}
+	}
+
+	return func(c *Context) {
+		name := config.Default
+		if c.route != nil {
+			if pool, ok := c.route.Meta(PoolMetaKey).(string); ok {
+				name = pool
+			}
+		}
+		pool := pools[name]
+		if pool == nil {
+			return
+		}
+		if !pool.acquire(config.Timeout) {
+			c.AbortWithError(http.StatusServiceUnavailable, nil)
+			c.Response.Header.Set("Retry-After", "1")
+			return
+		}
+		defer pool.release()
+		c.Next()
+	}
+}
+
+// acquire waits for a free worker of the pool, false means the queue is full or the timeout expired.
+func (p *workerPool) acquire(timeout time.Duration) bool {
+	select {
+	case p.workers <- struct{}{}:
+		return true
+	default:
+	}
+	defer atomic.AddInt32(&p.waiting, -1)
+	if atomic.AddInt32(&p.waiting, 1) > p.maxQueue {
+		return false
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case p.workers <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// release frees the worker.
+func (p *workerPool) release() {
+	<-p.workers
+}
@@ -0,0 +1,61 @@
+package tokay
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerPools(t *testing.T) {
+	engine := New()
+	engine.Use(WorkerPools(WorkerPoolsConfig{
+		Pools:    map[string]int{"interactive": 2, "batch": 1},
+		Default:  "interactive",
+		MaxQueue: 1,
+		Timeout:  50 * time.Millisecond,
+	}))
+	release := make(chan struct{})
+	started := make(chan struct{}, 10)
+	engine.GET("/export", func(c *Context) {
+		started <- struct{}{}
+		<-release
+	}).Pool("batch")
+	engine.GET("/page", func(c *Context) {
+		c.String(200, "page")
+	})
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses <- serveTestRequest(engine, "/export").Response.StatusCode()
+		}()
+		if i == 0 {
+			<-started
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// the batch pool is busy, the interactive one is not
+	ctx := serveTestRequest(engine, "/page")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+
+	// one request is rejected as the queue is full, another one times out
+	rejected := <-statuses
+	assert.Equal(t, 503, rejected)
+	rejected = <-statuses
+	assert.Equal(t, 503, rejected)
+	ctx = serveTestRequest(engine, "/export?again")
+	assert.Equal(t, 503, ctx.Response.StatusCode())
+	assert.Equal(t, "1", string(ctx.Response.Header.Peek("Retry-After")))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 200, <-statuses)
+	ctx = serveTestRequest(engine, "/export")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+}
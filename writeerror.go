@@ -0,0 +1,98 @@
+package tokay
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// WriteError is the failure of writing the response to the client, like a broken pipe
+// or a write timeout (see Engine.OnWriteError).
+type WriteError struct {
+	// Err is the connection write error.
+	Err error
+	// Method and Path are the request method and path.
+	Method, Path string
+	// Route is the route of the request, nil if no route matched.
+	Route *Route
+	// Status is the response status code.
+	Status int
+	// BytesWritten is the number of the response bytes written before the error.
+	BytesWritten int64
+}
+
+// Error returns the error description with the request.
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("cannot write %d response to %s %s after %d bytes: %v", e.Status, e.Method, e.Path, e.BytesWritten, e.Err)
+}
+
+// Unwrap returns the connection write error.
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// OnWriteError registers the function called when writing the response to the client fails,
+// so the metrics can tell the client aborted requests from the server errors. The response is written
+// after the handlers return, so the function must not use the request Context. The write errors are
+// detected on the connections accepted by the engine listener (see Run, RunTLS) and counted in Stats.
+//
+//	engine.OnWriteError(func(e *tokay.WriteError) {
+//		clientAborted.WithLabelValues(e.Route.Path()).Inc()
+//	})
+func (engine *Engine) OnWriteError(fn func(e *WriteError)) {
+	engine.onWriteError = append(engine.onWriteError, fn)
+}
+
+// responseWrite is the response being written to the connection.
+type responseWrite struct {
+	sync.Mutex
+	engine   *Engine
+	info     WriteError
+	reported bool
+}
+
+// trackResponseWrite registers the response of the request to report its write errors.
+func (engine *Engine) trackResponseWrite(c *Context) {
+	gc := gracefulConnOf(c.Conn())
+	if gc == nil {
+		return
+	}
+	gc.write.Lock()
+	gc.write.engine = engine
+	if c.Response.StatusCode() == http.StatusSwitchingProtocols {
+		// the hijacked connection writes are not the response ones
+		gc.write.engine = nil
+	}
+	gc.write.info = WriteError{Method: c.Method(), Path: c.Path(), Route: c.route, Status: c.Response.StatusCode()}
+	gc.write.reported = false
+	gc.write.Unlock()
+}
+
+// written counts the written bytes and reports the write error once per response.
+func (w *responseWrite) written(n int, err error) {
+	w.Lock()
+	w.info.BytesWritten += int64(n)
+	if err == nil || w.reported || w.engine == nil {
+		w.Unlock()
+		return
+	}
+	w.reported = true
+	e := w.info
+	e.Err = err
+	engine := w.engine
+	w.Unlock()
+
+	atomic.AddUint64(&engine.stats.writeErrors, 1)
+	if engine.metrics != nil {
+		route := "notfound"
+		if e.Route != nil {
+			route = e.Route.path
+		}
+		engine.metrics.Count("http.write_errors", 1, []string{"route:" + route, "method:" + e.Method, "status:" + strconv.Itoa(e.Status)})
+	}
+	for _, fn := range engine.onWriteError {
+		fn(&e)
+	}
+}
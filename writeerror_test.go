@@ -0,0 +1,52 @@
+package tokay
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineOnWriteError(t *testing.T) {
+	engine := New(&Config{Logger: &testLogger{}})
+	errs := make(chan *WriteError, 1)
+	engine.OnWriteError(func(e *WriteError) {
+		errs <- e
+	})
+	engine.GET("/ok", func(c *Context) {
+		c.String(200, "ok")
+	})
+	engine.GET("/report", func(c *Context) {
+		c.Data(200, "text/plain", bytes.Repeat([]byte("report "), 8<<20))
+	}).Name("report")
+	conn, err := runTestServer(engine)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer engine.Close()
+	defer conn.Close()
+
+	fmt.Fprint(conn, "GET /ok HTTP/1.1\r\nHost: localhost\r\n\r\nGET /report HTTP/1.1\r\nHost: localhost\r\n\r\n")
+	buf := make([]byte, 1024)
+	_, err = conn.Read(buf)
+	assert.Nil(t, err)
+	conn.(*net.TCPConn).SetLinger(0)
+	conn.Close()
+
+	select {
+	case e := <-errs:
+		assert.Equal(t, "GET", e.Method)
+		assert.Equal(t, "/report", e.Path)
+		assert.Equal(t, engine.Route("report"), e.Route)
+		assert.Equal(t, 200, e.Status)
+		assert.True(t, e.BytesWritten < 7*8<<20)
+		assert.NotNil(t, e.Err)
+		assert.Contains(t, e.Error(), "cannot write 200 response to GET /report")
+	case <-time.After(5 * time.Second):
+		t.Fatal("write error is not reported")
+	}
+	assert.Equal(t, uint64(1), engine.Stats().WriteErrors)
+}
@@ -0,0 +1,78 @@
+package tokay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	websocket "github.com/night-codes/tokay-websocket"
+)
+
+// wsRegistry tracks the active WebSocket connections for the graceful shutdown.
+type wsRegistry struct {
+	sync.Mutex
+	conns map[*websocket.Conn]struct{}
+	done  chan struct{} // closed when the last connection is removed during the shutdown
+}
+
+// add registers the connection.
+func (r *wsRegistry) add(conn *websocket.Conn) {
+	r.Lock()
+	if r.conns == nil {
+		r.conns = make(map[*websocket.Conn]struct{})
+	}
+	r.conns[conn] = struct{}{}
+	r.Unlock()
+}
+
+// count returns the number of the active connections.
+func (r *wsRegistry) count() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.conns)
+}
+
+// remove unregisters the closed connection.
+func (r *wsRegistry) remove(conn *websocket.Conn) {
+	r.Lock()
+	delete(r.conns, conn)
+	if len(r.conns) == 0 && r.done != nil {
+		close(r.done)
+		r.done = nil
+	}
+	r.Unlock()
+}
+
+// closeAll sends the close frames to all connections and waits until the handlers finish
+// or the timeout (or ctx) expires. The remaining connections are closed forcibly.
+func (r *wsRegistry) closeAll(ctx context.Context, timeout time.Duration) {
+	r.Lock()
+	if len(r.conns) == 0 {
+		r.Unlock()
+		return
+	}
+	done := make(chan struct{})
+	r.done = done
+	deadline := time.Now().Add(timeout)
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutdown")
+	for conn := range r.conns {
+		conn.WriteControl(websocket.CloseMessage, message, deadline)
+	}
+	r.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	r.Lock()
+	for conn := range r.conns {
+		conn.Close()
+	}
+	r.done = nil
+	r.Unlock()
+}
@@ -0,0 +1,56 @@
+package tokay
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	websocket "github.com/night-codes/tokay-websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdownClosesWebsockets(t *testing.T) {
+	engine := New(&Config{WebsocketShutdownTimeout: 2 * time.Second})
+	finished := make(chan struct{})
+	engine.GET("/ws", func(c *Context) {
+		c.Websocket(func() {
+			defer close(finished)
+			for {
+				if _, _, err := c.WSConn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		})
+	})
+	ln, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer ln.Close()
+	engine.Server.Handler = engine.HandleRequest
+	go engine.Server.Serve(ln)
+
+	conn, _, err := (&websocket.Dialer{}).Dial("ws://"+ln.Addr().String()+"/ws", nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	closeCode := make(chan int, 1)
+	go func() {
+		_, _, err := conn.ReadMessage()
+		if ce, ok := err.(*websocket.CloseError); ok {
+			closeCode <- ce.Code
+		}
+		close(closeCode)
+		conn.Close()
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	engine.Shutdown(context.Background())
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, websocket.CloseGoingAway, <-closeCode)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Error("websocket handler is not finished")
+	}
+}